@@ -4,12 +4,48 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/maciekmm/obligacje/tz"
+	"github.com/maciekmm/obligacje/civil"
+	"github.com/maciekmm/obligacje/decimal"
 )
 
+// Price is an amount in PLN. Calculations are carried out on decimals
+// and their results are always whole grosze.
 type Price float64
+
+// Percentage is a rate as a fraction, e.g. 0.0575 for 5.75%.
 type Percentage float64
 
+// PriceOf returns the price of the decimal amount.
+func PriceOf(d decimal.Decimal) Price {
+	return Price(d.Float64())
+}
+
+// Decimal returns the price as a fixed-point decimal.
+func (p Price) Decimal() decimal.Decimal {
+	return decimal.FromFloat(float64(p))
+}
+
+// Times returns the price of quantity units, e.g. the value of quantity bonds,
+// or decimal.ErrOverflow if the amount is too large to be represented.
+func (p Price) Times(quantity int) (Price, error) {
+	total, err := p.Decimal().MulInt(int64(quantity))
+	if err != nil {
+		return 0, err
+	}
+	return PriceOf(total), nil
+}
+
+// Decimal returns the rate as a fixed-point decimal.
+func (p Percentage) Decimal() decimal.Decimal {
+	return decimal.FromFloat(float64(p))
+}
+
+// Round rounds the rate to the precision of rates published by the issuer (0.0001%),
+// so that e.g. 4.9% + 1.25% equals the published 6.15%.
+func (p Percentage) Round() Percentage {
+	return Percentage(p.Decimal().Round(6).Float64())
+}
+
 type CouponPaymentsFrequency int
 
 const (
@@ -36,31 +72,44 @@ type Bond struct {
 	InterestPeriods         []Percentage
 	CouponPaymentsFrequency CouponPaymentsFrequency
 
-	SaleStart time.Time
-	SaleEnd   time.Time
+	// MaturityInterest is the interest paid per bond at maturity as published by the issuer.
+	// For bonds with a day-count based interest it assumes purchase on the first day of sale.
+	MaturityInterest Price
+	// PeriodInterest is the interest per bond in each interest period as published by the issuer
+	// for series paying coupons. Zero means the amount isn't published.
+	PeriodInterest []Price
+
+	// EarlyRedeemable reports whether the bond can be redeemed before maturity.
+	EarlyRedeemable bool
+	// EarlyRedemptionFee is charged per bond redeemed before maturity.
+	// It never exceeds the interest accrued on the bond.
+	EarlyRedemptionFee Price
+
+	SaleStart civil.Date
+	SaleEnd   civil.Date
 }
 
-func (b Bond) Period(i int, purchaseDay int) (time.Time, time.Time, error) {
+func (b Bond) Period(i int, purchaseDay int) (civil.Date, civil.Date, error) {
 	if b.CouponPaymentsFrequency == CouponPaymentsFrequencyUnknown {
-		return time.Time{}, time.Time{}, fmt.Errorf("unknown coupon payments frequency")
+		return civil.Date{}, civil.Date{}, fmt.Errorf("unknown coupon payments frequency")
 	}
 
 	if i < 0 || i >= b.InterestPeriodCount() {
-		return time.Time{}, time.Time{}, fmt.Errorf("invalid period index: %d", i)
+		return civil.Date{}, civil.Date{}, fmt.Errorf("invalid period index: %d", i)
 	}
 
 	if purchaseDay < 1 {
-		return time.Time{}, time.Time{}, fmt.Errorf("invalid purchase day: %d", purchaseDay)
+		return civil.Date{}, civil.Date{}, fmt.Errorf("invalid purchase day: %d", purchaseDay)
 	}
 
 	lastDayOfPurchaseMonth := lastDayOfMonth(b.SaleStart.Year(), b.SaleStart.Month())
 	if purchaseDay > lastDayOfPurchaseMonth {
-		return time.Time{}, time.Time{}, fmt.Errorf("invalid purchase day: %d", purchaseDay)
+		return civil.Date{}, civil.Date{}, fmt.Errorf("invalid purchase day: %d", purchaseDay)
 	}
 
-	purchasedAt := time.Date(b.SaleStart.Year(), b.SaleStart.Month(), purchaseDay, 0, 0, 0, 0, tz.UnifiedTimezone)
+	purchasedAt := civil.New(b.SaleStart.Year(), b.SaleStart.Month(), purchaseDay)
 
-	startAt := time.Date(purchasedAt.Year(), purchasedAt.Month()+time.Month(i*b.CouponPaymentsFrequency.Months()), 1, 0, 0, 0, 0, tz.UnifiedTimezone)
+	startAt := civil.New(purchasedAt.Year(), purchasedAt.Month()+time.Month(i*b.CouponPaymentsFrequency.Months()), 1)
 	lastDayOfPeriodStartDay := lastDayOfMonth(startAt.Year(), startAt.Month())
 	if purchaseDay > lastDayOfPeriodStartDay {
 		startAt = startAt.AddDate(0, 0, lastDayOfPeriodStartDay-1)
@@ -68,7 +117,7 @@ func (b Bond) Period(i int, purchaseDay int) (time.Time, time.Time, error) {
 		startAt = startAt.AddDate(0, 0, purchaseDay-1)
 	}
 
-	endAt := time.Date(purchasedAt.Year(), purchasedAt.Month()+time.Month((i+1)*b.CouponPaymentsFrequency.Months()), 1, 0, 0, 0, 0, tz.UnifiedTimezone)
+	endAt := civil.New(purchasedAt.Year(), purchasedAt.Month()+time.Month((i+1)*b.CouponPaymentsFrequency.Months()), 1)
 	lastDayOfPeriodEndDay := lastDayOfMonth(endAt.Year(), endAt.Month())
 	if purchaseDay > lastDayOfPeriodEndDay {
 		endAt = endAt.AddDate(0, 0, lastDayOfPeriodEndDay-1)
@@ -79,10 +128,23 @@ func (b Bond) Period(i int, purchaseDay int) (time.Time, time.Time, error) {
 	return startAt, endAt, nil
 }
 
+// Series returns the three letter series prefix of the bond name, e.g. EDO for EDO0834.
+func (b Bond) Series() string {
+	return SeriesOf(b.Name)
+}
+
+// SeriesOf returns the three letter series prefix of a bond name or an empty string if the name is too short.
+func SeriesOf(name string) string {
+	if len(name) < 3 {
+		return ""
+	}
+	return name[:3]
+}
+
 func (b Bond) InterestPeriodCount() int {
 	return b.MonthsToMaturity / b.CouponPaymentsFrequency.Months()
 }
 
 func lastDayOfMonth(year int, month time.Month) int {
-	return time.Date(year, month, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 1, -1).Day()
+	return civil.New(year, month+1, 0).Day()
 }
@@ -4,15 +4,15 @@ import (
 	"testing"
 	"time"
 
-	"github.com/maciekmm/obligacje/tz"
+	"github.com/maciekmm/obligacje/civil"
 )
 
 func TestBond_Period(t *testing.T) {
 	tests := []struct {
 		name string
 
-		saleStart      time.Time
-		saleEnd        time.Time
+		saleStart      civil.Date
+		saleEnd        civil.Date
 		frequency      CouponPaymentsFrequency
 		maturityMonths int
 
@@ -20,91 +20,91 @@ func TestBond_Period(t *testing.T) {
 		i            int
 		purchasedDay int
 
-		periodStart time.Time
-		periodEnd   time.Time
+		periodStart civil.Date
+		periodEnd   civil.Date
 		wantErr     bool
 	}{
 		{
 			name:           "first period, bought on the first day",
-			saleStart:      time.Date(2024, time.August, 1, 0, 0, 0, 0, tz.UnifiedTimezone),
-			saleEnd:        time.Date(2024, time.August, 31, 0, 0, 0, 0, tz.UnifiedTimezone),
+			saleStart:      civil.New(2024, time.August, 1),
+			saleEnd:        civil.New(2024, time.August, 31),
 			frequency:      CouponPaymentsFrequencyMonthly,
 			maturityMonths: 12,
 			i:              0,
 			purchasedDay:   1,
-			periodStart:    time.Date(2024, time.August, 1, 0, 0, 0, 0, tz.UnifiedTimezone),
-			periodEnd:      time.Date(2024, time.September, 1, 0, 0, 0, 0, tz.UnifiedTimezone),
+			periodStart:    civil.New(2024, time.August, 1),
+			periodEnd:      civil.New(2024, time.September, 1),
 		},
 		{
 			name:           "first period, bought in the middle of the month",
-			saleStart:      time.Date(2024, time.August, 1, 0, 0, 0, 0, tz.UnifiedTimezone),
-			saleEnd:        time.Date(2024, time.August, 31, 0, 0, 0, 0, tz.UnifiedTimezone),
+			saleStart:      civil.New(2024, time.August, 1),
+			saleEnd:        civil.New(2024, time.August, 31),
 			frequency:      CouponPaymentsFrequencyMonthly,
 			maturityMonths: 12,
 			i:              0,
 			purchasedDay:   15,
-			periodStart:    time.Date(2024, time.August, 15, 0, 0, 0, 0, tz.UnifiedTimezone),
-			periodEnd:      time.Date(2024, time.September, 15, 0, 0, 0, 0, tz.UnifiedTimezone),
+			periodStart:    civil.New(2024, time.August, 15),
+			periodEnd:      civil.New(2024, time.September, 15),
 		},
 		{
 			name:           "first period, bought on the last day, clamps to the last day of the next month",
-			saleStart:      time.Date(2024, time.August, 1, 0, 0, 0, 0, tz.UnifiedTimezone),
-			saleEnd:        time.Date(2024, time.August, 31, 0, 0, 0, 0, tz.UnifiedTimezone),
+			saleStart:      civil.New(2024, time.August, 1),
+			saleEnd:        civil.New(2024, time.August, 31),
 			frequency:      CouponPaymentsFrequencyMonthly,
 			maturityMonths: 12,
 			i:              0,
 			purchasedDay:   31,
-			periodStart:    time.Date(2024, time.August, 31, 0, 0, 0, 0, tz.UnifiedTimezone),
-			periodEnd:      time.Date(2024, time.September, 30, 0, 0, 0, 0, tz.UnifiedTimezone),
+			periodStart:    civil.New(2024, time.August, 31),
+			periodEnd:      civil.New(2024, time.September, 30),
 		},
 		{
 			name:           "second period, bought on the last day, clamps to the last day of the next month",
-			saleStart:      time.Date(2024, time.August, 1, 0, 0, 0, 0, tz.UnifiedTimezone),
-			saleEnd:        time.Date(2024, time.August, 31, 0, 0, 0, 0, tz.UnifiedTimezone),
+			saleStart:      civil.New(2024, time.August, 1),
+			saleEnd:        civil.New(2024, time.August, 31),
 			frequency:      CouponPaymentsFrequencyMonthly,
 			maturityMonths: 12,
 			i:              1,
 			purchasedDay:   31,
-			periodStart:    time.Date(2024, time.September, 30, 0, 0, 0, 0, tz.UnifiedTimezone),
-			periodEnd:      time.Date(2024, time.October, 31, 0, 0, 0, 0, tz.UnifiedTimezone),
+			periodStart:    civil.New(2024, time.September, 30),
+			periodEnd:      civil.New(2024, time.October, 31),
 		},
 		{
 			name:           "second period, bought on the last day, clamps to the last day of the Feb if it's a leap year",
-			saleStart:      time.Date(2024, time.January, 1, 0, 0, 0, 0, tz.UnifiedTimezone),
-			saleEnd:        time.Date(2024, time.January, 31, 0, 0, 0, 0, tz.UnifiedTimezone),
+			saleStart:      civil.New(2024, time.January, 1),
+			saleEnd:        civil.New(2024, time.January, 31),
 			frequency:      CouponPaymentsFrequencyMonthly,
 			maturityMonths: 12,
 			i:              0,
 			purchasedDay:   31,
-			periodStart:    time.Date(2024, time.January, 31, 0, 0, 0, 0, tz.UnifiedTimezone),
-			periodEnd:      time.Date(2024, time.February, 29, 0, 0, 0, 0, tz.UnifiedTimezone),
+			periodStart:    civil.New(2024, time.January, 31),
+			periodEnd:      civil.New(2024, time.February, 29),
 		},
 		{
 			name:           "second period, bought on the last day, clamps to the last day of the Feb if it's not a leap year",
-			saleStart:      time.Date(2025, time.January, 1, 0, 0, 0, 0, tz.UnifiedTimezone),
-			saleEnd:        time.Date(2025, time.January, 31, 0, 0, 0, 0, tz.UnifiedTimezone),
+			saleStart:      civil.New(2025, time.January, 1),
+			saleEnd:        civil.New(2025, time.January, 31),
 			frequency:      CouponPaymentsFrequencyMonthly,
 			maturityMonths: 12,
 			i:              0,
 			purchasedDay:   31,
-			periodStart:    time.Date(2025, time.January, 31, 0, 0, 0, 0, tz.UnifiedTimezone),
-			periodEnd:      time.Date(2025, time.February, 28, 0, 0, 0, 0, tz.UnifiedTimezone),
+			periodStart:    civil.New(2025, time.January, 31),
+			periodEnd:      civil.New(2025, time.February, 28),
 		},
 		{
 			name:           "second period, bought on the last day, clamps to the last day of the Feb if it's not a leap year",
-			saleStart:      time.Date(2025, time.January, 1, 0, 0, 0, 0, tz.UnifiedTimezone),
-			saleEnd:        time.Date(2025, time.January, 31, 0, 0, 0, 0, tz.UnifiedTimezone),
+			saleStart:      civil.New(2025, time.January, 1),
+			saleEnd:        civil.New(2025, time.January, 31),
 			frequency:      CouponPaymentsFrequencyMonthly,
 			maturityMonths: 12,
 			i:              0,
 			purchasedDay:   31,
-			periodStart:    time.Date(2025, time.January, 31, 0, 0, 0, 0, tz.UnifiedTimezone),
-			periodEnd:      time.Date(2025, time.February, 28, 0, 0, 0, 0, tz.UnifiedTimezone),
+			periodStart:    civil.New(2025, time.January, 31),
+			periodEnd:      civil.New(2025, time.February, 28),
 		},
 		{
 			name:           "invalid period index",
-			saleStart:      time.Date(2025, time.January, 1, 0, 0, 0, 0, tz.UnifiedTimezone),
-			saleEnd:        time.Date(2025, time.January, 31, 0, 0, 0, 0, tz.UnifiedTimezone),
+			saleStart:      civil.New(2025, time.January, 1),
+			saleEnd:        civil.New(2025, time.January, 31),
 			frequency:      CouponPaymentsFrequencyMonthly,
 			maturityMonths: 12,
 			i:              12,
@@ -113,30 +113,30 @@ func TestBond_Period(t *testing.T) {
 		},
 		{
 			name:           "yearly bond, purchased on the last day of the Feb when leap year",
-			saleStart:      time.Date(2024, time.February, 1, 0, 0, 0, 0, tz.UnifiedTimezone),
-			saleEnd:        time.Date(2024, time.February, 29, 0, 0, 0, 0, tz.UnifiedTimezone),
+			saleStart:      civil.New(2024, time.February, 1),
+			saleEnd:        civil.New(2024, time.February, 29),
 			frequency:      CouponPaymentsFrequencyYearly,
 			maturityMonths: 120,
 			i:              0,
 			purchasedDay:   29,
-			periodStart:    time.Date(2024, time.February, 29, 0, 0, 0, 0, tz.UnifiedTimezone),
-			periodEnd:      time.Date(2025, time.February, 28, 0, 0, 0, 0, tz.UnifiedTimezone),
+			periodStart:    civil.New(2024, time.February, 29),
+			periodEnd:      civil.New(2025, time.February, 28),
 		},
 		{
 			name:           "yearly bond, second period",
-			saleStart:      time.Date(2024, time.January, 1, 0, 0, 0, 0, tz.UnifiedTimezone),
-			saleEnd:        time.Date(2024, time.January, 31, 0, 0, 0, 0, tz.UnifiedTimezone),
+			saleStart:      civil.New(2024, time.January, 1),
+			saleEnd:        civil.New(2024, time.January, 31),
 			frequency:      CouponPaymentsFrequencyYearly,
 			maturityMonths: 120,
 			i:              1,
 			purchasedDay:   31,
-			periodStart:    time.Date(2025, time.January, 31, 0, 0, 0, 0, tz.UnifiedTimezone),
-			periodEnd:      time.Date(2026, time.January, 31, 0, 0, 0, 0, tz.UnifiedTimezone),
+			periodStart:    civil.New(2025, time.January, 31),
+			periodEnd:      civil.New(2026, time.January, 31),
 		},
 		{
 			name:           "invalid purchase Day - 30th of Feb",
-			saleStart:      time.Date(2024, time.February, 1, 0, 0, 0, 0, tz.UnifiedTimezone),
-			saleEnd:        time.Date(2024, time.February, 29, 0, 0, 0, 0, tz.UnifiedTimezone),
+			saleStart:      civil.New(2024, time.February, 1),
+			saleEnd:        civil.New(2024, time.February, 29),
 			frequency:      CouponPaymentsFrequencyYearly,
 			maturityMonths: 120,
 			i:              1,
@@ -145,8 +145,8 @@ func TestBond_Period(t *testing.T) {
 		},
 		{
 			name:           "invalid purchase Day - 0",
-			saleStart:      time.Date(2024, time.February, 1, 0, 0, 0, 0, tz.UnifiedTimezone),
-			saleEnd:        time.Date(2024, time.February, 29, 0, 0, 0, 0, tz.UnifiedTimezone),
+			saleStart:      civil.New(2024, time.February, 1),
+			saleEnd:        civil.New(2024, time.February, 29),
 			frequency:      CouponPaymentsFrequencyYearly,
 			maturityMonths: 120,
 			i:              1,
@@ -170,10 +170,10 @@ func TestBond_Period(t *testing.T) {
 				t.Errorf("Period() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if !start.Equal(tt.periodStart) {
+			if start != tt.periodStart {
 				t.Errorf("Period Start() = %v, want %v", start, tt.periodStart)
 			}
-			if !end.Equal(tt.periodEnd) {
+			if end != tt.periodEnd {
 				t.Errorf("Period End() = %v, want %v", end, tt.periodEnd)
 			}
 		})
@@ -221,3 +221,19 @@ func TestBond_InterestPeriodCount(t *testing.T) {
 		})
 	}
 }
+
+func TestPercentage_Round(t *testing.T) {
+	tests := []struct {
+		rate Percentage
+		want Percentage
+	}{
+		{rate: 0.049 + 0.0125, want: 0.0615},
+		{rate: 0.0123456789, want: 0.012346},
+		{rate: -0.0000004, want: 0},
+	}
+	for _, tt := range tests {
+		if got := tt.rate.Round(); got != tt.want {
+			t.Errorf("Percentage(%v).Round() = %v, want %v", tt.rate, got, tt.want)
+		}
+	}
+}
@@ -2,12 +2,27 @@ package bond
 
 import (
 	"errors"
+
+	"github.com/maciekmm/obligacje/civil"
 )
 
 var (
 	ErrNameNotFound = errors.New("name not found")
+	ErrNotOnSale    = errors.New("no bond of the series on sale")
 )
 
 type Repository interface {
 	Lookup(name string) (Bond, error)
+	// List returns all bonds sorted by name.
+	List() []Bond
+}
+
+// OnSale returns the bond of the series sold at the given date, e.g. the EDO issue offered this month.
+func OnSale(repo Repository, series string, at civil.Date) (Bond, error) {
+	for _, bnd := range repo.List() {
+		if bnd.Series() == series && !at.Before(bnd.SaleStart) && at.Before(bnd.SaleEnd.AddDate(0, 0, 1)) {
+			return bnd, nil
+		}
+	}
+	return Bond{}, ErrNotOnSale
 }
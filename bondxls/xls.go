@@ -3,55 +3,22 @@ package bondxls
 import (
 	"fmt"
 	"log/slog"
+	"maps"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/maciekmm/obligacje/bond"
-	"github.com/maciekmm/obligacje/tz"
+	"github.com/maciekmm/obligacje/calculator"
+	"github.com/maciekmm/obligacje/civil"
 	"github.com/xuri/excelize/v2"
 )
 
-var (
-	supportedNames = []string{
-		"TOS", "DOS",
-		"ROR", "DOR",
-		"COI", "EDO", "ROS", "ROD",
-	}
-)
-
 const (
 	dateFormat = "_2/01/2006"
 )
 
-func namePrefix(name string) string {
-	if len(name) < 3 {
-		return ""
-	}
-	return name[:3]
-}
-
-func interestRecalculation(name string) (bond.CouponPaymentsFrequency, error) {
-	prefix := namePrefix(name)
-	if prefix == "" {
-		return bond.CouponPaymentsFrequencyUnknown, fmt.Errorf("invalid name prefix: %s", name)
-	}
-	switch prefix {
-	case "TOS", "DOS":
-		return bond.CouponPaymentsFrequencyYearly, nil
-	case "ROR", "DOR":
-		return bond.CouponPaymentsFrequencyMonthly, nil
-	case "COI", "EDO", "ROS", "ROD":
-		return bond.CouponPaymentsFrequencyYearly, nil
-	// TODO: setting quarterly payment frequency for OTS doesn't work
-	// as interest calculation for it is based on number of days (or fixed value) and not frequency of payments
-	// case "OTS":
-	// 	return bond.CouponPaymentsFrequencyQuarterly, nil
-	default:
-		return bond.CouponPaymentsFrequencyUnknown, fmt.Errorf("invalid name prefix: %s", prefix)
-	}
-}
-
 type XLSXRepository struct {
 	logger *slog.Logger
 	bonds  map[string]bond.Bond
@@ -65,6 +32,14 @@ func (r *XLSXRepository) Lookup(name string) (bond.Bond, error) {
 	return bnd, nil
 }
 
+func (r *XLSXRepository) List() []bond.Bond {
+	bonds := slices.Collect(maps.Values(r.bonds))
+	slices.SortFunc(bonds, func(a, b bond.Bond) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return bonds
+}
+
 func LoadFromXLSX(logger *slog.Logger, file string) (*XLSXRepository, error) {
 	repo := &XLSXRepository{
 		logger: logger,
@@ -77,14 +52,14 @@ func LoadFromXLSX(logger *slog.Logger, file string) (*XLSXRepository, error) {
 	}
 	defer xls.Close()
 
-	for _, namePrefix := range supportedNames {
-		if bonds, err := parseSheet(logger, xls, namePrefix); err != nil {
-			return nil, fmt.Errorf("error loading sheet %s: %w", namePrefix, err)
+	for _, series := range calculator.Series() {
+		if bonds, err := parseSheet(logger, xls, series); err != nil {
+			return nil, fmt.Errorf("error loading sheet %s: %w", series, err)
 		} else {
 			for name, bond := range bonds {
 				repo.bonds[name] = bond
 			}
-			logger.Info("loaded bonds", "bonds_no", len(bonds), "name", namePrefix)
+			logger.Info("loaded bonds", "bonds_no", len(bonds), "name", series)
 		}
 	}
 	return repo, nil
@@ -191,6 +166,35 @@ func rowToBond(headers, row []string) (bond.Bond, error) {
 			} else {
 				return bond, fmt.Errorf("error parsing interest percentage: %w", err)
 			}
+		case strings.HasPrefix(header, "Odsetki (zł) w "):
+			// interest per period, e.g. "Odsetki (zł) w 3. okresie"
+			cell = strings.TrimSpace(cell)
+			if cell == "" {
+				continue
+			}
+			period, err := periodOfHeader(strings.TrimPrefix(header, "Odsetki (zł) w "))
+			if err != nil {
+				return bond, fmt.Errorf("error parsing interest period: %w", err)
+			}
+			price, err := parsePrice(cell)
+			if err != nil {
+				return bond, fmt.Errorf("error parsing period interest: %w", err)
+			}
+			for len(bond.PeriodInterest) < period {
+				bond.PeriodInterest = append(bond.PeriodInterest, 0)
+			}
+			bond.PeriodInterest[period-1] = price
+		case header == "Odsetki (zł)":
+			// some sheets pad the cell with whitespace or leave it blank
+			cell = strings.TrimSpace(cell)
+			if cell == "" {
+				continue
+			}
+			if price, err := parsePrice(cell); err == nil {
+				bond.MaturityInterest = price
+			} else {
+				return bond, fmt.Errorf("error parsing maturity interest: %w", err)
+			}
 		case strings.HasPrefix(header, "Marża"):
 			if percentage, err := parsePercentage(cell); err == nil {
 				bond.Margin = percentage
@@ -198,21 +202,15 @@ func rowToBond(headers, row []string) (bond.Bond, error) {
 				return bond, fmt.Errorf("error parsing margin percentage: %w", err)
 			}
 		case header == "Początek sprzedaży":
-			if saleStart, err := time.ParseInLocation(dateFormat, cell, tz.UnifiedTimezone); err == nil {
+			if saleStart, err := civil.ParseFormat(dateFormat, cell); err == nil {
 				bond.SaleStart = saleStart
 			}
 		case header == "Koniec sprzedaży":
-			if saleEnd, err := time.ParseInLocation(dateFormat, cell, tz.UnifiedTimezone); err == nil {
+			if saleEnd, err := civil.ParseFormat(dateFormat, cell); err == nil {
 				bond.SaleEnd = saleEnd
 			}
 		}
 	}
-	recalc, err := interestRecalculation(bond.Name)
-	if err != nil {
-		return bond, fmt.Errorf("error parsing interest recalculation: %w", err)
-	}
-	bond.CouponPaymentsFrequency = recalc
-
 	// sometimes sale start and sale end are not provided
 	if bond.SaleStart.IsZero() {
 		bond.SaleStart = nameToSaleStart(bond.Name, bond.MonthsToMaturity)
@@ -225,35 +223,47 @@ func rowToBond(headers, row []string) (bond.Bond, error) {
 		bond.SaleEnd = bond.SaleStart.AddDate(0, 1, -1)
 	}
 
-	// If it's fixed interest bond, fill interest periods for each year
-	namePrefix := namePrefix(bond.Name)
-	if namePrefix == "TOS" || namePrefix == "DOS" {
-		for i := 1; i < bond.MonthsToMaturity/12; i++ {
-			bond.InterestPeriods = append(bond.InterestPeriods, bond.InterestPeriods[0])
-		}
+	// terms of some series changed over time, so the strategy depends on the sale start
+	strategy, err := calculator.StrategyFor(bond)
+	if err != nil {
+		return bond, fmt.Errorf("error finding calculation strategy: %w", err)
 	}
+	bond.CouponPaymentsFrequency = strategy.Frequency()
 
-	return bond, nil
+	return strategy.Normalize(bond), nil
 }
 
-func nameToSaleStart(name string, monthsToMaturity int) time.Time {
+func nameToSaleStart(name string, monthsToMaturity int) civil.Date {
 	if len(name) < 4 {
-		return time.Time{}
+		return civil.Date{}
 	}
 	suffix := name[len(name)-4:]
 	month, err := strconv.Atoi(suffix[:2])
 	if err != nil {
-		return time.Time{}
+		return civil.Date{}
 	}
 	year, err := strconv.Atoi(suffix[2:])
 	if err != nil {
-		return time.Time{}
+		return civil.Date{}
 	}
 
-	maturity := time.Date(2000+year, time.Month(month), 1, 0, 0, 0, 0, tz.UnifiedTimezone)
+	maturity := civil.New(2000+year, time.Month(month), 1)
 	return maturity.AddDate(0, -monthsToMaturity, 0)
 }
 
+// periodOfHeader returns the one-based period number of a column header suffix such as "3. okresie" or "3. roku".
+func periodOfHeader(suffix string) (int, error) {
+	number, _, ok := strings.Cut(suffix, ".")
+	if !ok {
+		return 0, fmt.Errorf("invalid period header: %s", suffix)
+	}
+	period, err := strconv.Atoi(strings.TrimSpace(number))
+	if err != nil || period < 1 {
+		return 0, fmt.Errorf("invalid period header: %s", suffix)
+	}
+	return period, nil
+}
+
 func parsePrice(cell string) (bond.Price, error) {
 	if cell == "-" {
 		return 0, nil
@@ -1,6 +1,7 @@
 package bondxls
 
 import (
+	"errors"
 	"log/slog"
 	"math"
 	"os"
@@ -9,8 +10,8 @@ import (
 	"time"
 
 	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/civil"
 	"github.com/maciekmm/obligacje/internal/testutil"
-	"github.com/maciekmm/obligacje/tz"
 )
 
 func TestXLSRepository_Lookup(t *testing.T) {
@@ -24,22 +25,40 @@ func TestXLSRepository_Lookup(t *testing.T) {
 			want:    bond.Bond{},
 			wantErr: true,
 		},
-		// {
-		// 	name: "OTS0118",
-		// 	want: bond.Bond{
-		// 		Name:                  "OTS0118",
-		// 		ISIN:                    "PL0000110292",
-		// 		FaceValue:               100.00,
-		// 		ExchangePrice:           0.00,
-		// 		Margin:                  0.00,
-		// 		MonthsToMaturity:        3,
-		// 		CouponPaymentsFrequency: bond.CouponPaymentsFrequencyQuarterly,
-		// 		InterestPeriods:         []bond.Percentage{0.0150},
-
-		// 		SaleStart: testutil.Must(time.ParseInLocation(time.DateOnly, "2017-10-01", tz.WarsawTimezone)),
-		// 		SaleEnd:   testutil.Must(time.ParseInLocation(time.DateOnly, "2017-10-31", tz.WarsawTimezone)),
-		// 	},
-		// },
+		{
+			name: "OTS0118",
+			want: bond.Bond{
+				Name:                    "OTS0118",
+				ISIN:                    "PL0000110292",
+				FaceValue:               100.00,
+				ExchangePrice:           0.00,
+				Margin:                  0.00,
+				MonthsToMaturity:        3,
+				CouponPaymentsFrequency: bond.CouponPaymentsFrequencyQuarterly,
+				InterestPeriods:         []bond.Percentage{0.0150},
+				MaturityInterest:        0.38,
+
+				SaleStart: testutil.Must(civil.Parse("2017-10-01")),
+				SaleEnd:   testutil.Must(civil.Parse("2017-10-31")),
+			},
+		},
+		{
+			name: "OTS0126",
+			want: bond.Bond{
+				Name:                    "OTS0126",
+				ISIN:                    "PL0000118436",
+				FaceValue:               100.00,
+				ExchangePrice:           100.00,
+				Margin:                  0.00,
+				MonthsToMaturity:        3,
+				CouponPaymentsFrequency: bond.CouponPaymentsFrequencyQuarterly,
+				InterestPeriods:         []bond.Percentage{0.0275},
+				MaturityInterest:        0.69,
+
+				SaleStart: testutil.Must(civil.Parse("2025-10-01")),
+				SaleEnd:   testutil.Must(civil.Parse("2025-10-31")),
+			},
+		},
 		{
 			name: "ROR0623",
 			want: bond.Bond{
@@ -51,9 +70,12 @@ func TestXLSRepository_Lookup(t *testing.T) {
 				MonthsToMaturity:        12,
 				CouponPaymentsFrequency: bond.CouponPaymentsFrequencyMonthly,
 				InterestPeriods:         []bond.Percentage{0.0525, 0.0600, 0.0650, 0.0650, 0.0675, 0.0675, 0.0675, 0.0675, 0.0675, 0.0675, 0.0675, 0.0675},
+				PeriodInterest:          []bond.Price{0.44, 0.50, 0.54, 0.54, 0.56, 0.56, 0.56, 0.56, 0.56, 0.56, 0.56, 0.56},
+				EarlyRedeemable:         true,
+				EarlyRedemptionFee:      0.50,
 
-				SaleStart: testutil.Must(time.ParseInLocation(time.DateOnly, "2022-06-01", tz.UnifiedTimezone)),
-				SaleEnd:   testutil.Must(time.ParseInLocation(time.DateOnly, "2022-06-30", tz.UnifiedTimezone)),
+				SaleStart: testutil.Must(civil.Parse("2022-06-01")),
+				SaleEnd:   testutil.Must(civil.Parse("2022-06-30")),
 			},
 		},
 		{
@@ -67,9 +89,12 @@ func TestXLSRepository_Lookup(t *testing.T) {
 				MonthsToMaturity:        12,
 				CouponPaymentsFrequency: bond.CouponPaymentsFrequencyMonthly,
 				InterestPeriods:         []bond.Percentage{0.0425},
+				PeriodInterest:          []bond.Price{0.35},
+				EarlyRedeemable:         true,
+				EarlyRedemptionFee:      0.50,
 
-				SaleStart: testutil.Must(time.ParseInLocation(time.DateOnly, "2025-12-01", tz.UnifiedTimezone)),
-				SaleEnd:   testutil.Must(time.ParseInLocation(time.DateOnly, "2025-12-31", tz.UnifiedTimezone)),
+				SaleStart: testutil.Must(civil.Parse("2025-12-01")),
+				SaleEnd:   testutil.Must(civil.Parse("2025-12-31")),
 			},
 		},
 		{
@@ -83,9 +108,12 @@ func TestXLSRepository_Lookup(t *testing.T) {
 				MonthsToMaturity:        36,
 				CouponPaymentsFrequency: bond.CouponPaymentsFrequencyYearly,
 				InterestPeriods:         []bond.Percentage{0.0650, 0.0650, 0.0650},
+				MaturityInterest:        20.79,
+				EarlyRedeemable:         true,
+				EarlyRedemptionFee:      0.70,
 
-				SaleStart: testutil.Must(time.ParseInLocation(time.DateOnly, "2022-08-01", tz.UnifiedTimezone)),
-				SaleEnd:   testutil.Must(time.ParseInLocation(time.DateOnly, "2022-08-31", tz.UnifiedTimezone)),
+				SaleStart: testutil.Must(civil.Parse("2022-08-01")),
+				SaleEnd:   testutil.Must(civil.Parse("2022-08-31")),
 			},
 		},
 		{
@@ -99,9 +127,12 @@ func TestXLSRepository_Lookup(t *testing.T) {
 				MonthsToMaturity:        36,
 				CouponPaymentsFrequency: bond.CouponPaymentsFrequencyYearly,
 				Margin:                  0.00,
+				MaturityInterest:        17.93,
+				EarlyRedeemable:         true,
+				EarlyRedemptionFee:      0.70,
 
-				SaleStart: testutil.Must(time.ParseInLocation(time.DateOnly, "2025-07-01", tz.UnifiedTimezone)),
-				SaleEnd:   testutil.Must(time.ParseInLocation(time.DateOnly, "2025-07-31", tz.UnifiedTimezone)),
+				SaleStart: testutil.Must(civil.Parse("2025-07-01")),
+				SaleEnd:   testutil.Must(civil.Parse("2025-07-31")),
 			},
 		},
 		{
@@ -115,9 +146,11 @@ func TestXLSRepository_Lookup(t *testing.T) {
 				MonthsToMaturity:        120,
 				CouponPaymentsFrequency: bond.CouponPaymentsFrequencyYearly,
 				InterestPeriods:         []bond.Percentage{0.0170, 0.1200, 0.1710, 0.0300, 0.0590},
+				EarlyRedeemable:         true,
+				EarlyRedemptionFee:      2.00,
 
-				SaleStart: testutil.Must(time.ParseInLocation(time.DateOnly, "2021-05-01", tz.UnifiedTimezone)),
-				SaleEnd:   testutil.Must(time.ParseInLocation(time.DateOnly, "2021-05-31", tz.UnifiedTimezone)),
+				SaleStart: testutil.Must(civil.Parse("2021-05-01")),
+				SaleEnd:   testutil.Must(civil.Parse("2021-05-31")),
 			},
 		},
 		{
@@ -131,9 +164,30 @@ func TestXLSRepository_Lookup(t *testing.T) {
 				MonthsToMaturity:        120,
 				CouponPaymentsFrequency: bond.CouponPaymentsFrequencyYearly,
 				InterestPeriods:         []bond.Percentage{0.056},
+				EarlyRedeemable:         true,
+				EarlyRedemptionFee:      2.00,
 
-				SaleStart: testutil.Must(time.ParseInLocation(time.DateOnly, "2025-12-01", tz.UnifiedTimezone)),
-				SaleEnd:   testutil.Must(time.ParseInLocation(time.DateOnly, "2025-12-31", tz.UnifiedTimezone)),
+				SaleStart: testutil.Must(civil.Parse("2025-12-01")),
+				SaleEnd:   testutil.Must(civil.Parse("2025-12-31")),
+			},
+		},
+		{
+			name: "COI0528",
+			want: bond.Bond{
+				Name:                    "COI0528",
+				ISIN:                    "PL0000116901",
+				FaceValue:               100.00,
+				ExchangePrice:           99.90,
+				Margin:                  0.0125,
+				MonthsToMaturity:        48,
+				CouponPaymentsFrequency: bond.CouponPaymentsFrequencyYearly,
+				InterestPeriods:         []bond.Percentage{0.0655, 0.0615},
+				PeriodInterest:          []bond.Price{6.55, 6.15},
+				EarlyRedeemable:         true,
+				EarlyRedemptionFee:      0.70,
+
+				SaleStart: testutil.Must(civil.Parse("2024-05-01")),
+				SaleEnd:   testutil.Must(civil.Parse("2024-05-31")),
 			},
 		},
 		{
@@ -146,9 +200,12 @@ func TestXLSRepository_Lookup(t *testing.T) {
 				MonthsToMaturity:        12,
 				CouponPaymentsFrequency: bond.CouponPaymentsFrequencyMonthly,
 				InterestPeriods:         []bond.Percentage{0.0575, 0.0575, 0.0575, 0.0575, 0.0575, 0.0525, 0.0525, 0.0500, 0.0500, 0.0475, 0.0450, 0.0425},
+				PeriodInterest:          []bond.Price{0.48, 0.48, 0.48, 0.48, 0.48, 0.44, 0.44, 0.42, 0.42, 0.40, 0.38, 0.35},
+				EarlyRedeemable:         true,
+				EarlyRedemptionFee:      0.50,
 
-				SaleStart: testutil.Must(time.ParseInLocation(time.DateOnly, "2025-01-01", tz.UnifiedTimezone)),
-				SaleEnd:   testutil.Must(time.ParseInLocation(time.DateOnly, "2025-01-31", tz.UnifiedTimezone)),
+				SaleStart: testutil.Must(civil.Parse("2025-01-01")),
+				SaleEnd:   testutil.Must(civil.Parse("2025-01-31")),
 			},
 		},
 	}
@@ -171,6 +228,54 @@ func TestXLSRepository_Lookup(t *testing.T) {
 	}
 }
 
+func TestXLSRepository_List(t *testing.T) {
+	r, err := LoadFromXLSX(slog.New(slog.DiscardHandler), filepath.Join(testutil.TestDataDirectory(), "data.xlsx"))
+	if err != nil {
+		t.Fatalf("LoadFromXLSX() error = %v", err)
+	}
+
+	bonds := r.List()
+	if len(bonds) != len(r.bonds) {
+		t.Fatalf("List() returned %d bonds, want %d", len(bonds), len(r.bonds))
+	}
+	for i := 1; i < len(bonds); i++ {
+		if bonds[i-1].Name >= bonds[i].Name {
+			t.Fatalf("List() not sorted: %s before %s", bonds[i-1].Name, bonds[i].Name)
+		}
+	}
+}
+
+func TestOnSale(t *testing.T) {
+	r, err := LoadFromXLSX(slog.New(slog.DiscardHandler), filepath.Join(testutil.TestDataDirectory(), "data.xlsx"))
+	if err != nil {
+		t.Fatalf("LoadFromXLSX() error = %v", err)
+	}
+
+	tests := []struct {
+		series  string
+		at      civil.Date
+		want    string
+		wantErr error
+	}{
+		{series: "EDO", at: civil.New(2025, time.November, 1), want: "EDO1135"},
+		{series: "TOS", at: civil.New(2025, time.November, 30), want: "TOS1128"},
+		{series: "OTS", at: civil.New(2025, time.November, 30), want: "OTS0226"},
+		{series: "EDO", at: civil.New(2035, time.January, 1), wantErr: bond.ErrNotOnSale},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.series+tt.at.Format("2006-01-02"), func(t *testing.T) {
+			got, err := bond.OnSale(r, tt.series, tt.at)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("OnSale() error = %v, want %v", err, tt.wantErr)
+			}
+			if got.Name != tt.want {
+				t.Errorf("OnSale() = %s, want %s", got.Name, tt.want)
+			}
+		})
+	}
+}
+
 func equal(a, b bond.Bond) bool {
 	if a.Name != b.Name {
 		return false
@@ -193,6 +298,15 @@ func equal(a, b bond.Bond) bool {
 	if a.CouponPaymentsFrequency != b.CouponPaymentsFrequency {
 		return false
 	}
+	if !floatEqual(float64(a.MaturityInterest), float64(b.MaturityInterest)) {
+		return false
+	}
+	if a.EarlyRedeemable != b.EarlyRedeemable {
+		return false
+	}
+	if !floatEqual(float64(a.EarlyRedemptionFee), float64(b.EarlyRedemptionFee)) {
+		return false
+	}
 	if len(a.InterestPeriods) != len(b.InterestPeriods) {
 		return false
 	}
@@ -201,10 +315,18 @@ func equal(a, b bond.Bond) bool {
 			return false
 		}
 	}
-	if !a.SaleStart.Equal(b.SaleStart) {
+	if len(a.PeriodInterest) != len(b.PeriodInterest) {
+		return false
+	}
+	for i := range a.PeriodInterest {
+		if !floatEqual(float64(a.PeriodInterest[i]), float64(b.PeriodInterest[i])) {
+			return false
+		}
+	}
+	if a.SaleStart != b.SaleStart {
 		return false
 	}
-	if !a.SaleEnd.Equal(b.SaleEnd) {
+	if a.SaleEnd != b.SaleEnd {
 		return false
 	}
 	return true
@@ -2,11 +2,12 @@ package calculator
 
 import (
 	"errors"
-	"math"
-	"time"
+	"slices"
 
 	"github.com/maciekmm/obligacje/bond"
-	"github.com/maciekmm/obligacje/tz"
+	"github.com/maciekmm/obligacje/civil"
+	"github.com/maciekmm/obligacje/decimal"
+	"github.com/maciekmm/obligacje/index"
 )
 
 var (
@@ -14,42 +15,222 @@ var (
 	ErrValuationDateAfterMaturity      = errors.New("valuation date is after last known interest period")
 )
 
+// MaxQuantity is the largest number of bonds amounts are calculated for,
+// which keeps totals well within the range of decimals, see bond.Price.Times.
+const MaxQuantity = 1_000_000
+
 type Calculator struct {
+	indices Indices
+}
+
+// Indices are the market indices used to derive interest rates the issuer hasn't published yet.
+// A nil index is treated as unknown.
+type Indices struct {
+	// Inflation is the year-on-year consumer price index.
+	Inflation index.Index
+	// ReferenceRate is the NBP reference rate.
+	ReferenceRate index.Index
+}
+
+type Option func(*Calculator)
+
+// WithInflation derives unpublished rates of inflation-linked bonds (COI, EDO, ROS, ROD) from the CPI.
+func WithInflation(cpi index.Index) Option {
+	return func(c *Calculator) {
+		c.indices.Inflation = cpi
+	}
+}
+
+// WithReferenceRate derives unpublished rates of floating rate bonds (ROR, DOR) from the NBP reference rate.
+func WithReferenceRate(rate index.Index) Option {
+	return func(c *Calculator) {
+		c.indices.ReferenceRate = rate
+	}
+}
+
+func NewCalculator(opts ...Option) *Calculator {
+	c := &Calculator{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithScenario returns a calculator that falls back to the scenario indices for dates
+// its own indices don't cover, e.g. to project values of floating rate bonds into the future.
+func (c *Calculator) WithScenario(scenario Indices) *Calculator {
+	return &Calculator{
+		indices: Indices{
+			Inflation:     index.Fallback(c.indices.Inflation, scenario.Inflation),
+			ReferenceRate: index.Fallback(c.indices.ReferenceRate, scenario.ReferenceRate),
+		},
+	}
+}
+
+// Valuation is the value of a single bond at a given date.
+type Valuation struct {
+	// Price is the amount the bond is worth, including interest accrued in the current period.
+	// For coupon paying bonds it excludes coupons that were already paid out.
+	Price bond.Price
+	// PaidCoupons is the sum of coupons paid out until the valuation date.
+	PaidCoupons bond.Price
+	// Coupons are the individual coupons paid out until the valuation date, oldest first.
+	Coupons []bond.Price
+	// Derived reports whether the valuation relies on interest rates derived from market indices
+	// rather than published by the issuer.
+	Derived bool
+}
+
+// Times returns the valuation of quantity bonds. Amounts are rounded per bond and then multiplied,
+// the way brokerage accounts report holdings. It returns decimal.ErrOverflow if the amounts are too large.
+func (v Valuation) Times(quantity int) (Valuation, error) {
+	total := Valuation{Coupons: make([]bond.Price, len(v.Coupons)), Derived: v.Derived}
+	var err error
+	if total.Price, err = v.Price.Times(quantity); err != nil {
+		return Valuation{}, err
+	}
+	if total.PaidCoupons, err = v.PaidCoupons.Times(quantity); err != nil {
+		return Valuation{}, err
+	}
+	for i, coupon := range v.Coupons {
+		if total.Coupons[i], err = coupon.Times(quantity); err != nil {
+			return Valuation{}, err
+		}
+	}
+	return total, nil
 }
 
-func NewCalculator() *Calculator {
-	return &Calculator{}
+func (c *Calculator) Calculate(bnd bond.Bond, purchaseDay int, valuatedAt civil.Date) (bond.Price, error) {
+	valuation, err := c.Valuate(bnd, purchaseDay, valuatedAt)
+	return valuation.Price, err
 }
 
-func (c *Calculator) Calculate(bnd bond.Bond, purchaseDay int, valuatedAt time.Time) (bond.Price, error) {
-	purchaseDate := time.Date(bnd.SaleStart.Year(), bnd.SaleStart.Month(), purchaseDay, 0, 0, 0, 0, tz.UnifiedTimezone)
+// Valuate calculates the value of a bond bought on purchaseDay of its sale month.
+// Interest of capitalising bonds is added to the principal at the end of each period,
+// while coupon paying bonds pay it out, so only the current period's interest contributes to the price.
+func (c *Calculator) Valuate(bnd bond.Bond, purchaseDay int, valuatedAt civil.Date) (Valuation, error) {
+	return c.valuate(bnd, purchaseDay, valuatedAt, nil)
+}
+
+// valuate implements Valuate, recording every step in explanation unless it's nil.
+func (c *Calculator) valuate(bnd bond.Bond, purchaseDay int, valuatedAt civil.Date, explanation *Explanation) (Valuation, error) {
+	purchaseDate := civil.New(bnd.SaleStart.Year(), bnd.SaleStart.Month(), purchaseDay)
 	if valuatedAt.Before(purchaseDate) {
-		return 0, ErrValuationDateBeforePurchaseDate
+		return Valuation{}, ErrValuationDateBeforePurchaseDate
+	}
+
+	strategy, err := StrategyFor(bnd)
+	if err != nil {
+		return Valuation{}, err
+	}
+	official := len(bnd.InterestPeriods)
+	bnd, err = c.deriveRates(bnd, purchaseDay, strategy)
+	if err != nil {
+		return Valuation{}, err
 	}
 
-	price := float64(bnd.FaceValue)
+	price := bnd.FaceValue.Decimal()
+	var accrued, coupons decimal.Decimal
+	var paid []bond.Price
+	derived := false
+	valuation := func() Valuation {
+		if explanation != nil {
+			explanation.Unrounded = price + accrued
+		}
+		return Valuation{
+			Price:       bond.PriceOf((price + accrued).Round(2)),
+			PaidCoupons: bond.PriceOf(coupons),
+			Coupons:     paid,
+			Derived:     derived,
+		}
+	}
 	for i, perc := range bnd.InterestPeriods {
 		start, end, err := bnd.Period(i, purchaseDay)
 		if err != nil {
-			return 0, err
+			return Valuation{}, err
 		}
 
 		if valuatedAt.Before(start) {
 			break
 		}
+		derived = derived || i >= official
+
+		periodDays := end.DaysSince(start)
+		heldDays := periodDays
+		if valuatedAt.Before(end) {
+			heldDays = valuatedAt.DaysSince(start)
+		}
+
+		step := PeriodExplanation{
+			Index:       i,
+			Start:       start,
+			End:         end,
+			Rate:        perc,
+			RateDerived: i >= official,
+			HeldDays:    heldDays,
+			PeriodDays:  periodDays,
+			Principal:   price,
+		}
 
-		if valuatedAt.After(end) || valuatedAt.Equal(end) {
-			price = float64(price) * (1.0 + float64(perc)/float64(bnd.CouponPaymentsFrequency))
-		} else {
-			periodDays := int(math.Round(end.Sub(start).Hours() / 24))
-			heldDays := int(math.Round(valuatedAt.Sub(start).Hours() / 24))
-			price = float64(price) * (1.0 + float64(perc)/float64(bnd.CouponPaymentsFrequency)*float64(heldDays)/float64(periodDays))
+		final := i == bnd.InterestPeriodCount()-1
+		switch {
+		case !strategy.PaysCoupons():
+			// capitalised interest isn't rounded, only the resulting value is,
+			// which matches the maturity interest published by the issuer
+			step.Interest = strategy.Interest(price, perc, heldDays, periodDays, decimal.Scale)
+			step.Treatment = InterestCapitalised
+			price += step.Interest
+		case heldDays == periodDays && !final:
+			// the coupon of the final period is paid out together with the face value
+			step.Interest = strategy.Interest(price, perc, heldDays, periodDays, 2)
+			step.Treatment = InterestPaidOut
+			coupons += step.Interest
+			paid = append(paid, bond.PriceOf(step.Interest))
+			accrued = 0
+		default:
+			step.Interest = strategy.Interest(price, perc, heldDays, periodDays, 2)
+			step.Treatment = InterestAccrued
+			accrued = step.Interest
+		}
+
+		if explanation != nil {
+			step.Unrounded = strategy.Interest(step.Principal, perc, heldDays, periodDays, decimal.Scale)
+			step.Price = price + accrued
+			explanation.Periods = append(explanation.Periods, step)
 		}
 
 		if len(bnd.InterestPeriods) == i+1 && valuatedAt.After(end) {
-			return bond.Price(math.Round(price*100.0) / 100.0), ErrValuationDateAfterMaturity
+			return valuation(), ErrValuationDateAfterMaturity
 		}
 	}
 
-	return bond.Price(math.Round(price*100.0) / 100.0), nil
+	return valuation(), nil
+}
+
+// deriveRates returns the bond with rates of periods not published yet derived from market indices.
+// Derivation stops at the first period whose rate can't be derived.
+func (c *Calculator) deriveRates(bnd bond.Bond, purchaseDay int, strategy Strategy) (bond.Bond, error) {
+	count := bnd.InterestPeriodCount()
+	if len(bnd.InterestPeriods) == 0 || len(bnd.InterestPeriods) >= count {
+		// the first period rate is always fixed by the issuer
+		return bnd, nil
+	}
+
+	var rates []bond.Percentage
+	for i := len(bnd.InterestPeriods); i < count; i++ {
+		start, _, err := bnd.Period(i, purchaseDay)
+		if err != nil {
+			return bond.Bond{}, err
+		}
+		rate, ok := strategy.DeriveRate(bnd, start, c.indices)
+		if !ok {
+			break
+		}
+		rates = append(rates, rate)
+	}
+	if len(rates) > 0 {
+		// don't modify the repository's slice
+		bnd.InterestPeriods = append(slices.Clip(bnd.InterestPeriods), rates...)
+	}
+	return bnd, nil
 }
@@ -1,6 +1,7 @@
-package calculator
+package calculator_test
 
 import (
+	"errors"
 	"log/slog"
 	"math"
 	"path/filepath"
@@ -9,8 +10,11 @@ import (
 
 	"github.com/maciekmm/obligacje/bond"
 	"github.com/maciekmm/obligacje/bondxls"
+	"github.com/maciekmm/obligacje/calculator"
+	"github.com/maciekmm/obligacje/civil"
+	"github.com/maciekmm/obligacje/decimal"
+	"github.com/maciekmm/obligacje/index"
 	"github.com/maciekmm/obligacje/internal/testutil"
-	"github.com/maciekmm/obligacje/tz"
 )
 
 func MustLoadLocation(name string) *time.Location {
@@ -34,7 +38,7 @@ func TestCalculator_Calculate(t *testing.T) {
 	type args struct {
 		name        string
 		purchaseDay int
-		valuatedAt  time.Time
+		valuatedAt  civil.Date
 	}
 	tests := []struct {
 		name    string
@@ -42,24 +46,42 @@ func TestCalculator_Calculate(t *testing.T) {
 		want    bond.Price
 		wantErr bool
 	}{
-		// OTS uses different calculation logic and returns slightly incorrect data
-		// API won't support OTS unless fixed
-		// {
-		// 	name: "OTS bond at the end of valuation",
-		// 	args: args{
-		// 		name:      "OTS0825",
-		// 		purchasedAt: time.Date(2025, time.May, 1, 0, 0, 0, 0, tz.WarsawTimezone),
-		// 		valuatedAt:  time.Date(2025, time.August, 1, 0, 0, 0, 0, tz.WarsawTimezone),
-		// 	},
-		// 	want:    100.76,
-		// 	wantErr: false,
-		// },
+		{
+			name: "OTS bond at the end of valuation",
+			args: args{
+				name:        "OTS0825",
+				purchaseDay: 1,
+				valuatedAt:  civil.New(2025, time.August, 1),
+			},
+			want:    100.76,
+			wantErr: false,
+		},
+		{
+			name: "OTS bond in the middle of the period",
+			args: args{
+				name:        "OTS0825",
+				purchaseDay: 1,
+				valuatedAt:  civil.New(2025, time.June, 15),
+			},
+			want:    100.37,
+			wantErr: false,
+		},
+		{
+			name: "OTS bond purchased at the end of the month, shorter period",
+			args: args{
+				name:        "OTS0126",
+				purchaseDay: 31,
+				valuatedAt:  civil.New(2026, time.January, 31),
+			},
+			want:    100.69,
+			wantErr: false,
+		},
 		{
 			name: "TOS bond before DST change",
 			args: args{
 				name:        "TOS1125",
 				purchaseDay: 1,
-				valuatedAt:  time.Date(2023, time.March, 26, 0, 0, 0, 0, tz.UnifiedTimezone),
+				valuatedAt:  civil.New(2023, time.March, 26),
 			},
 			want:    102.72,
 			wantErr: false,
@@ -69,7 +91,7 @@ func TestCalculator_Calculate(t *testing.T) {
 			args: args{
 				name:        "TOS1125",
 				purchaseDay: 1,
-				valuatedAt:  time.Date(2023, time.March, 27, 0, 0, 0, 0, tz.UnifiedTimezone),
+				valuatedAt:  civil.New(2023, time.March, 27),
 			},
 			want:    102.74,
 			wantErr: false,
@@ -79,7 +101,7 @@ func TestCalculator_Calculate(t *testing.T) {
 			args: args{
 				name:        "TOS1125",
 				purchaseDay: 1,
-				valuatedAt:  time.Date(2025, time.November, 1, 0, 0, 0, 0, tz.UnifiedTimezone),
+				valuatedAt:  civil.New(2025, time.November, 1),
 			},
 			want:    121.99,
 			wantErr: false,
@@ -89,7 +111,7 @@ func TestCalculator_Calculate(t *testing.T) {
 			args: args{
 				name:        "TOS1125",
 				purchaseDay: 1,
-				valuatedAt:  time.Date(2025, time.April, 13, 0, 0, 0, 0, tz.UnifiedTimezone),
+				valuatedAt:  civil.New(2025, time.April, 13),
 			},
 			want:    117.66,
 			wantErr: false,
@@ -99,7 +121,7 @@ func TestCalculator_Calculate(t *testing.T) {
 			args: args{
 				name:        "EDO0834",
 				purchaseDay: 12,
-				valuatedAt:  time.Date(2025, time.December, 6, 0, 0, 0, 0, tz.UnifiedTimezone),
+				valuatedAt:  civil.New(2025, time.December, 6),
 			},
 			want:    108.87,
 			wantErr: false,
@@ -109,7 +131,7 @@ func TestCalculator_Calculate(t *testing.T) {
 			args: args{
 				name:        "EDO0834",
 				purchaseDay: 12,
-				valuatedAt:  time.Date(2025, time.December, 20, 0, 0, 0, 0, tz.UnifiedTimezone),
+				valuatedAt:  civil.New(2025, time.December, 20),
 			},
 			want:    109.12,
 			wantErr: false,
@@ -119,7 +141,7 @@ func TestCalculator_Calculate(t *testing.T) {
 			args: args{
 				name:        "EDO0834",
 				purchaseDay: 1,
-				valuatedAt:  time.Date(2025, time.August, 1, 0, 0, 0, 0, tz.UnifiedTimezone),
+				valuatedAt:  civil.New(2025, time.August, 1),
 			},
 			want:    106.80,
 			wantErr: false,
@@ -129,7 +151,7 @@ func TestCalculator_Calculate(t *testing.T) {
 			args: args{
 				name:        "EDO0834",
 				purchaseDay: 1,
-				valuatedAt:  time.Date(2025, time.August, 2, 0, 0, 0, 0, tz.UnifiedTimezone),
+				valuatedAt:  civil.New(2025, time.August, 2),
 			},
 			want:    106.82,
 			wantErr: false,
@@ -139,7 +161,7 @@ func TestCalculator_Calculate(t *testing.T) {
 			args: args{
 				name:        "EDO0834",
 				purchaseDay: 1,
-				valuatedAt:  time.Date(2025, time.August, 22, 0, 0, 0, 0, tz.UnifiedTimezone),
+				valuatedAt:  civil.New(2025, time.August, 22),
 			},
 			want:    107.17,
 			wantErr: false,
@@ -149,7 +171,7 @@ func TestCalculator_Calculate(t *testing.T) {
 			args: args{
 				name:        "EDO0834",
 				purchaseDay: 2,
-				valuatedAt:  time.Date(2025, time.August, 23, 0, 0, 0, 0, tz.UnifiedTimezone),
+				valuatedAt:  civil.New(2025, time.August, 23),
 			},
 			want:    107.17,
 			wantErr: false,
@@ -159,7 +181,7 @@ func TestCalculator_Calculate(t *testing.T) {
 			args: args{
 				name:        "EDO0832",
 				purchaseDay: 20,
-				valuatedAt:  time.Date(2024, time.August, 9, 0, 0, 0, 0, tz.UnifiedTimezone),
+				valuatedAt:  civil.New(2024, time.August, 9),
 			},
 			want:    119.95,
 			wantErr: false,
@@ -169,7 +191,7 @@ func TestCalculator_Calculate(t *testing.T) {
 			args: args{
 				name:        "EDO0935",
 				purchaseDay: 2,
-				valuatedAt:  time.Date(2025, time.December, 6, 0, 0, 0, 0, tz.UnifiedTimezone),
+				valuatedAt:  civil.New(2025, time.December, 6),
 			},
 			want:    101.56,
 			wantErr: false,
@@ -179,13 +201,13 @@ func TestCalculator_Calculate(t *testing.T) {
 			args: args{
 				name:        "EDO0935",
 				purchaseDay: 2,
-				valuatedAt:  time.Date(2025, time.September, 1, 0, 0, 0, 0, tz.UnifiedTimezone),
+				valuatedAt:  civil.New(2025, time.September, 1),
 			},
 			wantErr: true,
 		},
 	}
 
-	c := NewCalculator()
+	c := calculator.NewCalculator()
 	repo := LoadBondRepository()
 
 	for _, tt := range tests {
@@ -206,3 +228,324 @@ func TestCalculator_Calculate(t *testing.T) {
 		})
 	}
 }
+
+func TestCalculator_Calculate_MatchesPublishedMaturityInterest(t *testing.T) {
+	c := calculator.NewCalculator()
+	repo := LoadBondRepository()
+
+	for _, name := range []string{"OTS0118", "OTS0318", "OTS0825", "OTS0126", "TOS0825", "TOS0728"} {
+		t.Run(name, func(t *testing.T) {
+			bnd, err := repo.Lookup(name)
+			if err != nil {
+				t.Fatalf("Lookup() error = %v", err)
+			}
+			_, maturity, err := bnd.Period(bnd.InterestPeriodCount()-1, 1)
+			if err != nil {
+				t.Fatalf("Period() error = %v", err)
+			}
+			got, err := c.Calculate(bnd, 1, maturity)
+			if err != nil {
+				t.Fatalf("Calculate() error = %v", err)
+			}
+			want := bnd.FaceValue + bnd.MaturityInterest
+			if math.Abs(float64(want)-float64(got)) > 1e-9 {
+				t.Errorf("Calculate() at maturity got = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+// TestCalculator_Calculate_MatchesAllPublishedMaturityInterest checks the rounding of capitalised interest
+// against every issue with the maturity interest published, including inflation-linked series with rates varying over 12 years.
+func TestCalculator_Calculate_MatchesAllPublishedMaturityInterest(t *testing.T) {
+	c := calculator.NewCalculator()
+	repo := LoadBondRepository()
+
+	checked := 0
+	for _, bnd := range repo.List() {
+		if bnd.MaturityInterest == 0 {
+			continue
+		}
+		checked++
+		_, maturity, err := bnd.Period(bnd.InterestPeriodCount()-1, 1)
+		if err != nil {
+			t.Fatalf("%s: Period() error = %v", bnd.Name, err)
+		}
+		got, err := c.Calculate(bnd, 1, maturity)
+		if err != nil {
+			t.Errorf("%s: Calculate() error = %v", bnd.Name, err)
+			continue
+		}
+		if want := bnd.FaceValue.Decimal() + bnd.MaturityInterest.Decimal(); got.Decimal() != want {
+			t.Errorf("%s: Calculate() at maturity got = %v, want %v", bnd.Name, got, want)
+		}
+	}
+	if checked == 0 {
+		t.Fatal("no bonds with published maturity interest")
+	}
+}
+
+func TestCalculator_Valuate_Coupons(t *testing.T) {
+	tests := []struct {
+		name            string
+		bondName        string
+		purchaseDay     int
+		valuatedAt      civil.Date
+		wantPrice       bond.Price
+		wantPaidCoupons bond.Price
+		wantErr         error
+	}{
+		{
+			name:        "ROR in the middle of the first period",
+			bondName:    "ROR0126",
+			purchaseDay: 1,
+			valuatedAt:  civil.New(2025, time.January, 16),
+			wantPrice:   100.23,
+		},
+		{
+			name:            "ROR at the end of the first period pays the coupon out",
+			bondName:        "ROR0126",
+			purchaseDay:     1,
+			valuatedAt:      civil.New(2025, time.February, 1),
+			wantPrice:       100.00,
+			wantPaidCoupons: 0.48,
+		},
+		{
+			name:            "ROR at maturity includes the last coupon in the price",
+			bondName:        "ROR0126",
+			purchaseDay:     1,
+			valuatedAt:      civil.New(2026, time.January, 1),
+			wantPrice:       100.35,
+			wantPaidCoupons: 4.90,
+		},
+		{
+			name:            "COI in the second year",
+			bondName:        "COI0528",
+			purchaseDay:     1,
+			valuatedAt:      civil.New(2025, time.November, 1),
+			wantPrice:       103.10,
+			wantPaidCoupons: 6.55,
+		},
+		{
+			name:            "ROR bought on the first period only known period pays out the coupon",
+			bondName:        "ROR1226",
+			purchaseDay:     1,
+			valuatedAt:      civil.New(2026, time.January, 2),
+			wantPrice:       100.00,
+			wantPaidCoupons: 0.35,
+			wantErr:         calculator.ErrValuationDateAfterMaturity,
+		},
+		{
+			name:        "EDO capitalises interest",
+			bondName:    "EDO0834",
+			purchaseDay: 12,
+			valuatedAt:  civil.New(2025, time.December, 6),
+			wantPrice:   108.87,
+		},
+	}
+
+	c := calculator.NewCalculator()
+	repo := LoadBondRepository()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bnd, err := repo.Lookup(tt.bondName)
+			if err != nil {
+				t.Fatalf("Lookup() error = %v", err)
+			}
+			got, err := c.Valuate(bnd, tt.purchaseDay, tt.valuatedAt)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Valuate() error = %v, want %v", err, tt.wantErr)
+			}
+			if math.Abs(float64(tt.wantPrice)-float64(got.Price)) > 1e-9 {
+				t.Errorf("Valuate() Price = %v, want %v", got.Price, tt.wantPrice)
+			}
+			if math.Abs(float64(tt.wantPaidCoupons)-float64(got.PaidCoupons)) > 1e-9 {
+				t.Errorf("Valuate() PaidCoupons = %v, want %v", got.PaidCoupons, tt.wantPaidCoupons)
+			}
+		})
+	}
+}
+
+func LoadCPI() index.Index {
+	cpi, err := index.LoadCPI(testutil.CPIDataFile())
+	if err != nil {
+		panic(err)
+	}
+	return cpi
+}
+
+func LoadReferenceRate() index.Index {
+	rate, err := index.LoadReferenceRate(testutil.ReferenceRateDataFile())
+	if err != nil {
+		panic(err)
+	}
+	return rate
+}
+
+func TestCalculator_DerivedRatesMatchPublished(t *testing.T) {
+	c := calculator.NewCalculator(calculator.WithInflation(LoadCPI()), calculator.WithReferenceRate(LoadReferenceRate()))
+	repo := LoadBondRepository()
+
+	for _, name := range []string{"COI0528", "COI1124", "EDO0834", "EDO0132", "ROS0627", "ROD1236", "ROR0126", "ROR0623", "DOR1125", "DOR0126"} {
+		t.Run(name, func(t *testing.T) {
+			published, err := repo.Lookup(name)
+			if err != nil {
+				t.Fatalf("Lookup() error = %v", err)
+			}
+			if len(published.InterestPeriods) < 2 {
+				t.Fatalf("%s has %d published rates, want at least 2", name, len(published.InterestPeriods))
+			}
+			bnd := published
+			bnd.InterestPeriods = published.InterestPeriods[:1]
+
+			schedule, err := c.CashFlows(bnd, 1)
+			if err != nil {
+				t.Fatalf("CashFlows() error = %v", err)
+			}
+			for i, rate := range published.InterestPeriods[1:] {
+				p := schedule.Periods[i+1]
+				if !p.RateKnown || !p.RateDerived {
+					t.Fatalf("period %d rate known = %v, derived = %v, want derived", i+1, p.RateKnown, p.RateDerived)
+				}
+				if math.Abs(float64(p.Rate-rate)) > 1e-9 {
+					t.Errorf("period %d derived rate = %v, published %v", i+1, p.Rate, rate)
+				}
+			}
+		})
+	}
+}
+
+func TestCalculator_Valuate_DerivedRates(t *testing.T) {
+	repo := LoadBondRepository()
+	published, err := repo.Lookup("COI0528")
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	bnd := published
+	bnd.InterestPeriods = published.InterestPeriods[:1]
+	valuatedAt := civil.New(2025, time.November, 1)
+
+	t.Run("without CPI", func(t *testing.T) {
+		got, err := calculator.NewCalculator().Valuate(bnd, 1, valuatedAt)
+		if !errors.Is(err, calculator.ErrValuationDateAfterMaturity) {
+			t.Fatalf("Valuate() error = %v, want %v", err, calculator.ErrValuationDateAfterMaturity)
+		}
+		if got.Derived {
+			t.Error("Valuate() Derived = true, want false")
+		}
+	})
+
+	t.Run("with CPI", func(t *testing.T) {
+		got, err := calculator.NewCalculator(calculator.WithInflation(LoadCPI())).Valuate(bnd, 1, valuatedAt)
+		if err != nil {
+			t.Fatalf("Valuate() error = %v", err)
+		}
+		if !got.Derived {
+			t.Error("Valuate() Derived = false, want true")
+		}
+		if math.Abs(float64(got.Price-103.10)) > 1e-9 {
+			t.Errorf("Valuate() Price = %v, want 103.10", got.Price)
+		}
+	})
+
+	t.Run("published rates are not derived", func(t *testing.T) {
+		got, err := calculator.NewCalculator(calculator.WithInflation(LoadCPI())).Valuate(published, 1, valuatedAt)
+		if err != nil {
+			t.Fatalf("Valuate() error = %v", err)
+		}
+		if got.Derived {
+			t.Error("Valuate() Derived = true, want false")
+		}
+	})
+}
+
+func TestCalculator_Valuate_ReferenceRate(t *testing.T) {
+	repo := LoadBondRepository()
+	bnd, err := repo.Lookup("ROR1226")
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+
+	// the rate of the second period follows the NBP reference rate of 4.00% in effect at the end of December 2025
+	c := calculator.NewCalculator(calculator.WithReferenceRate(LoadReferenceRate()))
+	got, err := c.Valuate(bnd, 1, civil.New(2026, time.January, 16))
+	if err != nil {
+		t.Fatalf("Valuate() error = %v", err)
+	}
+	if !got.Derived {
+		t.Error("Valuate() Derived = false, want true")
+	}
+	if math.Abs(float64(got.Price-100.16)) > 1e-9 {
+		t.Errorf("Valuate() Price = %v, want 100.16", got.Price)
+	}
+	if math.Abs(float64(got.PaidCoupons-0.35)) > 1e-9 {
+		t.Errorf("Valuate() PaidCoupons = %v, want 0.35", got.PaidCoupons)
+	}
+}
+
+func TestCalculator_WithScenario(t *testing.T) {
+	repo := LoadBondRepository()
+	bnd, err := repo.Lookup("EDO1035")
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	_, maturity, err := bnd.Period(bnd.InterestPeriodCount()-1, 1)
+	if err != nil {
+		t.Fatalf("Period() error = %v", err)
+	}
+
+	c := calculator.NewCalculator(calculator.WithInflation(LoadCPI()))
+	if _, err := c.Valuate(bnd, 1, maturity); !errors.Is(err, calculator.ErrValuationDateAfterMaturity) {
+		t.Fatalf("Valuate() without scenario error = %v, want %v", err, calculator.ErrValuationDateAfterMaturity)
+	}
+
+	got, err := c.WithScenario(calculator.Indices{Inflation: index.Constant(0.03)}).Valuate(bnd, 1, maturity)
+	if err != nil {
+		t.Fatalf("Valuate() error = %v", err)
+	}
+	if !got.Derived {
+		t.Error("Valuate() Derived = false, want true")
+	}
+	// first year at the published rate, then 3% inflation plus the margin capitalised for nine years
+	want := 100 * (1 + float64(bnd.InterestPeriods[0])) * math.Pow(1+0.03+float64(bnd.Margin), 9)
+	if math.Abs(float64(got.Price)-want) > 0.005 {
+		t.Errorf("Valuate() Price = %v, want %.2f", got.Price, want)
+	}
+}
+
+func TestValuation_Times(t *testing.T) {
+	c := calculator.NewCalculator()
+	bnd, err := LoadBondRepository().Lookup("COI0528")
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+
+	valuation, err := c.Valuate(bnd, 1, civil.New(2025, time.November, 1))
+	if err != nil {
+		t.Fatalf("Valuate() error = %v", err)
+	}
+	got, err := valuation.Times(1000)
+	if err != nil {
+		t.Fatalf("Times() error = %v", err)
+	}
+
+	// 103.10 per bond, while the unrounded 103.10027397 would add up to 103100.27
+	if math.Abs(float64(got.Price-103100.00)) > 1e-9 {
+		t.Errorf("Times() Price = %v, want 103100.00", got.Price)
+	}
+	if math.Abs(float64(got.PaidCoupons-6550.00)) > 1e-9 {
+		t.Errorf("Times() PaidCoupons = %v, want 6550.00", got.PaidCoupons)
+	}
+	if len(got.Coupons) != 1 || math.Abs(float64(got.Coupons[0]-6550.00)) > 1e-9 {
+		t.Errorf("Times() Coupons = %v, want [6550]", got.Coupons)
+	}
+	if len(valuation.Coupons) != 1 || math.Abs(float64(valuation.Coupons[0]-6.55)) > 1e-9 {
+		t.Errorf("Times() modified the coupons of the valuation: %v", valuation.Coupons)
+	}
+
+	// 103.10 per bond is too much for a decimal for a billion bonds
+	if _, err := valuation.Times(1_000_000_000); !errors.Is(err, decimal.ErrOverflow) {
+		t.Errorf("Times() error = %v, want %v", err, decimal.ErrOverflow)
+	}
+}
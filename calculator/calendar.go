@@ -0,0 +1,101 @@
+package calculator
+
+import (
+	"errors"
+
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/civil"
+)
+
+// CalendarEventKind is the kind of a dated event in the life of a purchased bond.
+type CalendarEventKind string
+
+const (
+	// CalendarCoupon is the payout of the interest of a period of a bond paying coupons.
+	CalendarCoupon CalendarEventKind = "coupon"
+	// CalendarFeeFreeEnd is the last day of the window after the purchase or, for bonds paying coupons,
+	// after a coupon payment, in which the bonds can be redeemed early without the fee, as the fee is capped
+	// at the interest accrued and none has accrued yet. Bonds which can't be redeemed early have no such window.
+	CalendarFeeFreeEnd CalendarEventKind = "fee_free_end"
+	// CalendarRateReset is the start of a period with a new interest rate.
+	CalendarRateReset CalendarEventKind = "rate_reset"
+	// CalendarExchangeDeadline is the last day before maturity, by which rolling the bonds over into
+	// the issue on sale at maturity (zamiana) has to be ordered. It is left out if that issue has no exchange price.
+	CalendarExchangeDeadline CalendarEventKind = "exchange_deadline"
+	// CalendarMaturity is the redemption of the bonds together with the interest not paid out yet.
+	CalendarMaturity CalendarEventKind = "maturity"
+)
+
+// CalendarEvent is a dated event in the life of a purchased bond.
+type CalendarEvent struct {
+	Date civil.Date
+	Kind CalendarEventKind
+	// Period is the zero-based index of the interest period the event ends or, for rate resets, starts.
+	Period int
+}
+
+// Calendar returns the events in the life of a bond bought on purchaseDay ordered by date:
+// coupons, rate resets, ends of the windows without the early redemption fee, the exchange deadline and the maturity.
+// The issue of the series on sale at maturity is looked up in repo, or assumed like in Simulate,
+// to tell whether the bonds can be exchanged into it. Windows without the fee are left out for periods
+// whose rates aren't known yet, since how fast interest accrues depends on the rate.
+func (c *Calculator) Calendar(repo bond.Repository, bnd bond.Bond, purchaseDay int) ([]CalendarEvent, error) {
+	strategy, err := StrategyFor(bnd)
+	if err != nil {
+		return nil, err
+	}
+
+	count := bnd.InterestPeriodCount()
+	var events []CalendarEvent
+	for i := range count {
+		start, end, err := bnd.Period(i, purchaseDay)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 && !strategy.FixedRate() {
+			events = append(events, CalendarEvent{Date: start, Kind: CalendarRateReset, Period: i})
+		}
+		if bnd.EarlyRedeemable && (i == 0 || strategy.PaysCoupons()) {
+			feeFreeEnd, ok, err := c.feeFreeEnd(bnd, purchaseDay, start, end)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				events = append(events, CalendarEvent{Date: feeFreeEnd, Kind: CalendarFeeFreeEnd, Period: i})
+			}
+		}
+		if i == count-1 {
+			target, _, err := issueOnSale(repo, bnd.Series(), end)
+			if err != nil && !errors.Is(err, bond.ErrNotOnSale) {
+				return nil, err
+			}
+			if target.ExchangePrice > 0 {
+				events = append(events, CalendarEvent{Date: end.AddDate(0, 0, -1), Kind: CalendarExchangeDeadline, Period: i})
+			}
+			events = append(events, CalendarEvent{Date: end, Kind: CalendarMaturity, Period: i})
+			break
+		}
+		if strategy.PaysCoupons() {
+			events = append(events, CalendarEvent{Date: end, Kind: CalendarCoupon, Period: i})
+		}
+	}
+	return events, nil
+}
+
+// feeFreeEnd returns the last day from start on which the bonds are redeemed early without the fee.
+// It reports false if the fee is charged until end, when the next window starts, or the rates aren't known.
+func (c *Calculator) feeFreeEnd(bnd bond.Bond, purchaseDay int, start, end civil.Date) (civil.Date, bool, error) {
+	for d := start; d.Before(end); d = d.AddDate(0, 0, 1) {
+		redemption, err := c.Redeem(bnd, purchaseDay, d, Taxable)
+		if errors.Is(err, ErrValuationDateAfterMaturity) {
+			return civil.Date{}, false, nil
+		}
+		if err != nil {
+			return civil.Date{}, false, err
+		}
+		if redemption.Fee > 0 {
+			return d.AddDate(0, 0, -1), d.After(start), nil
+		}
+	}
+	return civil.Date{}, false, nil
+}
@@ -0,0 +1,122 @@
+package calculator_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/maciekmm/obligacje/calculator"
+	"github.com/maciekmm/obligacje/civil"
+)
+
+func TestCalculator_Calendar(t *testing.T) {
+	c := calculator.NewCalculator()
+	repo := LoadBondRepository()
+
+	tests := []struct {
+		name        string
+		bondName    string
+		purchaseDay int
+		want        []calculator.CalendarEvent
+	}{
+		{
+			name:        "coupons and rate resets",
+			bondName:    "COI0528",
+			purchaseDay: 15,
+			// interest accrues from the day after the purchase or a coupon, while windows of periods
+			// whose rates aren't known yet are left out
+			want: []calculator.CalendarEvent{
+				{Date: civil.New(2024, time.May, 15), Kind: calculator.CalendarFeeFreeEnd, Period: 0},
+				{Date: civil.New(2025, time.May, 15), Kind: calculator.CalendarCoupon, Period: 0},
+				{Date: civil.New(2025, time.May, 15), Kind: calculator.CalendarRateReset, Period: 1},
+				{Date: civil.New(2025, time.May, 15), Kind: calculator.CalendarFeeFreeEnd, Period: 1},
+				{Date: civil.New(2026, time.May, 15), Kind: calculator.CalendarCoupon, Period: 1},
+				{Date: civil.New(2026, time.May, 15), Kind: calculator.CalendarRateReset, Period: 2},
+				{Date: civil.New(2027, time.May, 15), Kind: calculator.CalendarCoupon, Period: 2},
+				{Date: civil.New(2027, time.May, 15), Kind: calculator.CalendarRateReset, Period: 3},
+				{Date: civil.New(2028, time.May, 14), Kind: calculator.CalendarExchangeDeadline, Period: 3},
+				{Date: civil.New(2028, time.May, 15), Kind: calculator.CalendarMaturity, Period: 3},
+			},
+		},
+		{
+			// COI0108 itself had no exchange price, but COI0112 on sale at its maturity had
+			name:        "exchange price of the issue on sale at maturity",
+			bondName:    "COI0108",
+			purchaseDay: 1,
+			want: []calculator.CalendarEvent{
+				{Date: civil.New(2004, time.January, 1), Kind: calculator.CalendarFeeFreeEnd, Period: 0},
+				{Date: civil.New(2005, time.January, 1), Kind: calculator.CalendarCoupon, Period: 0},
+				{Date: civil.New(2005, time.January, 1), Kind: calculator.CalendarRateReset, Period: 1},
+				{Date: civil.New(2005, time.January, 1), Kind: calculator.CalendarFeeFreeEnd, Period: 1},
+				{Date: civil.New(2006, time.January, 1), Kind: calculator.CalendarCoupon, Period: 1},
+				{Date: civil.New(2006, time.January, 1), Kind: calculator.CalendarRateReset, Period: 2},
+				{Date: civil.New(2006, time.January, 1), Kind: calculator.CalendarFeeFreeEnd, Period: 2},
+				{Date: civil.New(2007, time.January, 1), Kind: calculator.CalendarCoupon, Period: 2},
+				{Date: civil.New(2007, time.January, 1), Kind: calculator.CalendarRateReset, Period: 3},
+				{Date: civil.New(2007, time.January, 1), Kind: calculator.CalendarFeeFreeEnd, Period: 3},
+				{Date: civil.New(2007, time.December, 31), Kind: calculator.CalendarExchangeDeadline, Period: 3},
+				{Date: civil.New(2008, time.January, 1), Kind: calculator.CalendarMaturity, Period: 3},
+			},
+		},
+		{
+			name:        "no exchange into an issue without an exchange price",
+			bondName:    "COI0104",
+			purchaseDay: 1,
+			// capitalised interest keeps accruing, so the fee is charged after the purchase day until maturity
+			want: []calculator.CalendarEvent{
+				{Date: civil.New(2000, time.January, 1), Kind: calculator.CalendarFeeFreeEnd, Period: 0},
+				{Date: civil.New(2001, time.January, 1), Kind: calculator.CalendarRateReset, Period: 1},
+				{Date: civil.New(2002, time.January, 1), Kind: calculator.CalendarRateReset, Period: 2},
+				{Date: civil.New(2003, time.January, 1), Kind: calculator.CalendarRateReset, Period: 3},
+				{Date: civil.New(2004, time.January, 1), Kind: calculator.CalendarMaturity, Period: 3},
+			},
+		},
+		{
+			name:        "fixed rate capitalising bond",
+			bondName:    "TOS1125",
+			purchaseDay: 1,
+			want: []calculator.CalendarEvent{
+				{Date: civil.New(2022, time.November, 1), Kind: calculator.CalendarFeeFreeEnd, Period: 0},
+				{Date: civil.New(2025, time.October, 31), Kind: calculator.CalendarExchangeDeadline, Period: 2},
+				{Date: civil.New(2025, time.November, 1), Kind: calculator.CalendarMaturity, Period: 2},
+			},
+		},
+		{
+			// at 1% it takes two days for a grosz of interest to accrue
+			name:        "window without the fee longer than a day",
+			bondName:    "DOS0123",
+			purchaseDay: 1,
+			want: []calculator.CalendarEvent{
+				{Date: civil.New(2021, time.January, 2), Kind: calculator.CalendarFeeFreeEnd, Period: 0},
+				{Date: civil.New(2022, time.December, 31), Kind: calculator.CalendarExchangeDeadline, Period: 1},
+				{Date: civil.New(2023, time.January, 1), Kind: calculator.CalendarMaturity, Period: 1},
+			},
+		},
+		{
+			name:        "no early redemption",
+			bondName:    "OTS0825",
+			purchaseDay: 1,
+			want: []calculator.CalendarEvent{
+				{Date: civil.New(2025, time.July, 31), Kind: calculator.CalendarExchangeDeadline, Period: 0},
+				{Date: civil.New(2025, time.August, 1), Kind: calculator.CalendarMaturity, Period: 0},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bnd, err := repo.Lookup(tt.bondName)
+			if err != nil {
+				t.Fatalf("Lookup() error = %v", err)
+			}
+
+			got, err := c.Calendar(repo, bnd, tt.purchaseDay)
+			if err != nil {
+				t.Fatalf("Calendar() error = %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Calendar() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
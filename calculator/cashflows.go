@@ -0,0 +1,116 @@
+package calculator
+
+import (
+	"errors"
+
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/civil"
+)
+
+// CashFlow describes interest earned by a bond in a single interest period.
+type CashFlow struct {
+	// Index is the zero-based index of the interest period.
+	Index int
+	Start civil.Date
+	End   civil.Date
+	// Rate is the yearly interest rate of the period.
+	// It is zero if RateKnown is false.
+	Rate      bond.Percentage
+	RateKnown bool
+	// RateDerived reports whether the rate was derived from market indices
+	// because the issuer hasn't published it yet.
+	RateDerived bool
+	// Interest is the interest earned in the period, either paid out as a coupon or capitalised.
+	Interest bond.Price
+	// PaidOut reports whether Interest is paid out as a coupon at the end of the period.
+	PaidOut bool
+	// Payment is the cash paid to the holder at the end of the period,
+	// including the face value for the last period.
+	Payment bond.Price
+}
+
+// Schedule is the full cash flow schedule of a purchased bond.
+type Schedule struct {
+	PurchaseDate civil.Date
+	MaturityDate civil.Date
+	Periods      []CashFlow
+	// Redemption is the amount paid out at maturity.
+	// It is known only if rates for all interest periods are known.
+	Redemption      bond.Price
+	RedemptionKnown bool
+}
+
+// CashFlows returns the interest period schedule of a bond bought on purchaseDay of its sale month.
+// Amounts are derived from valuations at the end of each period, so they always add up to Valuate results.
+func (c *Calculator) CashFlows(bnd bond.Bond, purchaseDay int) (Schedule, error) {
+	strategy, err := StrategyFor(bnd)
+	if err != nil {
+		return Schedule{}, err
+	}
+
+	official := len(bnd.InterestPeriods)
+	bnd, err = c.deriveRates(bnd, purchaseDay, strategy)
+	if err != nil {
+		return Schedule{}, err
+	}
+
+	count := bnd.InterestPeriodCount()
+	schedule := Schedule{
+		Periods: make([]CashFlow, 0, count),
+	}
+
+	previous := Valuation{Price: bnd.FaceValue}
+	for i := range count {
+		start, end, err := bnd.Period(i, purchaseDay)
+		if err != nil {
+			return Schedule{}, err
+		}
+		if i == 0 {
+			schedule.PurchaseDate = start
+		}
+		schedule.MaturityDate = end
+
+		flow := CashFlow{
+			Index:   i,
+			Start:   start,
+			End:     end,
+			PaidOut: strategy.PaysCoupons(),
+		}
+		if i >= len(bnd.InterestPeriods) {
+			schedule.Periods = append(schedule.Periods, flow)
+			continue
+		}
+		flow.Rate = bnd.InterestPeriods[i]
+		flow.RateKnown = true
+		flow.RateDerived = i >= official
+
+		current, err := c.Valuate(bnd, purchaseDay, end)
+		if err != nil && !errors.Is(err, ErrValuationDateAfterMaturity) {
+			return Schedule{}, err
+		}
+
+		final := i == count-1
+		switch {
+		case final && strategy.PaysCoupons():
+			flow.Interest = bond.PriceOf(current.Price.Decimal() - bnd.FaceValue.Decimal())
+		case strategy.PaysCoupons():
+			flow.Interest = bond.PriceOf(current.PaidCoupons.Decimal() - previous.PaidCoupons.Decimal())
+		default:
+			flow.Interest = bond.PriceOf(current.Price.Decimal() - previous.Price.Decimal())
+		}
+
+		switch {
+		case final:
+			flow.Payment = current.Price
+			schedule.Redemption = current.Price
+			schedule.RedemptionKnown = true
+		case strategy.PaysCoupons():
+			flow.Payment = flow.Interest
+		}
+
+		schedule.Periods = append(schedule.Periods, flow)
+		previous = current
+	}
+
+	return schedule, nil
+}
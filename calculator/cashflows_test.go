@@ -0,0 +1,107 @@
+package calculator_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/calculator"
+	"github.com/maciekmm/obligacje/civil"
+)
+
+func TestCalculator_CashFlows(t *testing.T) {
+	type period struct {
+		start     civil.Date
+		interest  bond.Price
+		payment   bond.Price
+		rateKnown bool
+	}
+	tests := []struct {
+		name                string
+		bondName            string
+		purchaseDay         int
+		wantPaidOut         bool
+		wantPeriods         []period
+		wantRedemption      bond.Price
+		wantRedemptionKnown bool
+	}{
+		{
+			name:        "TOS capitalises interest yearly",
+			bondName:    "TOS0728",
+			purchaseDay: 15,
+			wantPeriods: []period{
+				{start: civil.New(2025, time.July, 15), interest: 5.65, rateKnown: true},
+				{start: civil.New(2026, time.July, 15), interest: 5.97, rateKnown: true},
+				{start: civil.New(2027, time.July, 15), interest: 6.31, payment: 117.93, rateKnown: true},
+			},
+			wantRedemption:      117.93,
+			wantRedemptionKnown: true,
+		},
+		{
+			name:        "COI pays coupons, future rates unknown",
+			bondName:    "COI0528",
+			purchaseDay: 15,
+			wantPaidOut: true,
+			wantPeriods: []period{
+				{start: civil.New(2024, time.May, 15), interest: 6.55, payment: 6.55, rateKnown: true},
+				{start: civil.New(2025, time.May, 15), interest: 6.15, payment: 6.15, rateKnown: true},
+				{start: civil.New(2026, time.May, 15)},
+				{start: civil.New(2027, time.May, 15)},
+			},
+		},
+		{
+			name:        "OTS single period",
+			bondName:    "OTS0825",
+			purchaseDay: 1,
+			wantPeriods: []period{
+				{start: civil.New(2025, time.May, 1), interest: 0.76, payment: 100.76, rateKnown: true},
+			},
+			wantRedemption:      100.76,
+			wantRedemptionKnown: true,
+		},
+	}
+
+	c := calculator.NewCalculator()
+	repo := LoadBondRepository()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bnd, err := repo.Lookup(tt.bondName)
+			if err != nil {
+				t.Fatalf("Lookup() error = %v", err)
+			}
+			got, err := c.CashFlows(bnd, tt.purchaseDay)
+			if err != nil {
+				t.Fatalf("CashFlows() error = %v", err)
+			}
+			if len(got.Periods) != len(tt.wantPeriods) {
+				t.Fatalf("CashFlows() got %d periods, want %d", len(got.Periods), len(tt.wantPeriods))
+			}
+			for i, want := range tt.wantPeriods {
+				p := got.Periods[i]
+				if p.Start != want.start {
+					t.Errorf("period %d start = %v, want %v", i, p.Start, want.start)
+				}
+				if p.RateKnown != want.rateKnown {
+					t.Errorf("period %d rate known = %v, want %v", i, p.RateKnown, want.rateKnown)
+				}
+				if p.PaidOut != tt.wantPaidOut {
+					t.Errorf("period %d paid out = %v, want %v", i, p.PaidOut, tt.wantPaidOut)
+				}
+				if math.Abs(float64(p.Interest-want.interest)) > 1e-9 {
+					t.Errorf("period %d interest = %v, want %v", i, p.Interest, want.interest)
+				}
+				if math.Abs(float64(p.Payment-want.payment)) > 1e-9 {
+					t.Errorf("period %d payment = %v, want %v", i, p.Payment, want.payment)
+				}
+			}
+			if got.RedemptionKnown != tt.wantRedemptionKnown {
+				t.Errorf("RedemptionKnown = %v, want %v", got.RedemptionKnown, tt.wantRedemptionKnown)
+			}
+			if math.Abs(float64(got.Redemption-tt.wantRedemption)) > 1e-9 {
+				t.Errorf("Redemption = %v, want %v", got.Redemption, tt.wantRedemption)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/civil"
+)
+
+// Comparison is the outcome of buying a single bond and holding it until a horizon,
+// used to compare issues of different series bought on the same day.
+type Comparison struct {
+	Bond        bond.Bond
+	PurchaseDay int
+	// Maturity is the maturity date of the bond.
+	Maturity civil.Date
+	// Horizon is the date the bond is valued at, capped at its maturity.
+	Horizon civil.Date
+	// Valuation is the value of the bond at the horizon.
+	Valuation Valuation
+	// Redemption is the payout if the bond is redeemed at the horizon,
+	// charged the early redemption fee if the horizon is before maturity.
+	Redemption Redemption
+	// Gross is the value of the bond at the horizon plus the coupons paid out until then.
+	Gross bond.Price
+	// NetCoupons is the sum of coupons paid out until the horizon after tax.
+	NetCoupons bond.Price
+	// Net is what the holder ends up with after redeeming the bond at the horizon,
+	// i.e. the payout plus the coupons after tax. Coupons are not reinvested.
+	Net bond.Price
+}
+
+// Compare values each of the candidates bought at purchasedAt and held until horizon.
+// Candidates must be on sale at purchasedAt, otherwise bond.ErrNotOnSale is returned.
+// Like Valuate, it returns ErrValuationDateAfterMaturity together with the comparisons
+// if rates of a candidate up to the horizon are not known yet.
+func (c *Calculator) Compare(candidates []bond.Bond, purchasedAt, horizon civil.Date, taxation Taxation) ([]Comparison, error) {
+	comparisons := make([]Comparison, 0, len(candidates))
+	var unknownRates error
+	for _, bnd := range candidates {
+		if purchasedAt.Before(bnd.SaleStart) || !purchasedAt.Before(bnd.SaleEnd.AddDate(0, 0, 1)) {
+			return nil, fmt.Errorf("%w: %s", bond.ErrNotOnSale, bnd.Name)
+		}
+		comparison, err := c.compare(bnd, purchasedAt.Day(), horizon, taxation)
+		if errors.Is(err, ErrValuationDateAfterMaturity) && unknownRates == nil {
+			unknownRates = fmt.Errorf("%w: %s", err, bnd.Name)
+		} else if err != nil && !errors.Is(err, ErrValuationDateAfterMaturity) {
+			return nil, err
+		}
+		comparisons = append(comparisons, comparison)
+	}
+	return comparisons, unknownRates
+}
+
+func (c *Calculator) compare(bnd bond.Bond, purchaseDay int, horizon civil.Date, taxation Taxation) (Comparison, error) {
+	_, maturity, err := bnd.Period(bnd.InterestPeriodCount()-1, purchaseDay)
+	if err != nil {
+		return Comparison{}, err
+	}
+	if horizon.After(maturity) {
+		// bonds don't earn interest after maturity
+		horizon = maturity
+	}
+
+	valuation, err := c.Valuate(bnd, purchaseDay, horizon)
+	if err != nil && !errors.Is(err, ErrValuationDateAfterMaturity) {
+		return Comparison{}, err
+	}
+	redemption, err := c.NetValue(bnd, purchaseDay, horizon, taxation)
+	if err != nil && !errors.Is(err, ErrValuationDateAfterMaturity) {
+		return Comparison{}, err
+	}
+
+	netCoupons := taxation.NetCoupons(valuation)
+	return Comparison{
+		Bond:        bnd,
+		PurchaseDay: purchaseDay,
+		Maturity:    maturity,
+		Horizon:     horizon,
+		Valuation:   valuation,
+		Redemption:  redemption,
+		Gross:       bond.PriceOf(valuation.Price.Decimal() + valuation.PaidCoupons.Decimal()),
+		NetCoupons:  netCoupons,
+		Net:         bond.PriceOf(redemption.Net.Decimal() + netCoupons.Decimal()),
+	}, err
+}
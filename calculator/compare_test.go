@@ -0,0 +1,144 @@
+package calculator_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/calculator"
+	"github.com/maciekmm/obligacje/civil"
+)
+
+func TestCalculator_Compare(t *testing.T) {
+	c := calculator.NewCalculator()
+	repo := LoadBondRepository()
+
+	var candidates []bond.Bond
+	for _, name := range []string{"COI1128", "DOR1126", "OTS0225"} {
+		bnd, err := repo.Lookup(name)
+		if err != nil {
+			t.Fatalf("Lookup(%s) error = %v", name, err)
+		}
+		candidates = append(candidates, bnd)
+	}
+
+	purchasedAt := civil.New(2024, time.November, 5)
+	horizon := civil.New(2025, time.August, 5)
+	got, err := c.Compare(candidates, purchasedAt, horizon, calculator.Taxable)
+	if err != nil {
+		t.Fatalf("Compare() error = %v", err)
+	}
+	if len(got) != len(candidates) {
+		t.Fatalf("Compare() returned %d comparisons, want %d", len(got), len(candidates))
+	}
+
+	tests := []struct {
+		name       string
+		horizon    civil.Date
+		early      bool
+		gross      bond.Price
+		fee        bond.Price
+		netCoupons bond.Price
+		net        bond.Price
+		comparison calculator.Comparison
+	}{
+		{
+			name:    "capitalising bond redeemed early",
+			horizon: horizon,
+			early:   true,
+			gross:   104.71,
+			fee:     0.70,
+			// 104.71 - 0.70 - 19% of 4.01
+			net:        103.25,
+			comparison: got[0],
+		},
+		{
+			name:    "coupon bond redeemed early on a coupon date",
+			horizon: horizon,
+			early:   true,
+			// 9 coupons, fee capped at the interest accrued in the current period
+			gross:      104.33,
+			netCoupons: 3.52,
+			net:        103.52,
+			comparison: got[1],
+		},
+		{
+			name:       "horizon past maturity",
+			horizon:    civil.New(2025, time.February, 5),
+			gross:      100.76,
+			net:        100.62,
+			comparison: got[2],
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.comparison
+			if got.PurchaseDay != 5 {
+				t.Errorf("Compare() PurchaseDay = %d, want 5", got.PurchaseDay)
+			}
+			if got.Horizon != tt.horizon {
+				t.Errorf("Compare() Horizon = %v, want %v", got.Horizon, tt.horizon)
+			}
+			if got.Redemption.Early != tt.early {
+				t.Errorf("Compare() Redemption.Early = %v, want %v", got.Redemption.Early, tt.early)
+			}
+			prices := []struct {
+				field string
+				got   bond.Price
+				want  bond.Price
+			}{
+				{field: "Gross", got: got.Gross, want: tt.gross},
+				{field: "Redemption.Fee", got: got.Redemption.Fee, want: tt.fee},
+				{field: "NetCoupons", got: got.NetCoupons, want: tt.netCoupons},
+				{field: "Net", got: got.Net, want: tt.net},
+			}
+			for _, p := range prices {
+				if math.Abs(float64(p.got-p.want)) > 1e-9 {
+					t.Errorf("Compare() %s = %v, want %v", p.field, p.got, p.want)
+				}
+			}
+		})
+	}
+}
+
+func TestCalculator_Compare_Errors(t *testing.T) {
+	c := calculator.NewCalculator()
+	repo := LoadBondRepository()
+
+	bnd, err := repo.Lookup("EDO1134")
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		purchasedAt civil.Date
+		horizon     civil.Date
+		wantErr     error
+	}{
+		{
+			name:        "not on sale",
+			purchasedAt: civil.New(2024, time.December, 5),
+			horizon:     civil.New(2025, time.December, 5),
+			wantErr:     bond.ErrNotOnSale,
+		},
+		{
+			name:        "rates unknown",
+			purchasedAt: civil.New(2024, time.November, 5),
+			horizon:     civil.New(2030, time.November, 5),
+			wantErr:     calculator.ErrValuationDateAfterMaturity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := c.Compare([]bond.Bond{bnd}, tt.purchasedAt, tt.horizon, calculator.Taxable)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Compare() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/civil"
+	"github.com/maciekmm/obligacje/decimal"
+)
+
+var (
+	ErrExchangeNotAvailable = errors.New("bond can't be exchanged into the target issue")
+	ErrInvalidQuantity      = fmt.Errorf("quantity must be between 1 and %d", MaxQuantity)
+)
+
+// Exchange is the result of rolling bonds over into a new issue at its exchange price (zamiana).
+// Every old bond is swapped for a new one bought at the exchange price, while the interest
+// and the difference between the face value and the exchange price are paid out in cash.
+type Exchange struct {
+	// Date is the maturity of the old bonds and the purchase date of the new ones.
+	Date civil.Date
+	// Redemption is the redemption of a single old bond at maturity.
+	Redemption Redemption
+	// InterestPayout is the interest of all old bonds after tax, paid out in cash.
+	InterestPayout bond.Price
+	// Quantity is the number of new bonds acquired, the same as the number of old bonds.
+	Quantity int
+	// CostBasis is the price paid per new bond, i.e. the exchange price of the new issue.
+	CostBasis bond.Price
+	// Leftover is the face value of the old bonds not spent on the new ones, e.g. 0.10 per bond, paid out in cash.
+	Leftover bond.Price
+	// Valuation is the value of a single new bond at the valuation date.
+	Valuation Valuation
+	// NetValue is the amount received for a single new bond if it was redeemed at the valuation date,
+	// with the tax calculated against the cost basis.
+	NetValue Redemption
+}
+
+// Exchange calculates exchanging quantity bonds bought on purchaseDay into the target issue at their maturity
+// and values the new bonds at valuatedAt. The target issue must be on sale at the maturity date
+// with an exchange price not above the face value of the old bonds.
+// Like Valuate, it returns ErrValuationDateAfterMaturity together with the result
+// if rates of the new bonds up to valuatedAt are not known yet.
+func (c *Calculator) Exchange(old bond.Bond, purchaseDay, quantity int, target bond.Bond, valuatedAt civil.Date, taxation Taxation) (Exchange, error) {
+	if quantity < 1 || quantity > MaxQuantity {
+		return Exchange{}, ErrInvalidQuantity
+	}
+
+	_, maturity, err := old.Period(old.InterestPeriodCount()-1, purchaseDay)
+	if err != nil {
+		return Exchange{}, err
+	}
+	if target.ExchangePrice <= 0 || target.ExchangePrice > old.FaceValue || maturity.Before(target.SaleStart) || !maturity.Before(target.SaleEnd.AddDate(0, 0, 1)) {
+		return Exchange{}, ErrExchangeNotAvailable
+	}
+
+	redemption, err := c.Redeem(old, purchaseDay, maturity, taxation)
+	if err != nil {
+		return Exchange{}, err
+	}
+
+	exchange := Exchange{
+		Date:           maturity,
+		Redemption:     redemption,
+		InterestPayout: bond.PriceOf((redemption.Net.Decimal() - old.FaceValue.Decimal()) * decimal.Decimal(quantity)),
+		Quantity:       quantity,
+		CostBasis:      target.ExchangePrice,
+		Leftover:       bond.PriceOf((old.FaceValue.Decimal() - target.ExchangePrice.Decimal()) * decimal.Decimal(quantity)),
+	}
+
+	newPurchaseDay := maturity.Day()
+	valuation, err := c.Valuate(target, newPurchaseDay, valuatedAt)
+	if err != nil && !errors.Is(err, ErrValuationDateAfterMaturity) {
+		return Exchange{}, err
+	}
+	exchange.Valuation = valuation
+
+	net, err := c.NetValueWithCostBasis(target, newPurchaseDay, valuatedAt, taxation, target.ExchangePrice)
+	if err != nil && !errors.Is(err, ErrValuationDateAfterMaturity) {
+		return Exchange{}, err
+	}
+	exchange.NetValue = net
+
+	return exchange, err
+}
@@ -0,0 +1,142 @@
+package calculator_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/calculator"
+	"github.com/maciekmm/obligacje/civil"
+)
+
+func TestCalculator_Exchange(t *testing.T) {
+	c := calculator.NewCalculator()
+	repo := LoadBondRepository()
+
+	old, err := repo.Lookup("TOS1125")
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	target, err := repo.Lookup("EDO1135")
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+
+	got, err := c.Exchange(old, 1, 10, target, civil.New(2026, time.November, 1), calculator.Taxable)
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+
+	if want := civil.New(2025, time.November, 1); got.Date != want {
+		t.Errorf("Exchange() Date = %v, want %v", got.Date, want)
+	}
+	if got.Quantity != 10 {
+		t.Errorf("Exchange() Quantity = %d, want 10", got.Quantity)
+	}
+
+	prices := []struct {
+		field string
+		got   bond.Price
+		want  bond.Price
+	}{
+		{field: "Redemption.Net", got: got.Redemption.Net, want: 117.81},
+		{field: "InterestPayout", got: got.InterestPayout, want: 178.10},
+		{field: "CostBasis", got: got.CostBasis, want: 99.90},
+		// 10 * 100.00 - 10 * 99.90
+		{field: "Leftover", got: got.Leftover, want: 1.00},
+		{field: "Valuation.Price", got: got.Valuation.Price, want: 105.75},
+		{field: "NetValue.Fee", got: got.NetValue.Fee, want: 2.00},
+		// 19% of 105.75 - 2.00 - 99.90
+		{field: "NetValue.Tax", got: got.NetValue.Tax, want: 0.73},
+		{field: "NetValue.Net", got: got.NetValue.Net, want: 103.02},
+	}
+	for _, p := range prices {
+		if math.Abs(float64(p.got-p.want)) > 1e-9 {
+			t.Errorf("Exchange() %s = %v, want %v", p.field, p.got, p.want)
+		}
+	}
+}
+
+func TestCalculator_Exchange_OneForOne(t *testing.T) {
+	c := calculator.NewCalculator()
+	repo := LoadBondRepository()
+
+	old, err := repo.Lookup("TOS1125")
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	target, err := repo.Lookup("EDO1135")
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+
+	// 100000.00 of face value would buy 1001 bonds at 99.90, but bonds are swapped one for one
+	got, err := c.Exchange(old, 1, 1000, target, civil.New(2026, time.November, 1), calculator.Taxable)
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+	if got.Quantity != 1000 {
+		t.Errorf("Exchange() Quantity = %d, want 1000", got.Quantity)
+	}
+	// 1000 * (100.00 - 99.90)
+	if math.Abs(float64(got.Leftover)-100.00) > 1e-9 {
+		t.Errorf("Exchange() Leftover = %v, want 100.00", got.Leftover)
+	}
+}
+
+func TestCalculator_Exchange_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		bondName   string
+		quantity   int
+		targetName string
+		valuatedAt civil.Date
+		wantErr    error
+	}{
+		{
+			name:       "target not on sale at maturity",
+			bondName:   "EDO0834",
+			quantity:   1,
+			targetName: "EDO1135",
+			valuatedAt: civil.New(2035, time.November, 1),
+			wantErr:    calculator.ErrExchangeNotAvailable,
+		},
+		{
+			name:       "no bonds",
+			bondName:   "TOS1125",
+			targetName: "EDO1135",
+			valuatedAt: civil.New(2026, time.November, 1),
+			wantErr:    calculator.ErrInvalidQuantity,
+		},
+		{
+			name:       "valuated before the exchange",
+			bondName:   "TOS1125",
+			quantity:   1,
+			targetName: "EDO1135",
+			valuatedAt: civil.New(2025, time.October, 1),
+			wantErr:    calculator.ErrValuationDateBeforePurchaseDate,
+		},
+	}
+
+	c := calculator.NewCalculator()
+	repo := LoadBondRepository()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			old, err := repo.Lookup(tt.bondName)
+			if err != nil {
+				t.Fatalf("Lookup() error = %v", err)
+			}
+			target, err := repo.Lookup(tt.targetName)
+			if err != nil {
+				t.Fatalf("Lookup() error = %v", err)
+			}
+			_, err = c.Exchange(old, 1, tt.quantity, target, tt.valuatedAt, calculator.Taxable)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Exchange() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
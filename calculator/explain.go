@@ -0,0 +1,60 @@
+package calculator
+
+import (
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/civil"
+	"github.com/maciekmm/obligacje/decimal"
+)
+
+// InterestTreatment describes what happens to the interest of a period.
+type InterestTreatment string
+
+const (
+	// InterestCapitalised interest is added to the value the interest of the following periods is calculated on.
+	InterestCapitalised InterestTreatment = "capitalised"
+	// InterestPaidOut interest is paid out as a coupon at the end of the period.
+	InterestPaidOut InterestTreatment = "paid_out"
+	// InterestAccrued interest is accrued in the current period and included in the value of the bond.
+	InterestAccrued InterestTreatment = "accrued"
+)
+
+// Explanation is a step-by-step breakdown of a valuation.
+type Explanation struct {
+	Valuation Valuation
+	// Periods are the interest periods walked until the valuation date, oldest first.
+	Periods []PeriodExplanation
+	// Unrounded is the value of the bond before it's rounded to the grosz.
+	Unrounded decimal.Decimal
+}
+
+// PeriodExplanation is the calculation of the interest of a single interest period.
+type PeriodExplanation struct {
+	// Index is the zero-based index of the interest period.
+	Index int
+	Start civil.Date
+	End   civil.Date
+	// Rate is the yearly interest rate applied in the period.
+	Rate        bond.Percentage
+	RateDerived bool
+	// HeldDays is the number of days of the period the bond was held for until the valuation date.
+	HeldDays   int
+	PeriodDays int
+	// Principal is the value of the bond the interest is calculated on.
+	Principal decimal.Decimal
+	// Unrounded is the interest before rounding.
+	Unrounded decimal.Decimal
+	// Interest is the interest after rounding. Capitalised interest isn't rounded, coupons are rounded to the grosz.
+	Interest  decimal.Decimal
+	Treatment InterestTreatment
+	// Price is the value of the bond at the end of the period or the valuation date, whichever is earlier,
+	// excluding paid out coupons and before rounding.
+	Price decimal.Decimal
+}
+
+// Explain calculates the same valuation as Valuate and reports how it was arrived at.
+func (c *Calculator) Explain(bnd bond.Bond, purchaseDay int, valuatedAt civil.Date) (Explanation, error) {
+	var explanation Explanation
+	valuation, err := c.valuate(bnd, purchaseDay, valuatedAt, &explanation)
+	explanation.Valuation = valuation
+	return explanation, err
+}
@@ -0,0 +1,106 @@
+package calculator_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/maciekmm/obligacje/calculator"
+	"github.com/maciekmm/obligacje/civil"
+	"github.com/maciekmm/obligacje/decimal"
+)
+
+func TestCalculator_Explain(t *testing.T) {
+	tests := []struct {
+		name          string
+		bondName      string
+		valuatedAt    civil.Date
+		wantPeriods   []calculator.PeriodExplanation
+		wantUnrounded decimal.Decimal
+	}{
+		{
+			name:       "coupon paid out and the next one accrued",
+			bondName:   "COI0528",
+			valuatedAt: civil.New(2025, time.November, 1),
+			wantPeriods: []calculator.PeriodExplanation{
+				{
+					Index: 0, Rate: 0.0655, HeldDays: 365, PeriodDays: 365,
+					Principal: decimal.FromInt(100), Unrounded: decimal.FromFloat(6.55), Interest: decimal.FromFloat(6.55),
+					Treatment: calculator.InterestPaidOut, Price: decimal.FromInt(100),
+				},
+				{
+					Index: 1, Rate: 0.0615, HeldDays: 184, PeriodDays: 365,
+					Principal: decimal.FromInt(100), Unrounded: decimal.FromFloat(3.10027397), Interest: decimal.FromFloat(3.10),
+					Treatment: calculator.InterestAccrued, Price: decimal.FromFloat(103.10),
+				},
+			},
+			wantUnrounded: decimal.FromFloat(103.10),
+		},
+		{
+			name:       "capitalised interest isn't rounded",
+			bondName:   "EDO0834",
+			valuatedAt: civil.New(2025, time.August, 15),
+			wantPeriods: []calculator.PeriodExplanation{
+				{
+					Index: 0, Rate: 0.068, HeldDays: 365, PeriodDays: 365,
+					Principal: decimal.FromInt(100), Unrounded: decimal.FromFloat(6.8), Interest: decimal.FromFloat(6.8),
+					Treatment: calculator.InterestCapitalised, Price: decimal.FromFloat(106.8),
+				},
+				{
+					Index: 1, Rate: 0.061, HeldDays: 14, PeriodDays: 365,
+					Principal: decimal.FromFloat(106.8), Unrounded: decimal.FromFloat(0.24988274), Interest: decimal.FromFloat(0.24988274),
+					Treatment: calculator.InterestCapitalised, Price: decimal.FromFloat(107.04988274),
+				},
+			},
+			wantUnrounded: decimal.FromFloat(107.04988274),
+		},
+	}
+
+	c := calculator.NewCalculator()
+	repo := LoadBondRepository()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bnd, err := repo.Lookup(tt.bondName)
+			if err != nil {
+				t.Fatalf("Lookup() error = %v", err)
+			}
+			got, err := c.Explain(bnd, 1, tt.valuatedAt)
+			if err != nil {
+				t.Fatalf("Explain() error = %v", err)
+			}
+
+			valuation, err := c.Valuate(bnd, 1, tt.valuatedAt)
+			if err != nil {
+				t.Fatalf("Valuate() error = %v", err)
+			}
+			if got.Valuation.Price != valuation.Price || got.Valuation.PaidCoupons != valuation.PaidCoupons {
+				t.Errorf("Explain() Valuation = %+v, want %+v", got.Valuation, valuation)
+			}
+			if got.Unrounded != tt.wantUnrounded {
+				t.Errorf("Explain() Unrounded = %v, want %v", got.Unrounded, tt.wantUnrounded)
+			}
+
+			if len(got.Periods) != len(tt.wantPeriods) {
+				t.Fatalf("Explain() got %d periods, want %d", len(got.Periods), len(tt.wantPeriods))
+			}
+			for i, want := range tt.wantPeriods {
+				period := got.Periods[i]
+				start, end, err := bnd.Period(i, 1)
+				if err != nil {
+					t.Fatalf("Period() error = %v", err)
+				}
+				want.Start, want.End = start, end
+				if period.Start != want.Start || period.End != want.End {
+					t.Errorf("period %d: got %v - %v, want %v - %v", i, period.Start, period.End, want.Start, want.End)
+				}
+				if period.Rate.Decimal() != want.Rate.Decimal() {
+					t.Errorf("period %d: got rate %v, want %v", i, period.Rate, want.Rate)
+				}
+				period.Start, period.End, period.Rate = want.Start, want.End, want.Rate
+				if period != want {
+					t.Errorf("period %d: got %+v, want %+v", i, period, want)
+				}
+			}
+		})
+	}
+}
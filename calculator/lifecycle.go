@@ -0,0 +1,90 @@
+package calculator
+
+import (
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/civil"
+)
+
+// Status is the stage of life of a purchased bond.
+type Status string
+
+const (
+	// StatusOnSale bonds are held while bonds of the same series are still being sold.
+	StatusOnSale Status = "on_sale"
+	// StatusActive bonds are held after the sale of the series ended and earn interest until maturity.
+	StatusActive Status = "active"
+	// StatusMatured bonds have been redeemed at maturity and no longer earn interest.
+	StatusMatured Status = "matured"
+)
+
+// Lifecycle describes where a purchased bond is in its life at a given date and what happens to it next.
+type Lifecycle struct {
+	Status Status
+	// Period is the zero-based index of the interest period the date falls in, -1 once the bond matured.
+	Period int
+	// NextRateReset is the start of the next period with a new interest rate.
+	// It is zero if the rate doesn't change until maturity.
+	NextRateReset civil.Date
+	// NextInterest is the end of the current period, when its interest is paid out or capitalised.
+	// It is zero once the bond matured.
+	NextInterest          civil.Date
+	NextInterestTreatment InterestTreatment
+	Maturity              civil.Date
+}
+
+// Lifecycle returns the stage of life of a bond bought on purchaseDay at the given date.
+// It returns ErrValuationDateBeforePurchaseDate if the bond isn't bought yet.
+func (c *Calculator) Lifecycle(bnd bond.Bond, purchaseDay int, at civil.Date) (Lifecycle, error) {
+	purchaseDate := civil.New(bnd.SaleStart.Year(), bnd.SaleStart.Month(), purchaseDay)
+	if at.Before(purchaseDate) {
+		return Lifecycle{}, ErrValuationDateBeforePurchaseDate
+	}
+
+	strategy, err := StrategyFor(bnd)
+	if err != nil {
+		return Lifecycle{}, err
+	}
+
+	count := bnd.InterestPeriodCount()
+	_, maturity, err := bnd.Period(count-1, purchaseDay)
+	if err != nil {
+		return Lifecycle{}, err
+	}
+
+	lifecycle := Lifecycle{
+		Status:   StatusActive,
+		Period:   -1,
+		Maturity: maturity,
+	}
+	if !at.Before(maturity) {
+		lifecycle.Status = StatusMatured
+		return lifecycle, nil
+	}
+	if !at.After(bnd.SaleEnd) {
+		lifecycle.Status = StatusOnSale
+	}
+
+	for i := range count {
+		start, end, err := bnd.Period(i, purchaseDay)
+		if err != nil {
+			return Lifecycle{}, err
+		}
+		if at.Before(start) || !at.Before(end) {
+			continue
+		}
+
+		lifecycle.Period = i
+		lifecycle.NextInterest = end
+		lifecycle.NextInterestTreatment = InterestCapitalised
+		if strategy.PaysCoupons() || i == count-1 {
+			// capitalised interest is paid out together with the face value at maturity
+			lifecycle.NextInterestTreatment = InterestPaidOut
+		}
+		if !strategy.FixedRate() && i < count-1 {
+			lifecycle.NextRateReset = end
+		}
+		break
+	}
+
+	return lifecycle, nil
+}
@@ -0,0 +1,119 @@
+package calculator_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/maciekmm/obligacje/calculator"
+	"github.com/maciekmm/obligacje/civil"
+)
+
+func TestCalculator_Lifecycle(t *testing.T) {
+	c := calculator.NewCalculator()
+	repo := LoadBondRepository()
+
+	tests := []struct {
+		name        string
+		bondName    string
+		purchaseDay int
+		at          civil.Date
+		want        calculator.Lifecycle
+	}{
+		{
+			name:        "series still on sale",
+			bondName:    "EDO0834",
+			purchaseDay: 1,
+			at:          civil.New(2024, time.August, 20),
+			want: calculator.Lifecycle{
+				Status:                calculator.StatusOnSale,
+				Period:                0,
+				NextRateReset:         civil.New(2025, time.August, 1),
+				NextInterest:          civil.New(2025, time.August, 1),
+				NextInterestTreatment: calculator.InterestCapitalised,
+				Maturity:              civil.New(2034, time.August, 1),
+			},
+		},
+		{
+			name:        "coupon paying bond",
+			bondName:    "COI0528",
+			purchaseDay: 1,
+			at:          civil.New(2025, time.November, 1),
+			want: calculator.Lifecycle{
+				Status:                calculator.StatusActive,
+				Period:                1,
+				NextRateReset:         civil.New(2026, time.May, 1),
+				NextInterest:          civil.New(2026, time.May, 1),
+				NextInterestTreatment: calculator.InterestPaidOut,
+				Maturity:              civil.New(2028, time.May, 1),
+			},
+		},
+		{
+			name:        "fixed rate bond",
+			bondName:    "TOS1125",
+			purchaseDay: 1,
+			at:          civil.New(2024, time.May, 1),
+			want: calculator.Lifecycle{
+				Status:                calculator.StatusActive,
+				Period:                1,
+				NextInterest:          civil.New(2024, time.November, 1),
+				NextInterestTreatment: calculator.InterestCapitalised,
+				Maturity:              civil.New(2025, time.November, 1),
+			},
+		},
+		{
+			name:        "last period of a capitalising bond",
+			bondName:    "OTS0225",
+			purchaseDay: 5,
+			at:          civil.New(2025, time.February, 4),
+			want: calculator.Lifecycle{
+				Status:                calculator.StatusActive,
+				Period:                0,
+				NextInterest:          civil.New(2025, time.February, 5),
+				NextInterestTreatment: calculator.InterestPaidOut,
+				Maturity:              civil.New(2025, time.February, 5),
+			},
+		},
+		{
+			name:        "matured on maturity date",
+			bondName:    "OTS0225",
+			purchaseDay: 5,
+			at:          civil.New(2025, time.February, 5),
+			want: calculator.Lifecycle{
+				Status:   calculator.StatusMatured,
+				Period:   -1,
+				Maturity: civil.New(2025, time.February, 5),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bnd, err := repo.Lookup(tt.bondName)
+			if err != nil {
+				t.Fatalf("Lookup(%s) error = %v", tt.bondName, err)
+			}
+
+			got, err := c.Lifecycle(bnd, tt.purchaseDay, tt.at)
+			if err != nil {
+				t.Fatalf("Lifecycle() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Lifecycle() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculator_Lifecycle_BeforePurchase(t *testing.T) {
+	c := calculator.NewCalculator()
+	bnd, err := LoadBondRepository().Lookup("EDO0834")
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+
+	_, err = c.Lifecycle(bnd, 15, civil.New(2024, time.August, 14))
+	if !errors.Is(err, calculator.ErrValuationDateBeforePurchaseDate) {
+		t.Errorf("Lifecycle() error = %v, want %v", err, calculator.ErrValuationDateBeforePurchaseDate)
+	}
+}
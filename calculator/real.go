@@ -0,0 +1,94 @@
+package calculator
+
+import (
+	"errors"
+
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/civil"
+	"github.com/maciekmm/obligacje/decimal"
+	"github.com/maciekmm/obligacje/index"
+)
+
+var (
+	ErrInflationUnknown = errors.New("inflation since purchase is unknown")
+)
+
+// maxCPILag is how many months before the valuation date the latest CPI reading may be.
+// GUS publishes the CPI for a month in the middle of the next one.
+const maxCPILag = 3
+
+// RealValue is the value of a bond in PLN of its purchase date, i.e. deflated by the inflation since.
+type RealValue struct {
+	// PricesAt is the first day of the month of the latest CPI reading up to the valuation date,
+	// which the inflation is measured until.
+	PricesAt civil.Date
+	// Inflation is the cumulative inflation from the month of purchase until PricesAt,
+	// zero if no reading after the month of purchase is known yet.
+	Inflation bond.Percentage
+	// Value is the price of the bond plus the coupons paid out so far, deflated.
+	// Coupons are assumed to be kept in cash rather than reinvested.
+	Value bond.Price
+	// Net is the amount the holder would receive if the bond was redeemed at the valuation date
+	// plus the coupons after tax, deflated.
+	Net bond.Price
+	// Preserved reports whether Net is at least the face value paid for the bond,
+	// i.e. whether the bond preserved the purchasing power of the money invested.
+	Preserved bool
+}
+
+// RealValue calculates the value of a bond bought on purchaseDay at valuatedAt in PLN of the purchase date,
+// using the inflation index of the calculator as the year-on-year CPI. Inflation is measured between the months
+// of purchase and of the latest CPI reading up to valuatedAt, see index.Inflation.
+// It returns ErrInflationUnknown if the CPI isn't known for the period.
+// Like Valuate, it returns ErrValuationDateAfterMaturity together with the value
+// if rates up to valuatedAt aren't known yet.
+func (c *Calculator) RealValue(bnd bond.Bond, purchaseDay int, valuatedAt civil.Date, taxation Taxation) (RealValue, error) {
+	if c.indices.Inflation == nil {
+		return RealValue{}, ErrInflationUnknown
+	}
+	purchaseDate := civil.New(bnd.SaleStart.Year(), bnd.SaleStart.Month(), purchaseDay)
+
+	pricesAt, ok := latestReading(c.indices.Inflation, valuatedAt)
+	if !ok {
+		return RealValue{}, ErrInflationUnknown
+	}
+	var inflation bond.Percentage
+	if pricesAt.After(purchaseDate) {
+		if inflation, ok = index.Inflation(c.indices.Inflation, purchaseDate, pricesAt); !ok {
+			return RealValue{}, ErrInflationUnknown
+		}
+	}
+
+	valuation, valuationErr := c.Valuate(bnd, purchaseDay, valuatedAt)
+	if valuationErr != nil && !errors.Is(valuationErr, ErrValuationDateAfterMaturity) {
+		return RealValue{}, valuationErr
+	}
+	redemption, err := c.NetValue(bnd, purchaseDay, valuatedAt, taxation)
+	if err != nil && !errors.Is(err, ErrValuationDateAfterMaturity) {
+		return RealValue{}, err
+	}
+
+	deflate := func(amount decimal.Decimal) bond.Price {
+		return bond.PriceOf(decimal.FromFloat(amount.Float64() / (1 + float64(inflation))).Round(2))
+	}
+	net := deflate(redemption.Net.Decimal() + taxation.NetCoupons(valuation).Decimal())
+	return RealValue{
+		PricesAt:  pricesAt,
+		Inflation: inflation,
+		Value:     deflate(valuation.Price.Decimal() + valuation.PaidCoupons.Decimal()),
+		Net:       net,
+		Preserved: net >= bnd.FaceValue,
+	}, valuationErr
+}
+
+// latestReading returns the first day of the latest month up to at the CPI is known for.
+func latestReading(cpi index.Index, at civil.Date) (civil.Date, bool) {
+	month := civil.New(at.Year(), at.Month(), 1)
+	for range maxCPILag + 1 {
+		if _, ok := cpi.At(month); ok {
+			return month, true
+		}
+		month = month.AddDate(0, -1, 0)
+	}
+	return civil.Date{}, false
+}
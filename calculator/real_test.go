@@ -0,0 +1,141 @@
+package calculator_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/calculator"
+	"github.com/maciekmm/obligacje/civil"
+)
+
+func TestCalculator_RealValue(t *testing.T) {
+	c := calculator.NewCalculator(calculator.WithInflation(LoadCPI()))
+	repo := LoadBondRepository()
+
+	tests := []struct {
+		name          string
+		bondName      string
+		purchaseDay   int
+		valuatedAt    civil.Date
+		wantPricesAt  civil.Date
+		wantInflation float64
+		wantValue     bond.Price
+		wantNet       bond.Price
+		wantPreserved bool
+	}{
+		{
+			name:         "EDO after a year",
+			bondName:     "EDO0834",
+			purchaseDay:  1,
+			valuatedAt:   civil.New(2025, time.August, 15),
+			wantPricesAt: civil.New(2025, time.August, 1),
+			// CPI for August 2025
+			wantInflation: 0.029,
+			// 107.05 / 1.029
+			wantValue: 104.03,
+			// (107.05 - 2.00 fee - 19% of 5.05) / 1.029
+			wantNet:       101.16,
+			wantPreserved: true,
+		},
+		{
+			name:         "EDO with the CPI for the valuation month not published yet",
+			bondName:     "EDO0834",
+			purchaseDay:  1,
+			valuatedAt:   civil.New(2025, time.December, 15),
+			wantPricesAt: civil.New(2025, time.October, 1),
+			// the CPI for October 2025 and two months at the CPI for October 2024
+			wantInflation: 1.028*math.Pow(1.05, 2.0/12) - 1,
+			wantValue:     105.39,
+			wantNet:       102.14,
+			wantPreserved: true,
+		},
+		{
+			name:          "no inflation measured yet",
+			bondName:      "EDO0834",
+			purchaseDay:   1,
+			valuatedAt:    civil.New(2024, time.August, 20),
+			wantPricesAt:  civil.New(2024, time.August, 1),
+			wantValue:     100.35,
+			wantNet:       100.00,
+			wantPreserved: true,
+		},
+		{
+			name:          "COI including coupons",
+			bondName:      "COI0528",
+			purchaseDay:   1,
+			valuatedAt:    civil.New(2025, time.November, 1),
+			wantPricesAt:  civil.New(2025, time.October, 1),
+			wantInflation: 1.028*math.Pow(1.05, 5.0/12) - 1,
+			// (103.10 + 6.55) / 1.0491
+			wantValue:     104.52,
+			wantNet:       102.23,
+			wantPreserved: true,
+		},
+		{
+			name:          "OTS lost to inflation",
+			bondName:      "OTS0225",
+			purchaseDay:   5,
+			valuatedAt:    civil.New(2025, time.February, 5),
+			wantPricesAt:  civil.New(2025, time.February, 1),
+			wantInflation: math.Pow(1.049, 3.0/12) - 1,
+			wantValue:     99.56,
+			wantNet:       99.42,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bnd, err := repo.Lookup(tt.bondName)
+			if err != nil {
+				t.Fatalf("Lookup() error = %v", err)
+			}
+
+			got, err := c.RealValue(bnd, tt.purchaseDay, tt.valuatedAt, calculator.Taxable)
+			if err != nil {
+				t.Fatalf("RealValue() error = %v", err)
+			}
+			if got.PricesAt != tt.wantPricesAt {
+				t.Errorf("RealValue() PricesAt = %v, want %v", got.PricesAt, tt.wantPricesAt)
+			}
+			if math.Abs(float64(got.Inflation)-tt.wantInflation) > 1e-9 {
+				t.Errorf("RealValue() Inflation = %v, want %v", got.Inflation, tt.wantInflation)
+			}
+			if math.Abs(float64(got.Value-tt.wantValue)) > 1e-9 {
+				t.Errorf("RealValue() Value = %v, want %v", got.Value, tt.wantValue)
+			}
+			if math.Abs(float64(got.Net-tt.wantNet)) > 1e-9 {
+				t.Errorf("RealValue() Net = %v, want %v", got.Net, tt.wantNet)
+			}
+			if got.Preserved != tt.wantPreserved {
+				t.Errorf("RealValue() Preserved = %v, want %v", got.Preserved, tt.wantPreserved)
+			}
+		})
+	}
+}
+
+func TestCalculator_RealValue_InflationUnknown(t *testing.T) {
+	bnd, err := LoadBondRepository().Lookup("EDO0834")
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		calc       *calculator.Calculator
+		valuatedAt civil.Date
+	}{
+		{name: "no CPI", calc: calculator.NewCalculator(), valuatedAt: civil.New(2025, time.August, 1)},
+		{name: "CPI not published for months", calc: calculator.NewCalculator(calculator.WithInflation(LoadCPI())), valuatedAt: civil.New(2026, time.June, 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.calc.RealValue(bnd, 1, tt.valuatedAt, calculator.Taxable); !errors.Is(err, calculator.ErrInflationUnknown) {
+				t.Errorf("RealValue() error = %v, want %v", err, calculator.ErrInflationUnknown)
+			}
+		})
+	}
+}
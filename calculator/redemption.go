@@ -0,0 +1,129 @@
+package calculator
+
+import (
+	"errors"
+
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/civil"
+)
+
+var (
+	ErrEarlyRedemptionNotAllowed = errors.New("bond can't be redeemed before maturity")
+)
+
+// Redemption is the amount paid out when a bond is redeemed.
+type Redemption struct {
+	// Gross is the value of the bond at the redemption date.
+	Gross bond.Price
+	// Fee is the early redemption fee, zero when redeemed at maturity.
+	Fee bond.Price
+	// Tax is the capital gains tax withheld from the interest reduced by the fee.
+	Tax bond.Price
+	// Net is the amount paid out to the holder.
+	Net bond.Price
+	// Early reports whether the bond is redeemed before maturity.
+	Early bool
+}
+
+// Times returns the redemption of quantity bonds. Like Valuation.Times,
+// the fee and the tax are calculated per bond and then multiplied.
+// It returns decimal.ErrOverflow if the amounts are too large.
+func (r Redemption) Times(quantity int) (Redemption, error) {
+	total := Redemption{Early: r.Early}
+	var err error
+	if total.Gross, err = r.Gross.Times(quantity); err != nil {
+		return Redemption{}, err
+	}
+	if total.Fee, err = r.Fee.Times(quantity); err != nil {
+		return Redemption{}, err
+	}
+	if total.Tax, err = r.Tax.Times(quantity); err != nil {
+		return Redemption{}, err
+	}
+	if total.Net, err = r.Net.Times(quantity); err != nil {
+		return Redemption{}, err
+	}
+	return total, nil
+}
+
+// Redeem calculates the payout of a bond bought on purchaseDay and redeemed at redeemedAt.
+// Bonds redeemed before maturity are charged the early redemption fee of their issue,
+// capped at the interest accrued in the current period (or since purchase for capitalising bonds).
+// The tax is withheld from interest not paid out yet according to taxation, after deducting the fee.
+// Like Valuate, it returns ErrValuationDateAfterMaturity together with the redemption
+// based on the last known interest period if later rates are not known yet.
+func (c *Calculator) Redeem(bnd bond.Bond, purchaseDay int, redeemedAt civil.Date, taxation Taxation) (Redemption, error) {
+	return c.RedeemWithCostBasis(bnd, purchaseDay, redeemedAt, taxation, bnd.FaceValue)
+}
+
+// RedeemWithCostBasis is Redeem for bonds acquired at costBasis rather than their face value,
+// e.g. at the exchange price when rolled over from an earlier issue. The tax is calculated against costBasis.
+func (c *Calculator) RedeemWithCostBasis(bnd bond.Bond, purchaseDay int, redeemedAt civil.Date, taxation Taxation, costBasis bond.Price) (Redemption, error) {
+	_, maturity, err := bnd.Period(bnd.InterestPeriodCount()-1, purchaseDay)
+	if err != nil {
+		return Redemption{}, err
+	}
+	early := redeemedAt.Before(maturity)
+	if early && !bnd.EarlyRedeemable {
+		return Redemption{}, ErrEarlyRedemptionNotAllowed
+	}
+
+	strategy, err := StrategyFor(bnd)
+	if err != nil {
+		return Redemption{}, err
+	}
+	bnd, err = c.deriveRates(bnd, purchaseDay, strategy)
+	if err != nil {
+		return Redemption{}, err
+	}
+
+	valuation, err := c.Valuate(bnd, purchaseDay, redeemedAt)
+	if errors.Is(err, ErrValuationDateAfterMaturity) && !early && len(bnd.InterestPeriods) == bnd.InterestPeriodCount() {
+		// bonds are redeemed at maturity and do not earn interest afterwards
+		err = nil
+	}
+	if err != nil && !errors.Is(err, ErrValuationDateAfterMaturity) {
+		return Redemption{}, err
+	}
+
+	fee := bond.Price(0)
+	if early {
+		accrued := max(valuation.Price.Decimal()-bnd.FaceValue.Decimal(), 0)
+		fee = bond.PriceOf(min(bnd.EarlyRedemptionFee.Decimal(), accrued))
+	}
+	// ErrValuationDateAfterMaturity is passed through when rates past the last known period are missing
+	return settle(valuation, costBasis, fee, early, taxation), err
+}
+
+// NetValue calculates what the holder receives for a bond bought on purchaseDay if it was redeemed at valuatedAt.
+// It matches Redeem, except that bonds which can't be redeemed early are settled without a fee,
+// as if the interest accrued so far was paid out.
+func (c *Calculator) NetValue(bnd bond.Bond, purchaseDay int, valuatedAt civil.Date, taxation Taxation) (Redemption, error) {
+	return c.NetValueWithCostBasis(bnd, purchaseDay, valuatedAt, taxation, bnd.FaceValue)
+}
+
+// NetValueWithCostBasis is NetValue for bonds acquired at costBasis, see RedeemWithCostBasis.
+func (c *Calculator) NetValueWithCostBasis(bnd bond.Bond, purchaseDay int, valuatedAt civil.Date, taxation Taxation, costBasis bond.Price) (Redemption, error) {
+	redemption, err := c.RedeemWithCostBasis(bnd, purchaseDay, valuatedAt, taxation, costBasis)
+	if !errors.Is(err, ErrEarlyRedemptionNotAllowed) {
+		return redemption, err
+	}
+
+	valuation, err := c.Valuate(bnd, purchaseDay, valuatedAt)
+	if err != nil && !errors.Is(err, ErrValuationDateAfterMaturity) {
+		return Redemption{}, err
+	}
+	return settle(valuation, costBasis, 0, true, taxation), err
+}
+
+// settle calculates the payout of a bond acquired at costBasis, usually its face value.
+func settle(valuation Valuation, costBasis, fee bond.Price, early bool, taxation Taxation) Redemption {
+	tax := taxation.Tax(bond.PriceOf(valuation.Price.Decimal() - costBasis.Decimal() - fee.Decimal()))
+	return Redemption{
+		Gross: valuation.Price,
+		Fee:   fee,
+		Tax:   tax,
+		Net:   bond.PriceOf(valuation.Price.Decimal() - fee.Decimal() - tax.Decimal()),
+		Early: early,
+	}
+}
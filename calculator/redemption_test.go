@@ -0,0 +1,237 @@
+package calculator_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/calculator"
+	"github.com/maciekmm/obligacje/civil"
+)
+
+func TestCalculator_Redeem(t *testing.T) {
+	tests := []struct {
+		name        string
+		bondName    string
+		purchaseDay int
+		redeemedAt  civil.Date
+		taxation    calculator.Taxation
+		wantGross   bond.Price
+		wantFee     bond.Price
+		wantTax     bond.Price
+		wantNet     bond.Price
+		wantEarly   bool
+		wantErr     error
+	}{
+		{
+			name:        "EDO redeemed early pays the full fee",
+			bondName:    "EDO0834",
+			purchaseDay: 1,
+			redeemedAt:  civil.New(2025, time.August, 1),
+			wantGross:   106.80,
+			wantFee:     2.00,
+			wantTax:     0.91,
+			wantNet:     103.89,
+			wantEarly:   true,
+		},
+		{
+			name:        "EDO held in IKE is not taxed",
+			bondName:    "EDO0834",
+			purchaseDay: 1,
+			redeemedAt:  civil.New(2025, time.August, 1),
+			taxation:    calculator.TaxExempt,
+			wantGross:   106.80,
+			wantFee:     2.00,
+			wantNet:     104.80,
+			wantEarly:   true,
+		},
+		{
+			name:        "EDO redeemed shortly after purchase, fee capped at accrued interest",
+			bondName:    "EDO0935",
+			purchaseDay: 2,
+			redeemedAt:  civil.New(2025, time.September, 5),
+			wantGross:   100.05,
+			wantFee:     0.05,
+			wantNet:     100.00,
+			wantEarly:   true,
+		},
+		{
+			name:        "COI fee is capped at the current period interest",
+			bondName:    "COI0528",
+			purchaseDay: 1,
+			redeemedAt:  civil.New(2025, time.November, 1),
+			wantGross:   103.10,
+			wantFee:     0.70,
+			wantTax:     0.46,
+			wantNet:     101.94,
+			wantEarly:   true,
+		},
+		{
+			name:        "TOS redeemed after maturity is free",
+			bondName:    "TOS1125",
+			purchaseDay: 1,
+			redeemedAt:  civil.New(2025, time.December, 1),
+			wantGross:   121.99,
+			wantTax:     4.18,
+			wantNet:     117.81,
+		},
+		{
+			name:        "OTS at maturity",
+			bondName:    "OTS0825",
+			purchaseDay: 1,
+			redeemedAt:  civil.New(2025, time.August, 1),
+			wantGross:   100.76,
+			wantTax:     0.14,
+			wantNet:     100.62,
+		},
+		{
+			name:        "OTS can't be redeemed early",
+			bondName:    "OTS0825",
+			purchaseDay: 1,
+			redeemedAt:  civil.New(2025, time.July, 1),
+			wantErr:     calculator.ErrEarlyRedemptionNotAllowed,
+		},
+		{
+			name:        "redemption before purchase",
+			bondName:    "EDO0935",
+			purchaseDay: 2,
+			redeemedAt:  civil.New(2025, time.September, 1),
+			wantErr:     calculator.ErrValuationDateBeforePurchaseDate,
+		},
+	}
+
+	c := calculator.NewCalculator()
+	repo := LoadBondRepository()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bnd, err := repo.Lookup(tt.bondName)
+			if err != nil {
+				t.Fatalf("Lookup() error = %v", err)
+			}
+			got, err := c.Redeem(bnd, tt.purchaseDay, tt.redeemedAt, tt.taxation)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Redeem() error = %v, want %v", err, tt.wantErr)
+			}
+			if math.Abs(float64(got.Gross-tt.wantGross)) > 1e-9 {
+				t.Errorf("Redeem() Gross = %v, want %v", got.Gross, tt.wantGross)
+			}
+			if math.Abs(float64(got.Fee-tt.wantFee)) > 1e-9 {
+				t.Errorf("Redeem() Fee = %v, want %v", got.Fee, tt.wantFee)
+			}
+			if math.Abs(float64(got.Tax-tt.wantTax)) > 1e-9 {
+				t.Errorf("Redeem() Tax = %v, want %v", got.Tax, tt.wantTax)
+			}
+			if math.Abs(float64(got.Net-tt.wantNet)) > 1e-9 {
+				t.Errorf("Redeem() Net = %v, want %v", got.Net, tt.wantNet)
+			}
+			if got.Early != tt.wantEarly {
+				t.Errorf("Redeem() Early = %v, want %v", got.Early, tt.wantEarly)
+			}
+		})
+	}
+}
+
+func TestCalculator_NetValue(t *testing.T) {
+	tests := []struct {
+		name        string
+		bondName    string
+		purchaseDay int
+		valuatedAt  civil.Date
+		taxation    calculator.Taxation
+		wantFee     bond.Price
+		wantTax     bond.Price
+		wantNet     bond.Price
+	}{
+		{
+			name:        "OTS before maturity is taxed without a fee",
+			bondName:    "OTS0825",
+			purchaseDay: 1,
+			valuatedAt:  civil.New(2025, time.June, 15),
+			wantTax:     0.07,
+			wantNet:     100.30,
+		},
+		{
+			name:        "EDO matches early redemption",
+			bondName:    "EDO0834",
+			purchaseDay: 1,
+			valuatedAt:  civil.New(2025, time.August, 1),
+			wantFee:     2.00,
+			wantTax:     0.91,
+			wantNet:     103.89,
+		},
+		{
+			name:        "COI held in IKZE",
+			bondName:    "COI0528",
+			purchaseDay: 1,
+			valuatedAt:  civil.New(2025, time.November, 1),
+			taxation:    calculator.TaxExempt,
+			wantFee:     0.70,
+			wantNet:     102.40,
+		},
+	}
+
+	c := calculator.NewCalculator()
+	repo := LoadBondRepository()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bnd, err := repo.Lookup(tt.bondName)
+			if err != nil {
+				t.Fatalf("Lookup() error = %v", err)
+			}
+			got, err := c.NetValue(bnd, tt.purchaseDay, tt.valuatedAt, tt.taxation)
+			if err != nil {
+				t.Fatalf("NetValue() error = %v", err)
+			}
+			if math.Abs(float64(got.Fee-tt.wantFee)) > 1e-9 {
+				t.Errorf("NetValue() Fee = %v, want %v", got.Fee, tt.wantFee)
+			}
+			if math.Abs(float64(got.Tax-tt.wantTax)) > 1e-9 {
+				t.Errorf("NetValue() Tax = %v, want %v", got.Tax, tt.wantTax)
+			}
+			if math.Abs(float64(got.Net-tt.wantNet)) > 1e-9 {
+				t.Errorf("NetValue() Net = %v, want %v", got.Net, tt.wantNet)
+			}
+		})
+	}
+}
+
+func TestRedemption_Times(t *testing.T) {
+	c := calculator.NewCalculator()
+	bnd, err := LoadBondRepository().Lookup("EDO0834")
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+
+	redemption, err := c.Redeem(bnd, 1, civil.New(2025, time.August, 1), calculator.Taxable)
+	if err != nil {
+		t.Fatalf("Redeem() error = %v", err)
+	}
+	got, err := redemption.Times(3)
+	if err != nil {
+		t.Fatalf("Times() error = %v", err)
+	}
+
+	if !got.Early {
+		t.Error("Times() Early = false, want true")
+	}
+	prices := []struct {
+		field string
+		got   bond.Price
+		want  bond.Price
+	}{
+		{field: "Gross", got: got.Gross, want: 320.40},
+		{field: "Fee", got: got.Fee, want: 6.00},
+		// the tax is withheld per bond, 3 * 0.91 rather than 19% of 14.40
+		{field: "Tax", got: got.Tax, want: 2.73},
+		{field: "Net", got: got.Net, want: 311.67},
+	}
+	for _, p := range prices {
+		if math.Abs(float64(p.got-p.want)) > 1e-9 {
+			t.Errorf("Times() %s = %v, want %v", p.field, p.got, p.want)
+		}
+	}
+}
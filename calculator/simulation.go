@@ -0,0 +1,367 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/civil"
+	"github.com/maciekmm/obligacje/decimal"
+)
+
+var (
+	ErrUnknownRule = errors.New("unknown reinvestment rule")
+)
+
+// Rule decides what a simulated portfolio does with bonds reaching maturity and with the cash paid out.
+type Rule int
+
+const (
+	// RollOver exchanges matured bonds one for one into the issue of the same series on sale at maturity
+	// at its exchange price. Interest and the difference between the face value and the exchange price are kept in cash.
+	RollOver Rule = iota
+	// Reinvest rolls matured bonds over like RollOver and buys new bonds of the series at face value
+	// with the cash paid out, e.g. COI coupons, whenever it's enough for at least one bond.
+	Reinvest
+)
+
+// ParseRule maps the name of a reinvestment rule to the rule. An empty name stands for RollOver.
+func ParseRule(name string) (Rule, error) {
+	switch strings.ToLower(name) {
+	case "", "roll_over":
+		return RollOver, nil
+	case "reinvest":
+		return Reinvest, nil
+	}
+	return RollOver, ErrUnknownRule
+}
+
+func (r Rule) String() string {
+	if r == Reinvest {
+		return "reinvest"
+	}
+	return "roll_over"
+}
+
+// EventKind is the kind of a simulation event.
+type EventKind string
+
+const (
+	// EventPurchase is the purchase of the initial bonds at face value.
+	EventPurchase EventKind = "purchase"
+	// EventCoupon is a coupon paid out in cash.
+	EventCoupon EventKind = "coupon"
+	// EventRollOver is the exchange of matured bonds into a new issue, with the interest paid out in cash.
+	EventRollOver EventKind = "roll_over"
+	// EventReinvestment is the purchase of new bonds at face value with cash.
+	EventReinvestment EventKind = "reinvestment"
+)
+
+// SimulationEvent is a change of the holdings or the cash of a simulated portfolio.
+type SimulationEvent struct {
+	Kind EventKind
+	// Bond is the name of the issue paying out or, for purchases, bought.
+	Bond     string
+	Quantity int
+	// Amount is the cash received, or spent for purchases, after tax.
+	Amount bond.Price
+	// Target is the name of the issue matured bonds are rolled over into and TargetQuantity the number of bonds acquired.
+	Target         string
+	TargetQuantity int
+}
+
+// Holding is a lot of bonds of the same issue acquired on the same day at the same price.
+type Holding struct {
+	Bond        bond.Bond
+	PurchaseDay int
+	Quantity    int
+	// CostBasis is the price paid per bond, the exchange price for bonds acquired by rolling over.
+	CostBasis bond.Price
+	// Assumed reports whether the issue isn't in the repository and is assumed to carry the terms
+	// of the latest issue of the series.
+	Assumed bool
+	// Value is the value of all bonds of the holding at the date of the step.
+	Value bond.Price
+	// NetValue is what the holder would receive for all bonds of the holding if they were redeemed at the date of the step.
+	NetValue bond.Price
+}
+
+// SimulationStep is the state of a simulated portfolio after the events of a single day.
+type SimulationStep struct {
+	Date     civil.Date
+	Events   []SimulationEvent
+	Holdings []Holding
+	// Cash is the sum of the interest, coupons and leftovers paid out and not reinvested so far.
+	Cash bond.Price
+	// Value is the value of all holdings plus the cash.
+	Value bond.Price
+	// NetValue is what the holder would end up with if all holdings were redeemed at the date, plus the cash.
+	NetValue bond.Price
+	// Derived reports whether the step relies on interest rates derived from market indices or on assumed issues.
+	Derived bool
+}
+
+// Simulate walks forward from buying quantity bonds on purchaseDay until the given date, applying the rule
+// whenever bonds mature or pay coupons. New bonds are bought from the issue of the same series on sale
+// in the repository, or, once the repository runs out of issues, from an issue assumed to carry the terms
+// of its latest issue of the series, including the first period rate. Rates of later periods not published yet
+// are derived from the indices of the calculator, see WithScenario.
+// It returns a step for the purchase, for the end of every interest period of the holdings and for until,
+// or ErrValuationDateAfterMaturity if rates of a holding until then are unknown.
+func (c *Calculator) Simulate(repo bond.Repository, bnd bond.Bond, purchaseDay, quantity int, until civil.Date, rule Rule, taxation Taxation) ([]SimulationStep, error) {
+	if quantity < 1 || quantity > MaxQuantity {
+		return nil, ErrInvalidQuantity
+	}
+	purchaseDate := civil.New(bnd.SaleStart.Year(), bnd.SaleStart.Month(), purchaseDay)
+	if until.Before(purchaseDate) {
+		return nil, ErrValuationDateBeforePurchaseDate
+	}
+
+	invested, err := bnd.FaceValue.Times(quantity)
+	if err != nil {
+		return nil, err
+	}
+	holdings := []Holding{{Bond: bnd, PurchaseDay: purchaseDay, Quantity: quantity, CostBasis: bnd.FaceValue}}
+	var cash decimal.Decimal
+	step, err := c.simulationStep(purchaseDate, holdings, cash, []SimulationEvent{{
+		Kind:     EventPurchase,
+		Bond:     bnd.Name,
+		Quantity: quantity,
+		Amount:   invested,
+	}}, taxation)
+	if err != nil {
+		return nil, err
+	}
+	steps := []SimulationStep{step}
+
+	for date := purchaseDate; ; {
+		next, ok := nextEvent(holdings, date)
+		if !ok || next.After(until) {
+			break
+		}
+		date = next
+
+		var events []SimulationEvent
+		var held []Holding
+		for _, h := range holdings {
+			event, rolled, err := c.simulateEvent(repo, h, date, taxation)
+			if err != nil {
+				return nil, err
+			}
+			switch {
+			case event.Kind == "" || event.Kind == EventCoupon:
+				held = append(held, h)
+			case rolled.Quantity > 0:
+				held = append(held, rolled)
+			}
+			if event.Kind != "" {
+				events = append(events, event)
+				if cash, err = cash.Add(event.Amount.Decimal()); err != nil {
+					return nil, err
+				}
+			}
+		}
+		holdings = held
+
+		if rule == Reinvest && cash >= bnd.FaceValue.Decimal() {
+			issue, assumed, err := issueOnSale(repo, bnd.Series(), date)
+			if err != nil {
+				return nil, err
+			}
+			if bought := int(cash / issue.FaceValue.Decimal()); bought > 0 {
+				spent, err := issue.FaceValue.Times(bought)
+				if err != nil {
+					return nil, err
+				}
+				cash -= spent.Decimal()
+				holdings = append(holdings, Holding{Bond: issue, PurchaseDay: date.Day(), Quantity: bought, CostBasis: issue.FaceValue, Assumed: assumed})
+				events = append(events, SimulationEvent{Kind: EventReinvestment, Bond: issue.Name, Quantity: bought, Amount: spent})
+			}
+		}
+
+		step, err := c.simulationStep(date, holdings, cash, events, taxation)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+
+	if last := steps[len(steps)-1]; last.Date != until {
+		step, err := c.simulationStep(until, holdings, cash, nil, taxation)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// nextEvent returns the earliest end of an interest period of the holdings after date.
+func nextEvent(holdings []Holding, date civil.Date) (civil.Date, bool) {
+	var next civil.Date
+	for _, h := range holdings {
+		for i := range h.Bond.InterestPeriodCount() {
+			_, end, err := h.Bond.Period(i, h.PurchaseDay)
+			if err != nil || !end.After(date) {
+				continue
+			}
+			if next.IsZero() || end.Before(next) {
+				next = end
+			}
+			break
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// simulateEvent pays out the coupon of the holding or rolls it over if it matures at date.
+// It returns a zero event if nothing happens to the holding at date, and the holding acquired by rolling over.
+func (c *Calculator) simulateEvent(repo bond.Repository, h Holding, date civil.Date, taxation Taxation) (SimulationEvent, Holding, error) {
+	count := h.Bond.InterestPeriodCount()
+	_, maturity, err := h.Bond.Period(count-1, h.PurchaseDay)
+	if err != nil {
+		return SimulationEvent{}, Holding{}, err
+	}
+
+	if date != maturity {
+		strategy, err := StrategyFor(h.Bond)
+		if err != nil {
+			return SimulationEvent{}, Holding{}, err
+		}
+		if !strategy.PaysCoupons() || !periodEndsAt(h, date) {
+			return SimulationEvent{}, Holding{}, nil
+		}
+		valuation, err := c.Valuate(h.Bond, h.PurchaseDay, date)
+		if err != nil {
+			return SimulationEvent{}, Holding{}, fmt.Errorf("%w: %s", err, h.Bond.Name)
+		}
+		coupon := valuation.Coupons[len(valuation.Coupons)-1]
+		net, err := bond.PriceOf(coupon.Decimal() - taxation.Tax(coupon).Decimal()).Times(h.Quantity)
+		if err != nil {
+			return SimulationEvent{}, Holding{}, err
+		}
+		return SimulationEvent{Kind: EventCoupon, Bond: h.Bond.Name, Quantity: h.Quantity, Amount: net}, Holding{}, nil
+	}
+
+	redemption, err := c.RedeemWithCostBasis(h.Bond, h.PurchaseDay, maturity, taxation, h.CostBasis)
+	if err != nil {
+		return SimulationEvent{}, Holding{}, fmt.Errorf("%w: %s", err, h.Bond.Name)
+	}
+	target, assumed, err := issueOnSale(repo, h.Bond.Series(), maturity)
+	if err != nil {
+		return SimulationEvent{}, Holding{}, err
+	}
+	price := target.ExchangePrice
+	if price <= 0 {
+		// issues without an exchange price are bought at face value
+		price = target.FaceValue
+	}
+
+	payout, err := redemption.Net.Times(h.Quantity)
+	if err != nil {
+		return SimulationEvent{}, Holding{}, err
+	}
+	// bonds are swapped one for one, paying out the difference of the exchange price
+	rolled := Holding{
+		Bond:        target,
+		PurchaseDay: maturity.Day(),
+		Quantity:    h.Quantity,
+		CostBasis:   price,
+		Assumed:     assumed,
+	}
+	faceValue := h.Bond.FaceValue.Decimal() * decimal.Decimal(h.Quantity)
+	leftover := faceValue - price.Decimal()*decimal.Decimal(h.Quantity)
+	return SimulationEvent{
+		Kind:           EventRollOver,
+		Bond:           h.Bond.Name,
+		Quantity:       h.Quantity,
+		Amount:         bond.PriceOf(payout.Decimal() - faceValue + leftover),
+		Target:         target.Name,
+		TargetQuantity: rolled.Quantity,
+	}, rolled, nil
+}
+
+func periodEndsAt(h Holding, date civil.Date) bool {
+	for i := range h.Bond.InterestPeriodCount() {
+		_, end, err := h.Bond.Period(i, h.PurchaseDay)
+		if err == nil && end == date {
+			return true
+		}
+	}
+	return false
+}
+
+// simulationStep values the holdings at date.
+func (c *Calculator) simulationStep(date civil.Date, holdings []Holding, cash decimal.Decimal, events []SimulationEvent, taxation Taxation) (SimulationStep, error) {
+	step := SimulationStep{
+		Date:     date,
+		Events:   events,
+		Holdings: make([]Holding, len(holdings)),
+		Cash:     bond.PriceOf(cash),
+	}
+	value, net := cash, cash
+	for i, h := range holdings {
+		valuation, err := c.Valuate(h.Bond, h.PurchaseDay, date)
+		if err != nil {
+			return SimulationStep{}, fmt.Errorf("%w: %s", err, h.Bond.Name)
+		}
+		redemption, err := c.NetValueWithCostBasis(h.Bond, h.PurchaseDay, date, taxation, h.CostBasis)
+		if err != nil {
+			return SimulationStep{}, fmt.Errorf("%w: %s", err, h.Bond.Name)
+		}
+		if h.Value, err = valuation.Price.Times(h.Quantity); err != nil {
+			return SimulationStep{}, err
+		}
+		if h.NetValue, err = redemption.Net.Times(h.Quantity); err != nil {
+			return SimulationStep{}, err
+		}
+		step.Holdings[i] = h
+
+		if value, err = value.Add(h.Value.Decimal()); err != nil {
+			return SimulationStep{}, err
+		}
+		if net, err = net.Add(h.NetValue.Decimal()); err != nil {
+			return SimulationStep{}, err
+		}
+		step.Derived = step.Derived || valuation.Derived || h.Assumed
+	}
+	step.Value = bond.PriceOf(value)
+	step.NetValue = bond.PriceOf(net)
+	return step, nil
+}
+
+// issueOnSale returns the issue of the series on sale at date. Once the repository runs out of issues
+// it returns an issue assumed to carry the terms of the latest issue of the series and reports true.
+func issueOnSale(repo bond.Repository, series string, at civil.Date) (bond.Bond, bool, error) {
+	issue, err := bond.OnSale(repo, series, at)
+	if !errors.Is(err, bond.ErrNotOnSale) {
+		return issue, false, err
+	}
+
+	var latest bond.Bond
+	for _, bnd := range repo.List() {
+		if bnd.Series() == series && (latest.Name == "" || bnd.SaleStart.After(latest.SaleStart)) {
+			latest = bnd
+		}
+	}
+	if latest.Name == "" || !at.After(latest.SaleEnd) {
+		return bond.Bond{}, false, fmt.Errorf("%w: %s at %s", bond.ErrNotOnSale, series, at)
+	}
+	saleStart := civil.New(at.Year(), at.Month(), 1)
+	maturity := saleStart.AddDate(0, latest.MonthsToMaturity, 0)
+	issue = latest
+	issue.Name = fmt.Sprintf("%s%02d%02d", series, int(maturity.Month()), maturity.Year()%100)
+	issue.ISIN = ""
+	issue.SaleStart = saleStart
+	issue.SaleEnd = civil.New(at.Year(), at.Month()+1, 0)
+	issue.InterestPeriods = slices.Clone(latest.InterestPeriods[:min(len(latest.InterestPeriods), 1)])
+	issue.MaturityInterest = 0
+	issue.PeriodInterest = nil
+	strategy, err := StrategyFor(issue)
+	if err != nil {
+		return bond.Bond{}, false, err
+	}
+	return strategy.Normalize(issue), true, nil
+}
@@ -0,0 +1,187 @@
+package calculator_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/maciekmm/obligacje/calculator"
+	"github.com/maciekmm/obligacje/civil"
+	"github.com/maciekmm/obligacje/index"
+)
+
+func TestCalculator_Simulate_RollOver(t *testing.T) {
+	c := calculator.NewCalculator()
+	repo := LoadBondRepository()
+
+	bnd, err := repo.Lookup("TOS1125")
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+
+	steps, err := c.Simulate(repo, bnd, 1, 10, civil.New(2026, time.March, 1), calculator.RollOver, calculator.Taxable)
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+
+	// purchase, two capitalisations, maturity and the final date
+	if len(steps) != 5 {
+		t.Fatalf("Simulate() returned %d steps, want 5", len(steps))
+	}
+	if got := steps[0].Events; len(got) != 1 || got[0].Kind != calculator.EventPurchase || got[0].Amount != 1000 {
+		t.Errorf("Simulate() first events = %+v, want a purchase of 1000", got)
+	}
+
+	rollOver := steps[3]
+	if want := civil.New(2025, time.November, 1); rollOver.Date != want {
+		t.Errorf("Simulate() roll over date = %v, want %v", rollOver.Date, want)
+	}
+	if len(rollOver.Events) != 1 {
+		t.Fatalf("Simulate() roll over events = %+v, want 1", rollOver.Events)
+	}
+	event := rollOver.Events[0]
+	if event.Kind != calculator.EventRollOver || event.Target != "TOS1128" || event.TargetQuantity != 10 {
+		t.Errorf("Simulate() roll over = %+v, want 10 bonds of TOS1128", event)
+	}
+	// 10 * (117.91 - 19% of 17.91) - 1000 face value + 10 * (100 - 99.90) leftover
+	if math.Abs(float64(event.Amount)-179.10) > 1e-9 {
+		t.Errorf("Simulate() roll over amount = %v, want 179.10", event.Amount)
+	}
+	holding := rollOver.Holdings[0]
+	if holding.Bond.Name != "TOS1128" || holding.CostBasis != 99.90 || holding.Assumed {
+		t.Errorf("Simulate() holding = %s at %v, assumed %v, want TOS1128 at 99.90 from the repository", holding.Bond.Name, holding.CostBasis, holding.Assumed)
+	}
+
+	last := steps[len(steps)-1]
+	if want := civil.New(2026, time.March, 1); last.Date != want || len(last.Events) != 0 {
+		t.Errorf("Simulate() last step = %v with %d events, want %v without events", last.Date, len(last.Events), want)
+	}
+	if math.Abs(float64(last.Value)-1195.20) > 1e-9 || math.Abs(float64(last.Cash)-179.10) > 1e-9 {
+		t.Errorf("Simulate() last value = %v with cash %v, want 1195.20 with 179.10", last.Value, last.Cash)
+	}
+}
+
+func TestCalculator_Simulate_RollOverOneForOne(t *testing.T) {
+	c := calculator.NewCalculator()
+	repo := LoadBondRepository()
+
+	bnd, err := repo.Lookup("TOS1125")
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+
+	steps, err := c.Simulate(repo, bnd, 1, 1000, civil.New(2025, time.November, 1), calculator.RollOver, calculator.Taxable)
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+	event := steps[len(steps)-1].Events[0]
+	if event.Kind != calculator.EventRollOver || event.TargetQuantity != 1000 {
+		t.Errorf("Simulate() roll over = %+v, want 1000 bonds of TOS1128", event)
+	}
+	// 1000 * (117.91 - 19% of 17.91) - 100000 face value + 1000 * (100 - 99.90) leftover
+	if math.Abs(float64(event.Amount)-17910) > 1e-9 {
+		t.Errorf("Simulate() roll over amount = %v, want 17910", event.Amount)
+	}
+}
+
+func TestCalculator_Simulate_Reinvest(t *testing.T) {
+	c := calculator.NewCalculator(calculator.WithInflation(LoadCPI())).
+		WithScenario(calculator.Indices{Inflation: index.Constant(0.03)})
+	repo := LoadBondRepository()
+
+	bnd, err := repo.Lookup("COI0528")
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+
+	steps, err := c.Simulate(repo, bnd, 1, 10, civil.New(2028, time.May, 1), calculator.Reinvest, calculator.Taxable)
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+
+	firstCoupon := steps[1]
+	// 10 * (6.55 - 19% of 6.55), not enough for a new bond
+	if math.Abs(float64(firstCoupon.Cash)-53.10) > 1e-9 || len(firstCoupon.Holdings) != 1 {
+		t.Errorf("Simulate() after the first coupon cash = %v with %d holdings, want 53.10 with 1", firstCoupon.Cash, len(firstCoupon.Holdings))
+	}
+
+	secondCoupon := steps[2]
+	if len(secondCoupon.Events) != 2 || secondCoupon.Events[1].Kind != calculator.EventReinvestment {
+		t.Fatalf("Simulate() after the second coupon events = %+v, want a coupon and a reinvestment", secondCoupon.Events)
+	}
+	reinvested := secondCoupon.Holdings[1]
+	if reinvested.Bond.Name != "COI0530" || reinvested.Quantity != 1 || !reinvested.Assumed {
+		t.Errorf("Simulate() reinvested %d bonds of %s, assumed %v, want 1 assumed bond of COI0530", reinvested.Quantity, reinvested.Bond.Name, reinvested.Assumed)
+	}
+	if !secondCoupon.Derived {
+		t.Error("Simulate() step with an assumed issue should be derived")
+	}
+	// 53.10 + 49.80 - 100
+	if math.Abs(float64(secondCoupon.Cash)-2.90) > 1e-9 {
+		t.Errorf("Simulate() cash after reinvesting = %v, want 2.90", secondCoupon.Cash)
+	}
+
+	last := steps[len(steps)-1]
+	if want := civil.New(2028, time.May, 1); last.Date != want {
+		t.Errorf("Simulate() last step date = %v, want %v", last.Date, want)
+	}
+	for _, h := range last.Holdings {
+		if h.Bond.Name == "COI0528" {
+			t.Errorf("Simulate() still holds %s after maturity", h.Bond.Name)
+		}
+	}
+}
+
+func TestCalculator_Simulate_Errors(t *testing.T) {
+	c := calculator.NewCalculator()
+	repo := LoadBondRepository()
+
+	bnd, err := repo.Lookup("EDO0834")
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		quantity int
+		until    civil.Date
+		wantErr  error
+	}{
+		{name: "invalid quantity", quantity: 0, until: civil.New(2025, time.August, 1), wantErr: calculator.ErrInvalidQuantity},
+		{name: "until before purchase", quantity: 1, until: civil.New(2024, time.July, 31), wantErr: calculator.ErrValuationDateBeforePurchaseDate},
+		{name: "rates unknown", quantity: 1, until: civil.New(2030, time.August, 1), wantErr: calculator.ErrValuationDateAfterMaturity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := c.Simulate(repo, bnd, 1, tt.quantity, tt.until, calculator.RollOver, calculator.Taxable)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Simulate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    calculator.Rule
+		wantErr error
+	}{
+		{name: "", want: calculator.RollOver},
+		{name: "roll_over", want: calculator.RollOver},
+		{name: "REINVEST", want: calculator.Reinvest},
+		{name: "ladder", wantErr: calculator.ErrUnknownRule},
+	}
+
+	for _, tt := range tests {
+		got, err := calculator.ParseRule(tt.name)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("ParseRule(%q) error = %v, want %v", tt.name, err, tt.wantErr)
+		}
+		if err == nil && got != tt.want {
+			t.Errorf("ParseRule(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
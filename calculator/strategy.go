@@ -0,0 +1,257 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/civil"
+	"github.com/maciekmm/obligacje/decimal"
+)
+
+var (
+	ErrUnsupportedSeries = errors.New("unsupported bond series")
+)
+
+// Strategy captures the rules specific to a bond series.
+// Adding support for a new series boils down to registering a Strategy for its prefix,
+// and issues of a series sold under different terms get a Strategy registered from the date the terms changed.
+type Strategy interface {
+	// Frequency returns how often the interest rate is recalculated.
+	Frequency() bond.CouponPaymentsFrequency
+	// Normalize completes bond data that the issuer publishes in a shortened form.
+	Normalize(bnd bond.Bond) bond.Bond
+	// Interest returns the interest a single bond worth principal earns over heldDays
+	// of an interest period lasting periodDays, rounded half up to places digits after the decimal point.
+	Interest(principal decimal.Decimal, rate bond.Percentage, heldDays, periodDays, places int) decimal.Decimal
+	// PaysCoupons reports whether interest is paid out at the end of each period
+	// instead of being capitalised.
+	PaysCoupons() bool
+	// FixedRate reports whether the rate of the first period applies until maturity.
+	FixedRate() bool
+	// DeriveRate derives the yearly rate of a period starting at start from market indices
+	// before the issuer publishes it. It reports false if the rate can't be derived.
+	DeriveRate(bnd bond.Bond, start civil.Date, indices Indices) (bond.Percentage, bool)
+}
+
+// issueStrategy is the strategy of the issues of a series sold from the given date onwards.
+type issueStrategy struct {
+	since    civil.Date
+	strategy Strategy
+}
+
+var (
+	strategiesMu sync.RWMutex
+	// strategies lists the strategies of each series ordered by the date they apply from.
+	strategies = make(map[string][]issueStrategy)
+)
+
+// Register makes a strategy available for bonds of the given series.
+// It panics if a strategy for the series is already registered.
+func Register(series string, strategy Strategy) {
+	RegisterSince(series, civil.Date{}, strategy)
+}
+
+// RegisterSince makes a strategy available for issues of the given series sold from since onwards,
+// taking over from the strategy registered for earlier issues.
+// It panics if a strategy for the series and date is already registered.
+func RegisterSince(series string, since civil.Date, strategy Strategy) {
+	strategiesMu.Lock()
+	defer strategiesMu.Unlock()
+
+	issues := strategies[series]
+	i, found := slices.BinarySearchFunc(issues, since, func(is issueStrategy, since civil.Date) int {
+		return is.since.Compare(since)
+	})
+	if found {
+		panic(fmt.Sprintf("calculator: strategy for series %s since %s already registered", series, since))
+	}
+	strategies[series] = slices.Insert(issues, i, issueStrategy{since: since, strategy: strategy})
+}
+
+// Series returns the sorted list of series with a registered strategy.
+func Series() []string {
+	strategiesMu.RLock()
+	defer strategiesMu.RUnlock()
+
+	series := make([]string, 0, len(strategies))
+	for s := range strategies {
+		series = append(series, s)
+	}
+	slices.Sort(series)
+	return series
+}
+
+// StrategyFor returns the strategy registered for the series of the bond which applies to the issue,
+// i.e. the one registered for the latest date not after the sale start of the bond.
+func StrategyFor(bnd bond.Bond) (Strategy, error) {
+	strategiesMu.RLock()
+	defer strategiesMu.RUnlock()
+
+	var strategy Strategy
+	for _, is := range strategies[bnd.Series()] {
+		if is.since.After(bnd.SaleStart) {
+			break
+		}
+		strategy = is.strategy
+	}
+	if strategy == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedSeries, bnd.Name)
+	}
+	return strategy, nil
+}
+
+func init() {
+	// OTS can't be redeemed before maturity
+	Register("OTS", actual365{frequency: bond.CouponPaymentsFrequencyQuarterly})
+
+	// early redemption fees per bond as specified in the current issue letters (listy emisyjne),
+	// which are also applied to past issues
+	Register("TOS", fixedRate{periodic{frequency: bond.CouponPaymentsFrequencyYearly, fee: 0.70}})
+	Register("DOS", fixedRate{periodic{frequency: bond.CouponPaymentsFrequencyYearly, fee: 0.70}})
+
+	Register("ROR", floating{periodic{frequency: bond.CouponPaymentsFrequencyMonthly, coupons: true, fee: 0.50}})
+	Register("DOR", floating{periodic{frequency: bond.CouponPaymentsFrequencyMonthly, coupons: true, fee: 0.70}})
+
+	// COI issues sold until July 2003 capitalised the interest and paid it out at maturity
+	Register("COI", inflationLinked{periodic{frequency: bond.CouponPaymentsFrequencyYearly, fee: 0.70}})
+	RegisterSince("COI", civil.New(2003, time.August, 1), inflationLinked{periodic{frequency: bond.CouponPaymentsFrequencyYearly, coupons: true, fee: 0.70}})
+	Register("EDO", inflationLinked{periodic{frequency: bond.CouponPaymentsFrequencyYearly, fee: 2.00}})
+	Register("ROS", inflationLinked{periodic{frequency: bond.CouponPaymentsFrequencyYearly, fee: 0.70}})
+	Register("ROD", inflationLinked{periodic{frequency: bond.CouponPaymentsFrequencyYearly, fee: 2.00}})
+}
+
+// periodic accrues the period rate (yearly rate divided by the frequency)
+// proportionally to the number of days held within the period.
+type periodic struct {
+	frequency bond.CouponPaymentsFrequency
+	coupons   bool
+	// fee is the early redemption fee per bond
+	fee bond.Price
+}
+
+func (p periodic) Frequency() bond.CouponPaymentsFrequency {
+	return p.frequency
+}
+
+func (p periodic) Normalize(bnd bond.Bond) bond.Bond {
+	// issues capitalising the interest in a series paying coupons have the interest at maturity
+	// published as the interest of the last period
+	if n := len(bnd.PeriodInterest); !p.coupons && n > 0 && bnd.MaturityInterest == 0 {
+		bnd.MaturityInterest = bnd.PeriodInterest[n-1]
+		bnd.PeriodInterest = nil
+	}
+	bnd.EarlyRedeemable = true
+	bnd.EarlyRedemptionFee = p.fee
+	return bnd
+}
+
+func (p periodic) Interest(principal decimal.Decimal, rate bond.Percentage, heldDays, periodDays, places int) decimal.Decimal {
+	return principal.Mul(rate.Decimal()).MulRatio(int64(heldDays), int64(p.frequency)*int64(periodDays), places)
+}
+
+func (p periodic) PaysCoupons() bool {
+	return p.coupons
+}
+
+func (p periodic) FixedRate() bool {
+	return false
+}
+
+func (p periodic) DeriveRate(bnd bond.Bond, start civil.Date, indices Indices) (bond.Percentage, bool) {
+	return 0, false
+}
+
+// fixedRate is a periodic strategy for bonds with the same rate in every period.
+// The issuer publishes only the first period rate.
+type fixedRate struct {
+	periodic
+}
+
+func (f fixedRate) Normalize(bnd bond.Bond) bond.Bond {
+	bnd = f.periodic.Normalize(bnd)
+	if len(bnd.InterestPeriods) == 0 {
+		return bnd
+	}
+	periods := make([]bond.Percentage, 0, bnd.MonthsToMaturity/f.frequency.Months())
+	for range bnd.MonthsToMaturity / f.frequency.Months() {
+		periods = append(periods, bnd.InterestPeriods[0])
+	}
+	bnd.InterestPeriods = periods
+	return bnd
+}
+
+func (f fixedRate) FixedRate() bool {
+	return true
+}
+
+// inflationLinked is a periodic strategy for bonds paying the year-on-year CPI plus the margin
+// in every period but the first, with the CPI floored at zero. The CPI is the one announced by GUS
+// in the month preceding the period, i.e. the CPI for the second month before the period starts.
+type inflationLinked struct {
+	periodic
+}
+
+func (l inflationLinked) DeriveRate(bnd bond.Bond, start civil.Date, indices Indices) (bond.Percentage, bool) {
+	if indices.Inflation == nil {
+		return 0, false
+	}
+	cpi, ok := indices.Inflation.At(civil.New(start.Year(), start.Month()-2, 1))
+	if !ok {
+		return 0, false
+	}
+	return (max(cpi, 0) + bnd.Margin).Round(), true
+}
+
+// floating is a periodic strategy for bonds paying the NBP reference rate plus the margin
+// in every period but the first. The reference rate is the one in effect
+// on the last day of the month preceding the month the period starts in.
+type floating struct {
+	periodic
+}
+
+func (f floating) DeriveRate(bnd bond.Bond, start civil.Date, indices Indices) (bond.Percentage, bool) {
+	if indices.ReferenceRate == nil {
+		return 0, false
+	}
+	rate, ok := indices.ReferenceRate.At(civil.New(start.Year(), start.Month(), 0))
+	if !ok {
+		return 0, false
+	}
+	return (rate + bnd.Margin).Round(), true
+}
+
+// actual365 accrues the yearly rate for the actual number of days held assuming a 365-day year.
+// The interest is a fixed amount depending on the actual length of the period rather than a fraction of a yearly coupon.
+type actual365 struct {
+	frequency bond.CouponPaymentsFrequency
+}
+
+func (a actual365) Frequency() bond.CouponPaymentsFrequency {
+	return a.frequency
+}
+
+func (a actual365) Normalize(bnd bond.Bond) bond.Bond {
+	bnd.EarlyRedeemable = false
+	bnd.EarlyRedemptionFee = 0
+	return bnd
+}
+
+func (a actual365) Interest(principal decimal.Decimal, rate bond.Percentage, heldDays, periodDays, places int) decimal.Decimal {
+	return principal.Mul(rate.Decimal()).MulRatio(int64(heldDays), 365, places)
+}
+
+func (a actual365) PaysCoupons() bool {
+	return false
+}
+
+func (a actual365) FixedRate() bool {
+	return true
+}
+
+func (a actual365) DeriveRate(bnd bond.Bond, start civil.Date, indices Indices) (bond.Percentage, bool) {
+	return 0, false
+}
@@ -0,0 +1,167 @@
+package calculator_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/calculator"
+	"github.com/maciekmm/obligacje/civil"
+	"github.com/maciekmm/obligacje/decimal"
+)
+
+func TestSeries(t *testing.T) {
+	want := []string{"COI", "DOR", "DOS", "EDO", "OTS", "ROD", "ROR", "ROS", "TOS"}
+	if got := calculator.Series(); !slices.Equal(got, want) {
+		t.Errorf("Series() = %v, want %v", got, want)
+	}
+}
+
+func TestStrategyFor(t *testing.T) {
+	tests := []struct {
+		name          string
+		wantFrequency bond.CouponPaymentsFrequency
+		wantErr       error
+	}{
+		{
+			name:          "OTS0126",
+			wantFrequency: bond.CouponPaymentsFrequencyQuarterly,
+		},
+		{
+			name:          "ROR1226",
+			wantFrequency: bond.CouponPaymentsFrequencyMonthly,
+		},
+		{
+			name:          "EDO0834",
+			wantFrequency: bond.CouponPaymentsFrequencyYearly,
+		},
+		{
+			name:    "KOS0125",
+			wantErr: calculator.ErrUnsupportedSeries,
+		},
+		{
+			name:    "AB",
+			wantErr: calculator.ErrUnsupportedSeries,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strategy, err := calculator.StrategyFor(bond.Bond{Name: tt.name})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("StrategyFor() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := strategy.Frequency(); got != tt.wantFrequency {
+				t.Errorf("Frequency() = %v, want %v", got, tt.wantFrequency)
+			}
+		})
+	}
+}
+
+func TestStrategyFor_IssueTerms(t *testing.T) {
+	tests := []struct {
+		name            string
+		saleStart       civil.Date
+		wantPaysCoupons bool
+	}{
+		{name: "COI0107", saleStart: civil.New(2003, time.January, 2), wantPaysCoupons: false},
+		{name: "COI0707", saleStart: civil.New(2003, time.July, 31), wantPaysCoupons: false},
+		{name: "COI0807", saleStart: civil.New(2003, time.August, 1), wantPaysCoupons: true},
+		{name: "COI0829", saleStart: civil.New(2025, time.August, 1), wantPaysCoupons: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strategy, err := calculator.StrategyFor(bond.Bond{Name: tt.name, SaleStart: tt.saleStart})
+			if err != nil {
+				t.Fatalf("StrategyFor() error = %v", err)
+			}
+			if got := strategy.PaysCoupons(); got != tt.wantPaysCoupons {
+				t.Errorf("PaysCoupons() = %v, want %v", got, tt.wantPaysCoupons)
+			}
+		})
+	}
+}
+
+func TestStrategy_NormalizeCapitalisedCOI(t *testing.T) {
+	bnd := bond.Bond{
+		Name:             "COI0104",
+		MonthsToMaturity: 48,
+		PeriodInterest:   []bond.Price{0, 0, 0, 47.42},
+		SaleStart:        civil.New(2000, time.January, 3),
+	}
+	strategy, err := calculator.StrategyFor(bnd)
+	if err != nil {
+		t.Fatalf("StrategyFor() error = %v", err)
+	}
+
+	got := strategy.Normalize(bnd)
+	if got.MaturityInterest != 47.42 || len(got.PeriodInterest) != 0 {
+		t.Errorf("Normalize() MaturityInterest = %v, PeriodInterest = %v, want 47.42 at maturity", got.MaturityInterest, got.PeriodInterest)
+	}
+}
+
+func TestStrategy_NormalizeFixedRate(t *testing.T) {
+	strategy, err := calculator.StrategyFor(bond.Bond{Name: "TOS0728"})
+	if err != nil {
+		t.Fatalf("StrategyFor() error = %v", err)
+	}
+
+	got := strategy.Normalize(bond.Bond{
+		Name:             "TOS0728",
+		MonthsToMaturity: 36,
+		InterestPeriods:  []bond.Percentage{0.0565},
+	})
+
+	want := []bond.Percentage{0.0565, 0.0565, 0.0565}
+	if !slices.Equal(got.InterestPeriods, want) {
+		t.Errorf("Normalize() InterestPeriods = %v, want %v", got.InterestPeriods, want)
+	}
+}
+
+func TestRegister_DuplicatePanics(t *testing.T) {
+	strategy, err := calculator.StrategyFor(bond.Bond{Name: "EDO0834"})
+	if err != nil {
+		t.Fatalf("StrategyFor() error = %v", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected Register() to panic on duplicate series")
+		}
+	}()
+	calculator.Register("EDO", strategy)
+}
+
+func TestStrategy_Interest(t *testing.T) {
+	tests := []struct {
+		name       string
+		bondName   string
+		rate       bond.Percentage
+		heldDays   int
+		periodDays int
+		places     int
+		want       decimal.Decimal
+	}{
+		// 100 * 4.5% / 12 = 0.375 exactly
+		{name: "monthly coupon rounded half up", bondName: "ROR0126", rate: 0.045, heldDays: 31, periodDays: 31, places: 2, want: decimal.FromFloat(0.38)},
+		{name: "capitalised interest not rounded", bondName: "EDO0834", rate: 0.0655, heldDays: 100, periodDays: 365, places: decimal.Scale, want: decimal.FromFloat(1.79452055)},
+		{name: "day count of OTS", bondName: "OTS0126", rate: 0.03, heldDays: 92, periodDays: 92, places: 2, want: decimal.FromFloat(0.76)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strategy, err := calculator.StrategyFor(bond.Bond{Name: tt.bondName})
+			if err != nil {
+				t.Fatalf("StrategyFor() error = %v", err)
+			}
+			got := strategy.Interest(decimal.FromInt(100), tt.rate, tt.heldDays, tt.periodDays, tt.places)
+			if got != tt.want {
+				t.Errorf("Interest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
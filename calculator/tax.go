@@ -0,0 +1,58 @@
+package calculator
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/decimal"
+)
+
+// TaxRate is the flat capital gains tax (podatek Belki) withheld from bond interest.
+const TaxRate = 0.19
+
+var (
+	ErrUnknownWrapper = errors.New("unknown tax wrapper")
+)
+
+// Taxation determines how the interest of a bond is taxed.
+type Taxation int
+
+const (
+	// Taxable bonds have the capital gains tax withheld from every interest payment.
+	Taxable Taxation = iota
+	// TaxExempt bonds are held in an IKE or IKZE account and interest is paid out in full.
+	TaxExempt
+)
+
+// ParseTaxation maps the account wrapper a bond is held in to its taxation.
+// An empty wrapper stands for a regular, taxable account.
+func ParseTaxation(wrapper string) (Taxation, error) {
+	switch strings.ToLower(wrapper) {
+	case "":
+		return Taxable, nil
+	case "ike", "ikze":
+		return TaxExempt, nil
+	}
+	return Taxable, ErrUnknownWrapper
+}
+
+// Tax returns the tax withheld from interest income.
+// The tax is rounded to the grosz, as required for taxes collected by the payer (art. 63 § 1a Ordynacji podatkowej).
+// Losses, e.g. when the early redemption fee exceeds the interest, are not taxed.
+func (t Taxation) Tax(income bond.Price) bond.Price {
+	if t == TaxExempt || income <= 0 {
+		return 0
+	}
+	return bond.PriceOf(income.Decimal().Mul(decimal.FromFloat(TaxRate)).Round(2))
+}
+
+// NetCoupons returns the coupons paid out until the valuation date after tax.
+// The tax is withheld from each coupon separately when it is paid.
+func (t Taxation) NetCoupons(valuation Valuation) bond.Price {
+	var net decimal.Decimal
+	for _, coupon := range valuation.Coupons {
+		net += coupon.Decimal() - t.Tax(coupon).Decimal()
+	}
+	return bond.PriceOf(net)
+}
@@ -0,0 +1,115 @@
+package calculator_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/calculator"
+	"github.com/maciekmm/obligacje/civil"
+)
+
+func TestParseTaxation(t *testing.T) {
+	tests := []struct {
+		wrapper string
+		want    calculator.Taxation
+		wantErr error
+	}{
+		{wrapper: "", want: calculator.Taxable},
+		{wrapper: "ike", want: calculator.TaxExempt},
+		{wrapper: "IKZE", want: calculator.TaxExempt},
+		{wrapper: "ppk", wantErr: calculator.ErrUnknownWrapper},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.wrapper, func(t *testing.T) {
+			got, err := calculator.ParseTaxation(tt.wrapper)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseTaxation() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseTaxation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTaxation_Tax(t *testing.T) {
+	tests := []struct {
+		name     string
+		taxation calculator.Taxation
+		income   bond.Price
+		want     bond.Price
+	}{
+		{name: "rounds down", taxation: calculator.Taxable, income: 6.55, want: 1.24},
+		{name: "rounds half up", taxation: calculator.Taxable, income: 0.50, want: 0.10},
+		{name: "small coupon", taxation: calculator.Taxable, income: 0.02, want: 0},
+		{name: "loss is not taxed", taxation: calculator.Taxable, income: -0.30, want: 0},
+		{name: "exempt", taxation: calculator.TaxExempt, income: 6.55, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.taxation.Tax(tt.income)
+			if math.Abs(float64(got-tt.want)) > 1e-9 {
+				t.Errorf("Tax() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTaxation_NetCoupons(t *testing.T) {
+	tests := []struct {
+		name        string
+		bondName    string
+		purchaseDay int
+		valuatedAt  civil.Date
+		taxation    calculator.Taxation
+		want        bond.Price
+	}{
+		{
+			name:        "COI coupon is taxed",
+			bondName:    "COI0528",
+			purchaseDay: 1,
+			valuatedAt:  civil.New(2025, time.November, 1),
+			want:        5.31,
+		},
+		{
+			name:        "ROR tax is withheld from each monthly coupon",
+			bondName:    "ROR0126",
+			purchaseDay: 1,
+			valuatedAt:  civil.New(2026, time.January, 1),
+			want:        3.98,
+		},
+		{
+			name:        "ROR in IKE",
+			bondName:    "ROR0126",
+			purchaseDay: 1,
+			valuatedAt:  civil.New(2026, time.January, 1),
+			taxation:    calculator.TaxExempt,
+			want:        4.90,
+		},
+	}
+
+	c := calculator.NewCalculator()
+	repo := LoadBondRepository()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bnd, err := repo.Lookup(tt.bondName)
+			if err != nil {
+				t.Fatalf("Lookup() error = %v", err)
+			}
+			valuation, err := c.Valuate(bnd, tt.purchaseDay, tt.valuatedAt)
+			if err != nil {
+				t.Fatalf("Valuate() error = %v", err)
+			}
+			got := tt.taxation.NetCoupons(valuation)
+			if math.Abs(float64(got-tt.want)) > 1e-9 {
+				t.Errorf("NetCoupons() = %v, want %v (coupons %v)", got, tt.want, valuation.Coupons)
+			}
+		})
+	}
+}
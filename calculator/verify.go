@@ -0,0 +1,85 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/maciekmm/obligacje/bond"
+)
+
+// Discrepancy is a difference between the interest calculated for a bond and the amount published by the issuer.
+type Discrepancy struct {
+	Name string
+	// Period is the zero-based index of the interest period, or -1 for the interest at maturity.
+	Period     int
+	Published  bond.Price
+	Calculated bond.Price
+	// Err is set if the interest couldn't be calculated at all.
+	Err error
+}
+
+func (d Discrepancy) String() string {
+	what := "interest at maturity"
+	if d.Period >= 0 {
+		what = fmt.Sprintf("interest in period %d", d.Period+1)
+	}
+	if d.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", d.Name, what, d.Err)
+	}
+	return fmt.Sprintf("%s: %s: published %.2f, calculated %.2f", d.Name, what, d.Published, d.Calculated)
+}
+
+// Verify compares the interest calculated for bonds bought on the first day of sale
+// against the amounts published by the issuer: the interest of each period for coupon paying series
+// and the interest at maturity for the others. Bonds without published amounts are skipped.
+func (c *Calculator) Verify(bonds []bond.Bond) []Discrepancy {
+	var discrepancies []Discrepancy
+	for _, bnd := range bonds {
+		discrepancies = append(discrepancies, c.verifyPeriodInterest(bnd)...)
+		discrepancies = append(discrepancies, c.verifyMaturityInterest(bnd)...)
+	}
+	return discrepancies
+}
+
+func (c *Calculator) verifyPeriodInterest(bnd bond.Bond) []Discrepancy {
+	if len(bnd.PeriodInterest) == 0 {
+		return nil
+	}
+	schedule, err := c.CashFlows(bnd, 1)
+	if err != nil {
+		return []Discrepancy{{Name: bnd.Name, Period: 0, Err: err}}
+	}
+
+	var discrepancies []Discrepancy
+	for i, published := range bnd.PeriodInterest {
+		if published == 0 {
+			continue
+		}
+		if i >= len(schedule.Periods) || !schedule.Periods[i].RateKnown {
+			discrepancies = append(discrepancies, Discrepancy{Name: bnd.Name, Period: i, Published: published, Err: errors.New("interest rate unknown")})
+			continue
+		}
+		if calculated := schedule.Periods[i].Interest; calculated.Decimal() != published.Decimal() {
+			discrepancies = append(discrepancies, Discrepancy{Name: bnd.Name, Period: i, Published: published, Calculated: calculated})
+		}
+	}
+	return discrepancies
+}
+
+func (c *Calculator) verifyMaturityInterest(bnd bond.Bond) []Discrepancy {
+	if bnd.MaturityInterest == 0 {
+		return nil
+	}
+	_, maturity, err := bnd.Period(bnd.InterestPeriodCount()-1, 1)
+	if err != nil {
+		return []Discrepancy{{Name: bnd.Name, Period: -1, Published: bnd.MaturityInterest, Err: err}}
+	}
+	price, err := c.Calculate(bnd, 1, maturity)
+	if err != nil {
+		return []Discrepancy{{Name: bnd.Name, Period: -1, Published: bnd.MaturityInterest, Err: err}}
+	}
+	if calculated := bond.PriceOf(price.Decimal() - bnd.FaceValue.Decimal()); calculated.Decimal() != bnd.MaturityInterest.Decimal() {
+		return []Discrepancy{{Name: bnd.Name, Period: -1, Published: bnd.MaturityInterest, Calculated: calculated}}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package calculator_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/calculator"
+)
+
+func TestCalculator_Verify(t *testing.T) {
+	c := calculator.NewCalculator()
+	repo := LoadBondRepository()
+
+	for _, d := range c.Verify(repo.List()) {
+		t.Errorf("Verify() found %s", d)
+	}
+}
+
+func TestCalculator_Verify_Discrepancies(t *testing.T) {
+	c := calculator.NewCalculator()
+	repo := LoadBondRepository()
+
+	ror, err := repo.Lookup("ROR0126")
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	ror.PeriodInterest = slices.Clone(ror.PeriodInterest)
+	ror.PeriodInterest[5] = 0.45
+
+	tos, err := repo.Lookup("TOS0825")
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	tos.MaturityInterest = 20.80
+
+	got := c.Verify([]bond.Bond{ror, tos})
+	want := []calculator.Discrepancy{
+		{Name: "ROR0126", Period: 5, Published: 0.45, Calculated: 0.44},
+		{Name: "TOS0825", Period: -1, Published: 20.80, Calculated: 20.79},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Verify() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i].Name != want[i].Name || got[i].Period != want[i].Period || got[i].Err != nil ||
+			got[i].Published.Decimal() != want[i].Published.Decimal() || got[i].Calculated.Decimal() != want[i].Calculated.Decimal() {
+			t.Errorf("Verify()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
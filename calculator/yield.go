@@ -0,0 +1,138 @@
+package calculator
+
+import (
+	"errors"
+	"math"
+
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/civil"
+	"github.com/maciekmm/obligacje/decimal"
+)
+
+var (
+	ErrInvalidYieldPeriod = errors.New("yield period must end after it starts")
+)
+
+// Yield is the return on a bond held over a period.
+// Rates are fractions, e.g. 0.0655 for 6.55%.
+type Yield struct {
+	From civil.Date
+	To   civil.Date
+	// Invested is the value of the bond at From.
+	Invested bond.Price
+	// Coupons is the sum of coupons paid out after From until To.
+	Coupons bond.Price
+	// Final is the value of the bond at To, or the payout if it was redeemed at To for net yields.
+	Final bond.Price
+	// Return is the total return over the period, with coupons not reinvested.
+	Return float64
+	// Annualised is the total return converted to an effective yearly rate.
+	Annualised float64
+	// IRR is the money-weighted internal rate of return as an effective yearly rate,
+	// which accounts for when coupons are paid out.
+	IRR float64
+	// Derived reports whether the yield relies on interest rates derived from market indices.
+	Derived bool
+}
+
+// Yield calculates the return on a bond bought on purchaseDay and held from from until to,
+// assuming 365-day years. If net is set, coupons are taxed according to taxation
+// and the bond is assumed to be redeemed at to, paying the early redemption fee and the tax.
+// Like Valuate, it returns ErrValuationDateAfterMaturity together with the yield
+// if rates up to to aren't known yet.
+func (c *Calculator) Yield(bnd bond.Bond, purchaseDay int, from, to civil.Date, net bool, taxation Taxation) (Yield, error) {
+	if !to.After(from) {
+		return Yield{}, ErrInvalidYieldPeriod
+	}
+
+	invested, err := c.Calculate(bnd, purchaseDay, from)
+	if err != nil {
+		return Yield{}, err
+	}
+	if invested <= 0 {
+		return Yield{}, ErrInvalidYieldPeriod
+	}
+
+	valuation, valuationErr := c.Valuate(bnd, purchaseDay, to)
+	if valuationErr != nil && !errors.Is(valuationErr, ErrValuationDateAfterMaturity) {
+		return Yield{}, valuationErr
+	}
+	final := valuation.Price
+	if net {
+		redemption, err := c.NetValue(bnd, purchaseDay, to, taxation)
+		if err != nil && !errors.Is(err, ErrValuationDateAfterMaturity) {
+			return Yield{}, err
+		}
+		final = redemption.Net
+	}
+
+	schedule, err := c.CashFlows(bnd, purchaseDay)
+	if err != nil {
+		return Yield{}, err
+	}
+
+	flows := []cashFlow{{at: from, amount: -float64(invested)}}
+	var coupons decimal.Decimal
+	for i, period := range schedule.Periods {
+		// the final coupon is paid out together with the face value and included in its value
+		if !period.PaidOut || !period.RateKnown || i == len(schedule.Periods)-1 {
+			continue
+		}
+		if !period.End.After(from) || period.End.After(to) {
+			continue
+		}
+		coupon := period.Payment
+		if net {
+			coupon = bond.PriceOf(coupon.Decimal() - taxation.Tax(coupon).Decimal())
+		}
+		coupons += coupon.Decimal()
+		flows = append(flows, cashFlow{at: period.End, amount: float64(coupon)})
+	}
+	flows = append(flows, cashFlow{at: to, amount: float64(final)})
+
+	total := (coupons + final.Decimal() - invested.Decimal()).Float64() / float64(invested)
+	years := float64(to.DaysSince(from)) / 365
+	return Yield{
+		From:       from,
+		To:         to,
+		Invested:   invested,
+		Coupons:    bond.PriceOf(coupons),
+		Final:      final,
+		Return:     total,
+		Annualised: math.Pow(1+total, 1/years) - 1,
+		IRR:        irr(flows),
+		Derived:    valuation.Derived,
+	}, valuationErr
+}
+
+type cashFlow struct {
+	at     civil.Date
+	amount float64
+}
+
+// irr finds the yearly rate at which the present value of the flows is zero by bisection.
+// The first flow must be the only outflow, so the present value decreases with the rate.
+func irr(flows []cashFlow) float64 {
+	presentValue := func(rate float64) float64 {
+		pv := 0.0
+		for _, flow := range flows {
+			years := float64(flow.at.DaysSince(flows[0].at)) / 365
+			pv += flow.amount / math.Pow(1+rate, years)
+		}
+		return pv
+	}
+
+	low, high := -0.99, 1.0
+	for presentValue(high) > 0 && high < 1e6 {
+		high *= 2
+	}
+	for range 200 {
+		mid := (low + high) / 2
+		if presentValue(mid) > 0 {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+	return (low + high) / 2
+}
@@ -0,0 +1,151 @@
+package calculator_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/calculator"
+	"github.com/maciekmm/obligacje/civil"
+)
+
+func TestCalculator_Yield(t *testing.T) {
+	tests := []struct {
+		name           string
+		bondName       string
+		from           civil.Date
+		to             civil.Date
+		net            bool
+		wantCoupons    bond.Price
+		wantFinal      bond.Price
+		wantReturn     float64
+		wantAnnualised float64
+		wantIRR        float64
+	}{
+		{
+			name:           "TOS held to maturity, IRR equals the annualised return",
+			bondName:       "TOS1125",
+			from:           civil.New(2022, time.November, 1),
+			to:             civil.New(2025, time.November, 1),
+			wantFinal:      121.99,
+			wantReturn:     0.2199,
+			wantAnnualised: 0.068436,
+			wantIRR:        0.068436,
+		},
+		{
+			name:           "COI for a year earns the first year rate",
+			bondName:       "COI0528",
+			from:           civil.New(2024, time.May, 1),
+			to:             civil.New(2025, time.May, 1),
+			wantCoupons:    6.55,
+			wantFinal:      100.00,
+			wantReturn:     0.0655,
+			wantAnnualised: 0.0655,
+			wantIRR:        0.0655,
+		},
+		{
+			name:           "COI coupon paid early raises the IRR",
+			bondName:       "COI0528",
+			from:           civil.New(2024, time.May, 1),
+			to:             civil.New(2025, time.November, 1),
+			wantCoupons:    6.55,
+			wantFinal:      103.10,
+			wantReturn:     0.0965,
+			wantAnnualised: 0.063162,
+			wantIRR:        0.064514,
+		},
+		{
+			name:           "COI net of coupon tax, fee and tax on redemption",
+			bondName:       "COI0528",
+			from:           civil.New(2024, time.May, 1),
+			to:             civil.New(2025, time.November, 1),
+			net:            true,
+			wantCoupons:    5.31,
+			wantFinal:      101.94,
+			wantReturn:     0.0725,
+			wantAnnualised: 0.047634,
+			wantIRR:        0.048466,
+		},
+	}
+
+	c := calculator.NewCalculator()
+	repo := LoadBondRepository()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bnd, err := repo.Lookup(tt.bondName)
+			if err != nil {
+				t.Fatalf("Lookup() error = %v", err)
+			}
+			got, err := c.Yield(bnd, 1, tt.from, tt.to, tt.net, calculator.Taxable)
+			if err != nil {
+				t.Fatalf("Yield() error = %v", err)
+			}
+			if math.Abs(float64(got.Invested-100)) > 1e-9 {
+				t.Errorf("Yield() Invested = %v, want 100", got.Invested)
+			}
+			if math.Abs(float64(got.Coupons-tt.wantCoupons)) > 1e-9 {
+				t.Errorf("Yield() Coupons = %v, want %v", got.Coupons, tt.wantCoupons)
+			}
+			if math.Abs(float64(got.Final-tt.wantFinal)) > 1e-9 {
+				t.Errorf("Yield() Final = %v, want %v", got.Final, tt.wantFinal)
+			}
+			if math.Abs(got.Return-tt.wantReturn) > 1e-6 {
+				t.Errorf("Yield() Return = %v, want %v", got.Return, tt.wantReturn)
+			}
+			if math.Abs(got.Annualised-tt.wantAnnualised) > 1e-6 {
+				t.Errorf("Yield() Annualised = %v, want %v", got.Annualised, tt.wantAnnualised)
+			}
+			if math.Abs(got.IRR-tt.wantIRR) > 1e-6 {
+				t.Errorf("Yield() IRR = %v, want %v", got.IRR, tt.wantIRR)
+			}
+		})
+	}
+}
+
+func TestCalculator_Yield_Errors(t *testing.T) {
+	c := calculator.NewCalculator()
+	repo := LoadBondRepository()
+
+	bnd, err := repo.Lookup("COI0528")
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		from    civil.Date
+		to      civil.Date
+		wantErr error
+	}{
+		{
+			name:    "empty period",
+			from:    civil.New(2025, time.May, 1),
+			to:      civil.New(2025, time.May, 1),
+			wantErr: calculator.ErrInvalidYieldPeriod,
+		},
+		{
+			name:    "before purchase",
+			from:    civil.New(2024, time.April, 1),
+			to:      civil.New(2025, time.May, 1),
+			wantErr: calculator.ErrValuationDateBeforePurchaseDate,
+		},
+		{
+			name:    "rates unknown",
+			from:    civil.New(2024, time.May, 1),
+			to:      civil.New(2028, time.May, 1),
+			wantErr: calculator.ErrValuationDateAfterMaturity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := c.Yield(bnd, 1, tt.from, tt.to, false, calculator.Taxable)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Yield() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+// Package civil implements calendar dates without a time of day or a time zone.
+// Bond periods are defined in whole days, so dates are counted exactly
+// regardless of DST changes and the time zone of the process.
+package civil
+
+import (
+	"cmp"
+	"fmt"
+	"time"
+
+	"github.com/maciekmm/obligacje/tz"
+)
+
+// Layout is the format dates are parsed from and formatted to, e.g. 2025-11-01.
+const Layout = "2006-01-02"
+
+// Date is a day in the Gregorian calendar.
+// Dates can be compared with the == operator. The zero value is 0000-00-00 and is reported by IsZero.
+type Date struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+// New returns the date of the given day. Like time.Date, values outside their usual ranges are normalized,
+// e.g. New(2025, time.March, 0) is the last day of February.
+func New(year int, month time.Month, day int) Date {
+	return Of(time.Date(year, month, day, 0, 0, 0, 0, time.UTC))
+}
+
+// Of returns the date of t in its location.
+func Of(t time.Time) Date {
+	year, month, day := t.Date()
+	return Date{year: year, month: month, day: day}
+}
+
+// Today returns the current date in Poland, where bonds are sold.
+func Today() Date {
+	return Of(time.Now().In(tz.UnifiedTimezone))
+}
+
+// Parse parses a date in the Layout format.
+func Parse(s string) (Date, error) {
+	t, err := time.Parse(Layout, s)
+	if err != nil {
+		return Date{}, err
+	}
+	return Of(t), nil
+}
+
+// ParseFormat parses a date in the given time.Parse layout, ignoring any time of day.
+func ParseFormat(layout, s string) (Date, error) {
+	t, err := time.Parse(layout, s)
+	if err != nil {
+		return Date{}, err
+	}
+	return Of(t), nil
+}
+
+func (d Date) Year() int {
+	return d.year
+}
+
+func (d Date) Month() time.Month {
+	return d.month
+}
+
+func (d Date) Day() int {
+	return d.day
+}
+
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+// AddDate returns the date years, months and days after d, normalized like time.Time.AddDate.
+func (d Date) AddDate(years, months, days int) Date {
+	return New(d.year+years, d.month+time.Month(months), d.day+days)
+}
+
+// DaysSince returns the number of days from u to d, negative if d is before u.
+func (d Date) DaysSince(u Date) int {
+	return d.days() - u.days()
+}
+
+func (d Date) Before(u Date) bool {
+	return d.Compare(u) < 0
+}
+
+func (d Date) After(u Date) bool {
+	return d.Compare(u) > 0
+}
+
+// Compare returns -1 if d is before u, +1 if it's after u and 0 if they are the same day.
+func (d Date) Compare(u Date) int {
+	switch {
+	case d.year != u.year:
+		return cmp.Compare(d.year, u.year)
+	case d.month != u.month:
+		return cmp.Compare(d.month, u.month)
+	default:
+		return cmp.Compare(d.day, u.day)
+	}
+}
+
+// In returns the midnight starting d in loc.
+func (d Date) In(loc *time.Location) time.Time {
+	return time.Date(d.year, d.month, d.day, 0, 0, 0, 0, loc)
+}
+
+// Format formats d according to a time.Time layout, e.g. "02" for the day of the month.
+func (d Date) Format(layout string) string {
+	return d.In(time.UTC).Format(layout)
+}
+
+// String formats d in the Layout format.
+func (d Date) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.year, d.month, d.day)
+}
+
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Date) UnmarshalText(text []byte) error {
+	parsed, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// days returns the number of days since 1970-01-01.
+func (d Date) days() int {
+	// days from civil, see https://howardhinnant.github.io/date_algorithms.html
+	y, m := d.year, int(d.month)
+	if m <= 2 {
+		y--
+	}
+	era := y
+	if era < 0 {
+		era -= 399
+	}
+	era /= 400
+	yearOfEra := y - era*400
+	mp := (m + 9) % 12
+	dayOfYear := (153*mp+2)/5 + d.day - 1
+	dayOfEra := yearOfEra*365 + yearOfEra/4 - yearOfEra/100 + dayOfYear
+	return era*146097 + dayOfEra - 719468
+}
@@ -0,0 +1,125 @@
+package civil_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/maciekmm/obligacje/civil"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name  string
+		year  int
+		month time.Month
+		day   int
+		want  string
+	}{
+		{name: "regular", year: 2025, month: time.November, day: 1, want: "2025-11-01"},
+		{name: "day zero", year: 2025, month: time.March, day: 0, want: "2025-02-28"},
+		{name: "day zero in leap year", year: 2024, month: time.March, day: 0, want: "2024-02-29"},
+		{name: "month overflow", year: 2025, month: 14, day: 1, want: "2026-02-01"},
+		{name: "month underflow", year: 2025, month: -1, day: 1, want: "2024-11-01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := civil.New(tt.year, tt.month, tt.day).String(); got != tt.want {
+				t.Errorf("New(%d, %d, %d) = %s, want %s", tt.year, tt.month, tt.day, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDate_DaysSince(t *testing.T) {
+	tests := []struct {
+		name string
+		from civil.Date
+		to   civil.Date
+		want int
+	}{
+		{name: "same day", from: civil.New(2025, time.March, 30), to: civil.New(2025, time.March, 30), want: 0},
+		{name: "over spring DST change", from: civil.New(2025, time.March, 29), to: civil.New(2025, time.March, 31), want: 2},
+		{name: "over autumn DST change", from: civil.New(2025, time.October, 25), to: civil.New(2025, time.October, 27), want: 2},
+		{name: "leap year", from: civil.New(2024, time.January, 1), to: civil.New(2025, time.January, 1), want: 366},
+		{name: "century not divisible by 400", from: civil.New(1900, time.February, 28), to: civil.New(1900, time.March, 1), want: 1},
+		{name: "backwards", from: civil.New(2025, time.November, 1), to: civil.New(2025, time.October, 1), want: -31},
+		{name: "since epoch", from: civil.New(1970, time.January, 1), to: civil.New(2000, time.March, 1), want: 11017},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.to.DaysSince(tt.from); got != tt.want {
+				t.Errorf("%s.DaysSince(%s) = %d, want %d", tt.to, tt.from, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDate_Compare(t *testing.T) {
+	a := civil.New(2025, time.October, 31)
+	b := civil.New(2025, time.November, 1)
+
+	if !a.Before(b) || a.After(b) || a.Compare(b) != -1 {
+		t.Errorf("%s should be before %s", a, b)
+	}
+	if !b.After(a) || b.Before(a) || b.Compare(a) != 1 {
+		t.Errorf("%s should be after %s", b, a)
+	}
+	if a.AddDate(0, 0, 1) != b || a.Compare(a) != 0 {
+		t.Errorf("%s plus a day should equal %s", a, b)
+	}
+}
+
+func TestOf(t *testing.T) {
+	// 23:30 UTC is already the next day in Warsaw
+	utc := time.Date(2025, time.October, 31, 23, 30, 0, 0, time.UTC)
+	warsaw, err := time.LoadLocation("Europe/Warsaw")
+	if err != nil {
+		t.Fatalf("LoadLocation() error = %v", err)
+	}
+
+	if got := civil.Of(utc); got != civil.New(2025, time.October, 31) {
+		t.Errorf("Of(%v) = %s, want 2025-10-31", utc, got)
+	}
+	if got := civil.Of(utc.In(warsaw)); got != civil.New(2025, time.November, 1) {
+		t.Errorf("Of(%v) = %s, want 2025-11-01", utc.In(warsaw), got)
+	}
+}
+
+func TestParse(t *testing.T) {
+	got, err := civil.Parse("2025-11-01")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if want := civil.New(2025, time.November, 1); got != want {
+		t.Errorf("Parse() = %s, want %s", got, want)
+	}
+
+	if _, err := civil.Parse("2025-11-01T00:00:00Z"); err == nil {
+		t.Error("Parse() with a time of day should fail")
+	}
+}
+
+func TestDate_JSON(t *testing.T) {
+	type holding struct {
+		PurchasedAt civil.Date `json:"purchased_at"`
+	}
+
+	data, err := json.Marshal(holding{PurchasedAt: civil.New(2025, time.November, 1)})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if want := `{"purchased_at":"2025-11-01"}`; string(data) != want {
+		t.Errorf("Marshal() = %s, want %s", data, want)
+	}
+
+	var got holding
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if got.PurchasedAt != civil.New(2025, time.November, 1) {
+		t.Errorf("Unmarshal() = %s, want 2025-11-01", got.PurchasedAt)
+	}
+}
@@ -3,10 +3,12 @@ package main
 import (
 	"net/http"
 	"os"
+	"path/filepath"
 
 	"log/slog"
 
 	"github.com/maciekmm/obligacje"
+	"github.com/maciekmm/obligacje/calculator"
 	"github.com/maciekmm/obligacje/internal/server"
 )
 
@@ -24,7 +26,24 @@ func main() {
 
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 
-	srv := server.NewServer(source, logger)
+	var opts []calculator.Option
+	cpi, err := obligacje.NewCPISource(logger, filepath.Join(dir, "cpi.csv"))
+	if err != nil {
+		logger.Warn("CPI not available, rates of inflation-linked bonds won't be derived", "err", err)
+	} else {
+		defer cpi.Close()
+		opts = append(opts, calculator.WithInflation(cpi))
+	}
+
+	referenceRate, err := obligacje.NewReferenceRateSource(logger, filepath.Join(dir, "nbp.csv"))
+	if err != nil {
+		logger.Warn("NBP reference rate not available, rates of floating rate bonds won't be derived", "err", err)
+	} else {
+		defer referenceRate.Close()
+		opts = append(opts, calculator.WithReferenceRate(referenceRate))
+	}
+
+	srv := server.NewServer(source, logger, opts...)
 
 	slog.Info("starting server on :8080")
 	if err := http.ListenAndServe(":8080", srv); err != nil {
@@ -0,0 +1,68 @@
+// Command verify compares the interest calculated for every bond against the amounts
+// published in the bond data of the Ministry of Finance and prints the differences.
+//
+// Usage:
+//
+//	verify [file.xlsx]
+//
+// Without a file the latest data is downloaded. It exits with status 1 if any differences are found.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/maciekmm/obligacje/bondxls"
+	"github.com/maciekmm/obligacje/calculator"
+)
+
+func main() {
+	discrepancies, err := run()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if discrepancies > 0 {
+		os.Exit(1)
+	}
+}
+
+// run verifies the bond data and returns the number of discrepancies found.
+func run() (int, error) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+
+	var file string
+	switch len(os.Args) {
+	case 1:
+		dir, err := os.MkdirTemp("", "obligacje-verify-")
+		if err != nil {
+			return 0, err
+		}
+		defer os.RemoveAll(dir)
+
+		file = filepath.Join(dir, "data.xlsx")
+		if err := bondxls.DownloadLatestAndConvert(context.Background(), file); err != nil {
+			return 0, fmt.Errorf("error downloading bond data: %w", err)
+		}
+	case 2:
+		file = os.Args[1]
+	default:
+		return 0, fmt.Errorf("usage: %s [file.xlsx]", filepath.Base(os.Args[0]))
+	}
+
+	repo, err := bondxls.LoadFromXLSX(logger, file)
+	if err != nil {
+		return 0, err
+	}
+
+	bonds := repo.List()
+	discrepancies := calculator.NewCalculator().Verify(bonds)
+	for _, d := range discrepancies {
+		fmt.Println(d)
+	}
+	fmt.Printf("verified %d bonds, %d discrepancies\n", len(bonds), len(discrepancies))
+	return len(discrepancies), nil
+}
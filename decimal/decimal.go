@@ -0,0 +1,128 @@
+// Package decimal implements fixed-point decimal arithmetic for amounts and rates,
+// so that values calculated by the issuer can be reproduced exactly.
+package decimal
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Scale is the number of digits after the decimal point a Decimal holds.
+// It is enough for prices in grosze and rates published with 0.0001% precision.
+const Scale = 8
+
+const unit = 100_000_000
+
+var (
+	ErrOverflow = errors.New("decimal overflow")
+)
+
+// Decimal is a fixed-point decimal number with Scale digits after the decimal point,
+// stored as an integer number of 10^-Scale units.
+// Decimals can be added, subtracted and compared with the built-in operators.
+type Decimal int64
+
+// FromFloat returns the decimal closest to f, rounded half away from zero to Scale digits.
+func FromFloat(f float64) Decimal {
+	return Decimal(math.Round(f * unit))
+}
+
+// FromInt returns the decimal equal to i.
+func FromInt(i int64) Decimal {
+	return Decimal(i * unit)
+}
+
+// Float64 returns the float64 closest to d.
+func (d Decimal) Float64() float64 {
+	return float64(d) / unit
+}
+
+// MulInt returns d * n, or ErrOverflow if the product doesn't fit in a Decimal.
+func (d Decimal) MulInt(n int64) (Decimal, error) {
+	product := new(big.Int).Mul(big.NewInt(int64(d)), big.NewInt(n))
+	if !product.IsInt64() {
+		return 0, ErrOverflow
+	}
+	return Decimal(product.Int64()), nil
+}
+
+// Add returns d + o, or ErrOverflow if the sum doesn't fit in a Decimal.
+func (d Decimal) Add(o Decimal) (Decimal, error) {
+	sum := d + o
+	if (sum > d) != (o > 0) {
+		return 0, ErrOverflow
+	}
+	return sum, nil
+}
+
+// Mul returns d * o rounded half away from zero to Scale digits.
+func (d Decimal) Mul(o Decimal) Decimal {
+	product := new(big.Int).Mul(big.NewInt(int64(d)), big.NewInt(int64(o)))
+	return Decimal(quo(product, big.NewInt(unit)))
+}
+
+// MulRatio returns d * num / den rounded half away from zero to places digits after the decimal point.
+// The result is rounded once, so no precision is lost in between.
+func (d Decimal) MulRatio(num, den int64, places int) Decimal {
+	if places < 0 || places > Scale {
+		panic("decimal: invalid number of places")
+	}
+	step := pow10(Scale - places)
+	n := new(big.Int).Mul(big.NewInt(int64(d)), big.NewInt(num))
+	q := new(big.Int).Mul(big.NewInt(den), big.NewInt(step))
+	return Decimal(quo(n, q) * step)
+}
+
+// Round returns d rounded half away from zero to places digits after the decimal point.
+func (d Decimal) Round(places int) Decimal {
+	return d.MulRatio(1, 1, places)
+}
+
+// String formats d without trailing zeros, e.g. 106.8 or -0.05.
+func (d Decimal) String() string {
+	sign := ""
+	u := uint64(d)
+	if d < 0 {
+		sign = "-"
+		u = uint64(-d)
+	}
+	integer := strconv.FormatUint(u/unit, 10)
+	frac := strings.TrimRight(strconv.FormatUint(u%unit+unit, 10)[1:], "0")
+	if frac == "" {
+		return sign + integer
+	}
+	return sign + integer + "." + frac
+}
+
+// quo returns n / den rounded half away from zero.
+// It panics if the quotient doesn't fit in a Decimal, callers bound their operands.
+func quo(n, den *big.Int) int64 {
+	if den.Sign() < 0 {
+		n = new(big.Int).Neg(n)
+		den = new(big.Int).Neg(den)
+	}
+	q, r := new(big.Int).QuoRem(n, den, new(big.Int))
+	r.Abs(r).Lsh(r, 1)
+	if r.Cmp(den) >= 0 {
+		if n.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	if !q.IsInt64() {
+		panic(ErrOverflow)
+	}
+	return q.Int64()
+}
+
+func pow10(n int) int64 {
+	p := int64(1)
+	for range n {
+		p *= 10
+	}
+	return p
+}
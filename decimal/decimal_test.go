@@ -0,0 +1,148 @@
+package decimal_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/maciekmm/obligacje/decimal"
+)
+
+func TestFromFloat(t *testing.T) {
+	tests := []struct {
+		name string
+		f    float64
+		want string
+	}{
+		{name: "price", f: 106.8, want: "106.8"},
+		{name: "rate not representable in binary", f: 0.0575, want: "0.0575"},
+		{name: "negative", f: -0.05, want: "-0.05"},
+		{name: "beyond scale", f: 0.123456789, want: "0.12345679"},
+		{name: "integer", f: 100, want: "100"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decimal.FromFloat(tt.f).String(); got != tt.want {
+				t.Errorf("FromFloat(%v) = %s, want %s", tt.f, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecimal_Mul(t *testing.T) {
+	tests := []struct {
+		name string
+		d, o decimal.Decimal
+		want decimal.Decimal
+	}{
+		{name: "exact", d: decimal.FromFloat(106.8), o: decimal.FromFloat(0.0575), want: decimal.FromFloat(6.141)},
+		{name: "rounded half away from zero", d: decimal.FromFloat(0.00000001), o: decimal.FromFloat(0.5), want: decimal.FromFloat(0.00000001)},
+		{name: "negative", d: decimal.FromFloat(-0.00000001), o: decimal.FromFloat(0.5), want: decimal.FromFloat(-0.00000001)},
+		{name: "large values don't overflow", d: decimal.FromInt(1_000_000), o: decimal.FromInt(1_000), want: decimal.FromInt(1_000_000_000)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.d.Mul(tt.o); got != tt.want {
+				t.Errorf("Mul() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecimal_MulRatio(t *testing.T) {
+	tests := []struct {
+		name     string
+		d        decimal.Decimal
+		num, den int64
+		places   int
+		want     decimal.Decimal
+	}{
+		// 100 * 4.5% / 12 = 0.375 exactly, which a float64 represents as 0.37499999...
+		{name: "half rounded up", d: decimal.FromFloat(4.5), num: 1, den: 12, places: 2, want: decimal.FromFloat(0.38)},
+		{name: "just below half", d: decimal.FromFloat(0.0149999), num: 1, den: 1, places: 2, want: decimal.FromFloat(0.01)},
+		{name: "days held", d: decimal.FromFloat(6.2), num: 31, den: 365, places: 2, want: decimal.FromFloat(0.53)},
+		{name: "negative half rounded away from zero", d: decimal.FromFloat(-0.125), num: 1, den: 1, places: 2, want: decimal.FromFloat(-0.13)},
+		{name: "whole numbers", d: decimal.FromInt(1000), num: 1, den: 3, places: 0, want: decimal.FromInt(333)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.d.MulRatio(tt.num, tt.den, tt.places); got != tt.want {
+				t.Errorf("MulRatio() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecimal_MulInt(t *testing.T) {
+	tests := []struct {
+		name    string
+		d       decimal.Decimal
+		n       int64
+		want    decimal.Decimal
+		wantErr error
+	}{
+		{name: "quantity", d: decimal.FromFloat(106.8), n: 1_000, want: decimal.FromInt(106_800)},
+		{name: "negative", d: decimal.FromFloat(-0.05), n: 3, want: decimal.FromFloat(-0.15)},
+		{name: "overflow", d: decimal.FromFloat(175.6), n: 1_000_000_000, wantErr: decimal.ErrOverflow},
+		{name: "negative overflow", d: decimal.FromFloat(-175.6), n: 100_000_000_000, wantErr: decimal.ErrOverflow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.d.MulInt(tt.n)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("MulInt() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("MulInt() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecimal_Add(t *testing.T) {
+	tests := []struct {
+		name    string
+		d, o    decimal.Decimal
+		want    decimal.Decimal
+		wantErr error
+	}{
+		{name: "sum", d: decimal.FromFloat(106.8), o: decimal.FromFloat(0.05), want: decimal.FromFloat(106.85)},
+		{name: "negative", d: decimal.FromFloat(0.05), o: decimal.FromFloat(-0.15), want: decimal.FromFloat(-0.1)},
+		{name: "overflow", d: math.MaxInt64 - 1, o: 2, wantErr: decimal.ErrOverflow},
+		{name: "negative overflow", d: math.MinInt64 + 1, o: -2, wantErr: decimal.ErrOverflow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.d.Add(tt.o)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Add() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Add() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecimal_String(t *testing.T) {
+	tests := []struct {
+		d    decimal.Decimal
+		want string
+	}{
+		{d: 0, want: "0"},
+		{d: 1, want: "0.00000001"},
+		{d: -150_000_000, want: "-1.5"},
+		{d: decimal.FromInt(100), want: "100"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.d.String(); got != tt.want {
+			t.Errorf("String() = %s, want %s", got, tt.want)
+		}
+	}
+}
@@ -5,6 +5,7 @@ go 1.26.0
 require (
 	github.com/xuri/excelize/v2 v2.10.1
 	golang.org/x/net v0.51.0
+	golang.org/x/text v0.34.0
 )
 
 require (
@@ -14,5 +15,4 @@ require (
 	github.com/xuri/efp v0.0.1 // indirect
 	github.com/xuri/nfp v0.0.2-0.20250530014748-2ddeb826f9a9 // indirect
 	golang.org/x/crypto v0.48.0 // indirect
-	golang.org/x/text v0.34.0 // indirect
 )
@@ -0,0 +1,117 @@
+package index
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/civil"
+)
+
+var (
+	ErrInvalidCPIData = errors.New("invalid CPI data")
+)
+
+type month struct {
+	year  int
+	month time.Month
+}
+
+// Monthly is an index published once per month.
+type Monthly struct {
+	values map[month]bond.Percentage
+}
+
+// At returns the value published for the month of d.
+func (m Monthly) At(d civil.Date) (bond.Percentage, bool) {
+	v, ok := m.values[month{d.Year(), d.Month()}]
+	return v, ok
+}
+
+// Inflation returns the cumulative inflation from the month of from until the month of to,
+// chained from the year-on-year index yoy. Whole years are chained exactly from the readings for to,
+// to a year earlier and so on, while the remaining months assume a constant monthly rate within their year.
+// It reports false if a reading it needs is not known.
+func Inflation(yoy Index, from, to civil.Date) (bond.Percentage, bool) {
+	months := (to.Year()-from.Year())*12 + int(to.Month()-from.Month())
+	if months < 0 {
+		return 0, false
+	}
+
+	level := 1.0
+	at := civil.New(to.Year(), to.Month(), 1)
+	for ; months >= 12; months -= 12 {
+		v, ok := yoy.At(at)
+		if !ok {
+			return 0, false
+		}
+		level *= 1 + float64(v)
+		at = at.AddDate(-1, 0, 0)
+	}
+	if months > 0 {
+		v, ok := yoy.At(at)
+		if !ok {
+			return 0, false
+		}
+		level *= math.Pow(1+float64(v), float64(months)/12)
+	}
+	return bond.Percentage(level - 1), true
+}
+
+// LoadCPI reads the year-on-year consumer price index from a file, see ParseCPI.
+func LoadCPI(path string) (Monthly, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Monthly{}, err
+	}
+	defer f.Close()
+
+	return ParseCPI(f)
+}
+
+// ParseCPI reads the year-on-year consumer price index in the format published by GUS:
+// semicolon separated rows of year, month and the index value with the same month
+// of the previous year equal to 100, e.g. "2025;3;104,9" for 4.9% inflation in March 2025.
+// A header row is skipped. Both decimal commas and points are accepted.
+func ParseCPI(r io.Reader) (Monthly, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = ';'
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return Monthly{}, fmt.Errorf("%w: %w", ErrInvalidCPIData, err)
+	}
+
+	values := make(map[month]bond.Percentage, len(records))
+	for i, record := range records {
+		year, err := strconv.Atoi(record[0])
+		if err != nil {
+			if i == 0 {
+				// header
+				continue
+			}
+			return Monthly{}, fmt.Errorf("%w: invalid year %q in row %d", ErrInvalidCPIData, record[0], i+1)
+		}
+		m, err := strconv.Atoi(record[1])
+		if err != nil || m < 1 || m > 12 {
+			return Monthly{}, fmt.Errorf("%w: invalid month %q in row %d", ErrInvalidCPIData, record[1], i+1)
+		}
+		value, err := strconv.ParseFloat(strings.Replace(record[2], ",", ".", 1), 64)
+		if err != nil {
+			return Monthly{}, fmt.Errorf("%w: invalid value %q in row %d", ErrInvalidCPIData, record[2], i+1)
+		}
+		// snap to 1e-8 to drop binary representation errors, e.g. 104.9-100 = 4.900000000000006
+		values[month{year, time.Month(m)}] = bond.Percentage(math.Round((value-100)*1e6) / 1e8)
+	}
+
+	return Monthly{values: values}, nil
+}
@@ -0,0 +1,114 @@
+package index_test
+
+import (
+	"errors"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/civil"
+	"github.com/maciekmm/obligacje/index"
+	"github.com/maciekmm/obligacje/internal/testutil"
+)
+
+func TestLoadCPI(t *testing.T) {
+	cpi, err := index.LoadCPI(filepath.Join(testutil.TestDataDirectory(), "cpi.csv"))
+	if err != nil {
+		t.Fatalf("LoadCPI() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		at     civil.Date
+		want   bond.Percentage
+		wantOk bool
+	}{
+		{name: "first day of month", at: civil.New(2025, time.March, 1), want: 0.049, wantOk: true},
+		{name: "last day of month", at: civil.New(2023, time.February, 28), want: 0.184, wantOk: true},
+		{name: "not published", at: civil.New(2030, time.January, 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := cpi.At(tt.at)
+			if ok != tt.wantOk {
+				t.Fatalf("At() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if math.Abs(float64(got-tt.want)) > 1e-9 {
+				t.Errorf("At() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCPI(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    bond.Percentage
+		wantErr error
+	}{
+		{name: "decimal point without header", data: "2020;1;104.4\n", want: 0.044},
+		{name: "deflation", data: "rok;miesiąc;wartość\n2020;1;99,2\n", want: -0.008},
+		{name: "invalid month", data: "2020;13;104,4\n", wantErr: index.ErrInvalidCPIData},
+		{name: "invalid value", data: "2020;1;abc\n", wantErr: index.ErrInvalidCPIData},
+		{name: "missing column", data: "2020;1\n", wantErr: index.ErrInvalidCPIData},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cpi, err := index.ParseCPI(strings.NewReader(tt.data))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseCPI() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			got, ok := cpi.At(civil.New(2020, time.January, 15))
+			if !ok {
+				t.Fatal("At() ok = false, want true")
+			}
+			if math.Abs(float64(got-tt.want)) > 1e-9 {
+				t.Errorf("At() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInflation(t *testing.T) {
+	cpi, err := index.ParseCPI(strings.NewReader("2023;6;110,0\n2024;6;105,0\n2025;6;104,0\n"))
+	if err != nil {
+		t.Fatalf("ParseCPI() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		from   civil.Date
+		to     civil.Date
+		want   float64
+		wantOk bool
+	}{
+		{name: "same month", from: civil.New(2025, time.June, 1), to: civil.New(2025, time.June, 30), want: 0, wantOk: true},
+		{name: "whole year", from: civil.New(2024, time.June, 15), to: civil.New(2025, time.June, 1), want: 0.04, wantOk: true},
+		{name: "whole years chained", from: civil.New(2023, time.June, 1), to: civil.New(2025, time.June, 1), want: 1.05*1.04 - 1, wantOk: true},
+		{name: "part of a year", from: civil.New(2024, time.December, 1), to: civil.New(2025, time.June, 1), want: math.Sqrt(1.04) - 1, wantOk: true},
+		{name: "whole and part of a year", from: civil.New(2023, time.December, 1), to: civil.New(2025, time.June, 1), want: 1.04*math.Sqrt(1.05) - 1, wantOk: true},
+		{name: "reading missing", from: civil.New(2022, time.May, 1), to: civil.New(2025, time.June, 1)},
+		{name: "backwards", from: civil.New(2025, time.June, 1), to: civil.New(2024, time.June, 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := index.Inflation(cpi, tt.from, tt.to)
+			if ok != tt.wantOk {
+				t.Fatalf("Inflation() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if math.Abs(float64(got)-tt.want) > 1e-9 {
+				t.Errorf("Inflation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
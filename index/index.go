@@ -0,0 +1,14 @@
+// Package index provides market indices that rates of floating rate bonds are linked to.
+package index
+
+import (
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/civil"
+)
+
+// Index is a market index whose value changes over time, e.g. the consumer price index.
+type Index interface {
+	// At returns the value of the index in effect at the given date.
+	// It reports false if the value is not known.
+	At(d civil.Date) (bond.Percentage, bool)
+}
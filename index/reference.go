@@ -0,0 +1,106 @@
+package index
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/civil"
+)
+
+var (
+	ErrInvalidReferenceRateData = errors.New("invalid reference rate data")
+)
+
+type change struct {
+	effective civil.Date
+	value     bond.Percentage
+}
+
+// Stepwise is an index changed by decisions taking effect on given dates, such as the NBP reference rate.
+// Values are known up to a date, since a change may be announced at any time.
+type Stepwise struct {
+	changes []change
+	// until returns the date the index is known until, asked on every lookup.
+	until func() civil.Date
+}
+
+// At returns the value in effect at d.
+// It reports false for dates before the first change or after the index is known until.
+func (s Stepwise) At(d civil.Date) (bond.Percentage, bool) {
+	if s.until == nil || d.After(s.until()) {
+		return 0, false
+	}
+	i, found := slices.BinarySearchFunc(s.changes, d, func(c change, d civil.Date) int {
+		return c.effective.Compare(d)
+	})
+	if !found {
+		i--
+	}
+	if i < 0 {
+		return 0, false
+	}
+	return s.changes[i].value, true
+}
+
+// LoadReferenceRate reads the NBP reference rate history from a file, see ParseReferenceRate.
+// The history is assumed to be up to date, i.e. known until today, including the days passed since loading.
+func LoadReferenceRate(path string) (Stepwise, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Stepwise{}, err
+	}
+	defer f.Close()
+
+	return ParseReferenceRate(f, civil.Today)
+}
+
+// ParseReferenceRate reads the NBP reference rate history known until the date returned by until,
+// which is called on every lookup:
+// semicolon separated rows of the date a rate takes effect and the yearly rate in percent,
+// e.g. "2025-05-08;5,25". A header row is skipped. Both decimal commas and points are accepted.
+func ParseReferenceRate(r io.Reader, until func() civil.Date) (Stepwise, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = ';'
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return Stepwise{}, fmt.Errorf("%w: %w", ErrInvalidReferenceRateData, err)
+	}
+
+	changes := make([]change, 0, len(records))
+	for i, record := range records {
+		effective, err := civil.Parse(record[0])
+		if err != nil {
+			if i == 0 {
+				// header
+				continue
+			}
+			return Stepwise{}, fmt.Errorf("%w: invalid date %q in row %d", ErrInvalidReferenceRateData, record[0], i+1)
+		}
+		value, err := strconv.ParseFloat(strings.Replace(record[1], ",", ".", 1), 64)
+		if err != nil {
+			return Stepwise{}, fmt.Errorf("%w: invalid rate %q in row %d", ErrInvalidReferenceRateData, record[1], i+1)
+		}
+		changes = append(changes, change{
+			effective: effective,
+			// snap to 1e-8 to drop binary representation errors of value/100
+			value: bond.Percentage(math.Round(value*1e6) / 1e8),
+		})
+	}
+
+	slices.SortFunc(changes, func(a, b change) int {
+		return a.effective.Compare(b.effective)
+	})
+
+	return Stepwise{changes: changes, until: until}, nil
+}
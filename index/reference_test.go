@@ -0,0 +1,112 @@
+package index_test
+
+import (
+	"errors"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/civil"
+	"github.com/maciekmm/obligacje/index"
+	"github.com/maciekmm/obligacje/internal/testutil"
+)
+
+func TestLoadReferenceRate(t *testing.T) {
+	rate, err := index.LoadReferenceRate(filepath.Join(testutil.TestDataDirectory(), "nbp.csv"))
+	if err != nil {
+		t.Fatalf("LoadReferenceRate() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		at     civil.Date
+		want   bond.Percentage
+		wantOk bool
+	}{
+		{name: "day before a change", at: civil.New(2025, time.May, 7), want: 0.0575, wantOk: true},
+		{name: "day of a change", at: civil.New(2025, time.May, 8), want: 0.0525, wantOk: true},
+		{name: "long after a change", at: civil.New(2024, time.June, 30), want: 0.0575, wantOk: true},
+		{name: "before history", at: civil.New(2010, time.January, 1)},
+		{name: "future", at: civil.Today().AddDate(0, 1, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := rate.At(tt.at)
+			if ok != tt.wantOk {
+				t.Fatalf("At() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if math.Abs(float64(got-tt.want)) > 1e-9 {
+				t.Errorf("At() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseReferenceRate(t *testing.T) {
+	until := civil.New(2021, time.January, 1)
+
+	tests := []struct {
+		name    string
+		data    string
+		at      civil.Date
+		want    bond.Percentage
+		wantOk  bool
+		wantErr error
+	}{
+		{
+			name:   "unsorted without header",
+			data:   "2020-05-29;0.10\n2020-04-09;0.50\n",
+			at:     civil.New(2020, time.May, 1),
+			want:   0.005,
+			wantOk: true,
+		},
+		{
+			name: "after known until",
+			data: "data;stopa\n2020-05-29;0,10\n",
+			at:   civil.New(2021, time.January, 2),
+		},
+		{name: "invalid date", data: "2020-05-29;0,10\n2020-13-01;0,10\n", wantErr: index.ErrInvalidReferenceRateData},
+		{name: "invalid rate", data: "2020-05-29;abc\n", wantErr: index.ErrInvalidReferenceRateData},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, err := index.ParseReferenceRate(strings.NewReader(tt.data), func() civil.Date { return until })
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseReferenceRate() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			got, ok := rate.At(tt.at)
+			if ok != tt.wantOk {
+				t.Fatalf("At() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if math.Abs(float64(got-tt.want)) > 1e-9 {
+				t.Errorf("At() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseReferenceRate_KnownUntilLookup(t *testing.T) {
+	today := civil.New(2025, time.May, 7)
+	rate, err := index.ParseReferenceRate(strings.NewReader("2025-05-08;5,25\n2024-06-01;5,75\n"), func() civil.Date { return today })
+	if err != nil {
+		t.Fatalf("ParseReferenceRate() error = %v", err)
+	}
+	if _, ok := rate.At(civil.New(2025, time.May, 8)); ok {
+		t.Fatal("At() ok = true the day before the change, want the rate unknown")
+	}
+
+	// a day passes without reloading the history
+	today = today.AddDate(0, 0, 1)
+	got, ok := rate.At(civil.New(2025, time.May, 8))
+	if !ok || math.Abs(float64(got-0.0525)) > 1e-9 {
+		t.Errorf("At() = %v, %v, want 0.0525 known the day after loading", got, ok)
+	}
+}
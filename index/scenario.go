@@ -0,0 +1,61 @@
+package index
+
+import (
+	"slices"
+
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/civil"
+)
+
+// Constant is an index with the same value at all times, e.g. an assumed future inflation.
+type Constant bond.Percentage
+
+func (c Constant) At(d civil.Date) (bond.Percentage, bool) {
+	return bond.Percentage(c), true
+}
+
+// Yearly is an index curve with a value per calendar year.
+// Years past the last one keep its value, years before the first one are unknown.
+type Yearly map[int]bond.Percentage
+
+func (y Yearly) At(d civil.Date) (bond.Percentage, bool) {
+	years := make([]int, 0, len(y))
+	for year := range y {
+		if year <= d.Year() {
+			years = append(years, year)
+		}
+	}
+	if len(years) == 0 {
+		return 0, false
+	}
+	return y[slices.Max(years)], true
+}
+
+type fallback []Index
+
+func (f fallback) At(d civil.Date) (bond.Percentage, bool) {
+	for _, idx := range f {
+		if v, ok := idx.At(d); ok {
+			return v, true
+		}
+	}
+	return 0, false
+}
+
+// Fallback returns an index taking the value from the first of indices that knows it.
+// Nil indices are skipped and nil is returned if there are none left.
+func Fallback(indices ...Index) Index {
+	var f fallback
+	for _, idx := range indices {
+		if idx != nil {
+			f = append(f, idx)
+		}
+	}
+	switch len(f) {
+	case 0:
+		return nil
+	case 1:
+		return f[0]
+	}
+	return f
+}
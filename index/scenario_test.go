@@ -0,0 +1,72 @@
+package index_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/civil"
+	"github.com/maciekmm/obligacje/index"
+)
+
+func TestYearly(t *testing.T) {
+	curve := index.Yearly{2026: 0.03, 2028: 0.025}
+
+	tests := []struct {
+		name   string
+		year   int
+		want   bond.Percentage
+		wantOk bool
+	}{
+		{name: "before the curve", year: 2025},
+		{name: "first year", year: 2026, want: 0.03, wantOk: true},
+		{name: "gap keeps the previous year", year: 2027, want: 0.03, wantOk: true},
+		{name: "past the curve keeps the last year", year: 2035, want: 0.025, wantOk: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := curve.At(civil.New(tt.year, time.June, 1))
+			if ok != tt.wantOk {
+				t.Fatalf("At() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if math.Abs(float64(got-tt.want)) > 1e-9 {
+				t.Errorf("At() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFallback(t *testing.T) {
+	cpi, err := index.ParseCPI(strings.NewReader("2025;1;105,3\n"))
+	if err != nil {
+		t.Fatalf("ParseCPI() error = %v", err)
+	}
+
+	if got := index.Fallback(nil, nil); got != nil {
+		t.Errorf("Fallback() of nil indices = %v, want nil", got)
+	}
+
+	idx := index.Fallback(cpi, nil, index.Constant(0.03))
+	tests := []struct {
+		name string
+		at   civil.Date
+		want bond.Percentage
+	}{
+		{name: "known", at: civil.New(2025, time.January, 1), want: 0.053},
+		{name: "falls back", at: civil.New(2025, time.February, 1), want: 0.03},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := idx.At(tt.at)
+			if !ok {
+				t.Fatal("At() ok = false, want true")
+			}
+			if math.Abs(float64(got-tt.want)) > 1e-9 {
+				t.Errorf("At() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package obligacje
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/civil"
+	"github.com/maciekmm/obligacje/index"
+	"github.com/maciekmm/obligacje/internal/periodical"
+)
+
+// IndexSource keeps a market index loaded from a local file up to date,
+// so that the file can be replaced without restarting the server.
+type IndexSource struct {
+	indexLoader *periodical.Loader[index.Index]
+}
+
+// NewCPISource loads the year-on-year CPI from a file in the format described in index.ParseCPI.
+func NewCPISource(logger *slog.Logger, file string) (*IndexSource, error) {
+	return newIndexSource(logger, file, func(file string) (index.Index, error) {
+		return index.LoadCPI(file)
+	})
+}
+
+// NewReferenceRateSource loads the NBP reference rate history from a file in the format described in index.ParseReferenceRate.
+func NewReferenceRateSource(logger *slog.Logger, file string) (*IndexSource, error) {
+	return newIndexSource(logger, file, func(file string) (index.Index, error) {
+		return index.LoadReferenceRate(file)
+	})
+}
+
+func newIndexSource(logger *slog.Logger, file string, load func(string) (index.Index, error)) (*IndexSource, error) {
+	loadFn := func() (index.Index, error) {
+		idx, err := load(file)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("loaded index", "file", file)
+		return idx, nil
+	}
+
+	indexLoader, err := periodical.NewLoader(12*time.Hour, loadFn, periodical.ErrBehaviorKeepOld)
+	if err != nil {
+		return nil, fmt.Errorf("initial index load failed: %w", err)
+	}
+
+	return &IndexSource{
+		indexLoader: indexLoader,
+	}, nil
+}
+
+func (s *IndexSource) Close() error {
+	s.indexLoader.Stop()
+	return nil
+}
+
+func (s *IndexSource) At(d civil.Date) (bond.Percentage, bool) {
+	cur, err := s.indexLoader.Current()
+	if err != nil {
+		return 0, false
+	}
+	return cur.At(d)
+}
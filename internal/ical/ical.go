@@ -0,0 +1,85 @@
+// Package ical writes calendars of all-day events in the iCalendar format (RFC 5545).
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/maciekmm/obligacje/civil"
+)
+
+// maxLineLength is the length of content lines in octets, longer lines are folded.
+const maxLineLength = 75
+
+// Event is an all-day event.
+type Event struct {
+	// UID identifies the event across updates of the calendar, so it should be stable.
+	UID         string
+	Date        civil.Date
+	Summary     string
+	Description string
+}
+
+type Calendar struct {
+	Name   string
+	Events []Event
+	// Stamp is the time the calendar was generated at.
+	Stamp time.Time
+}
+
+// Write writes the calendar in the iCalendar format.
+func (c Calendar) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeLine(bw, name+":"+value)
+	}
+
+	stamp := c.Stamp.UTC().Format("20060102T150405Z")
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//obligacje//bond events//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("X-WR-CALNAME", escape(c.Name))
+	for _, e := range c.Events {
+		line("BEGIN", "VEVENT")
+		line("UID", escape(e.UID))
+		line("DTSTAMP", stamp)
+		line("DTSTART;VALUE=DATE", e.Date.Format("20060102"))
+		line("DTEND;VALUE=DATE", e.Date.AddDate(0, 0, 1).Format("20060102"))
+		line("SUMMARY", escape(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION", escape(e.Description))
+		}
+		line("TRANSP", "TRANSPARENT")
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+// escape escapes a text value.
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+// writeLine writes a content line terminated with CRLF, folding it into lines of at most maxLineLength octets
+// without splitting UTF-8 characters.
+func writeLine(w *bufio.Writer, s string) {
+	limit := maxLineLength
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		w.WriteString(s[:cut])
+		w.WriteString("\r\n ")
+		s = s[cut:]
+		// continuation lines start with a space
+		limit = maxLineLength - 1
+	}
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
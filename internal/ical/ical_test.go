@@ -0,0 +1,62 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maciekmm/obligacje/civil"
+)
+
+func TestCalendar_Write(t *testing.T) {
+	c := Calendar{
+		Name: "EDO083401",
+		Events: []Event{{
+			UID:         "EDO083401-maturity-9@obligacje",
+			Date:        civil.New(2034, time.August, 1),
+			Summary:     "EDO083401: maturity",
+			Description: "Redemption; interest, face value\npaid out",
+		}},
+		Stamp: time.Date(2025, time.November, 1, 12, 30, 0, 0, time.UTC),
+	}
+
+	var buf bytes.Buffer
+	if err := c.Write(&buf); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	got := buf.String()
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"DTSTAMP:20251101T123000Z\r\n",
+		"DTSTART;VALUE=DATE:20340801\r\n",
+		"DTEND;VALUE=DATE:20340802\r\n",
+		`DESCRIPTION:Redemption\; interest\, face value\npaid out` + "\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Write() = %q, want it to contain %q", got, want)
+		}
+	}
+}
+
+func TestWriteLine_Folds(t *testing.T) {
+	var buf bytes.Buffer
+	c := Calendar{Events: []Event{{Summary: strings.Repeat("ż", 100)}}}
+	if err := c.Write(&buf); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		if len(line) > maxLineLength {
+			t.Errorf("line of %d octets, want at most %d: %q", len(line), maxLineLength, line)
+		}
+		if !strings.HasPrefix(line, " ") && strings.Contains(line, "ż") && !strings.HasPrefix(line, "SUMMARY:") {
+			t.Errorf("unfolded continuation line %q", line)
+		}
+	}
+	if unfolded := strings.ReplaceAll(buf.String(), "\r\n ", ""); !strings.Contains(unfolded, "SUMMARY:"+strings.Repeat("ż", 100)+"\r\n") {
+		t.Errorf("Write() folded summary doesn't unfold to the original")
+	}
+}
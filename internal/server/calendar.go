@@ -0,0 +1,147 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/maciekmm/obligacje/calculator"
+	"github.com/maciekmm/obligacje/internal/ical"
+	"github.com/maciekmm/obligacje/portfolio"
+)
+
+// maxCalendarBonds limits the number of bonds of portfolio calendars passed in the query.
+const maxCalendarBonds = 100
+
+func (s *Server) handleCalendar(w http.ResponseWriter, r *http.Request) {
+	bnd, purchaseDay, ok := s.lookupPurchasedBond(w, r)
+	if !ok {
+		return
+	}
+
+	events, err := s.calc.Calendar(s.repo, bnd, purchaseDay)
+	if err != nil {
+		s.log.Warn("error calculating calendar", "name", bnd.Name, "purchase_day", purchaseDay, "err", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	h := portfolio.Holding{Series: bnd.Name, PurchaseDay: purchaseDay}
+	calendar := ical.Calendar{
+		Name:   "Obligacje " + h.Name(),
+		Events: make([]ical.Event, len(events)),
+		Stamp:  time.Now(),
+	}
+	for i, e := range events {
+		calendar.Events[i] = calendarEvent(h, e)
+	}
+
+	s.log.Info("bond calendar", "name", bnd.Name, "purchase_day", purchaseDay, "events_no", len(events))
+
+	writeCalendar(w, calendar)
+}
+
+// handlePortfolioCalendar serves the calendar of a portfolio passed in the body of POST requests or,
+// so that calendar applications can subscribe to it, as bond names in the query of GET requests.
+func (s *Server) handlePortfolioCalendar(w http.ResponseWriter, r *http.Request) {
+	var p portfolio.Portfolio
+	if r.Method == http.MethodGet {
+		names := r.URL.Query()["bond"]
+		if len(names) == 0 {
+			http.Error(w, "missing bond", http.StatusBadRequest)
+			return
+		}
+		if len(names) > maxCalendarBonds {
+			http.Error(w, fmt.Sprintf("at most %d bonds are allowed", maxCalendarBonds), http.StatusBadRequest)
+			return
+		}
+		for _, name := range names {
+			purchaseDay, err := extractPurchaseDayFromName(name)
+			if err != nil {
+				s.log.Info("invalid name", "name", name, "err", err)
+				http.Error(w, "invalid bond "+name, http.StatusBadRequest)
+				return
+			}
+			p.Holdings = append(p.Holdings, portfolio.Holding{Series: name[:len(name)-2], PurchaseDay: purchaseDay})
+		}
+	} else {
+		var ok bool
+		if p, ok = s.portfolioFromBody(w, r); !ok {
+			return
+		}
+	}
+
+	events, err := portfolio.Calendar(s.repo, s.calc, p)
+	if !s.checkPortfolioError(w, err) {
+		return
+	}
+
+	calendar := ical.Calendar{
+		Name:   "Obligacje",
+		Events: make([]ical.Event, len(events)),
+		Stamp:  time.Now(),
+	}
+	for i, e := range events {
+		calendar.Events[i] = calendarEvent(e.Holding, e.Event)
+	}
+
+	s.log.Info("portfolio calendar", "holdings_no", len(p.Holdings), "events_no", len(events))
+
+	writeCalendar(w, calendar)
+}
+
+func writeCalendar(w http.ResponseWriter, calendar ical.Calendar) {
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	calendar.Write(w)
+}
+
+func calendarEvent(h portfolio.Holding, e calculator.CalendarEvent) ical.Event {
+	name := h.Name()
+	uid := name
+	if h.Account != "" {
+		uid += "-" + h.Account
+	}
+	// periods are numbered from one for people
+	period := e.Period + 1
+
+	var summary string
+	var description []string
+	switch e.Kind {
+	case calculator.CalendarCoupon:
+		summary = fmt.Sprintf("%s: coupon of period %d", name, period)
+		description = append(description, fmt.Sprintf("The interest of period %d is paid out.", period))
+	case calculator.CalendarFeeFreeEnd:
+		summary = fmt.Sprintf("%s: last day to redeem without fee", name)
+		description = append(description, "No interest the early redemption fee could be taken from has accrued yet, so the bonds can be redeemed early without the fee until today.")
+	case calculator.CalendarRateReset:
+		summary = fmt.Sprintf("%s: rate reset", name)
+		description = append(description, fmt.Sprintf("A new interest rate applies from period %d.", period))
+	case calculator.CalendarExchangeDeadline:
+		summary = fmt.Sprintf("%s: last day to order exchange", name)
+		description = append(description, "The bonds mature tomorrow. Order the exchange (zamiana) into the issue on sale at maturity today.")
+		if h.ExchangedInto != "" {
+			description = append(description, "Planned exchange into "+h.ExchangedInto+".")
+		}
+	case calculator.CalendarMaturity:
+		summary = fmt.Sprintf("%s: maturity", name)
+		description = append(description, "The bonds are redeemed together with the interest not paid out yet.")
+		if h.ExchangedInto != "" {
+			description = append(description, "Exchanged into "+h.ExchangedInto+".")
+		}
+	}
+	if h.Quantity > 0 {
+		description = append(description, fmt.Sprintf("Quantity: %d.", h.Quantity))
+	}
+	if h.Account != "" {
+		description = append(description, "Account: "+h.Account+".")
+	}
+
+	return ical.Event{
+		UID:         fmt.Sprintf("%s-%s-%d@obligacje", uid, e.Kind, e.Period),
+		Date:        e.Date,
+		Summary:     summary,
+		Description: strings.Join(description, "\n"),
+	}
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandleCalendar(t *testing.T) {
+	server := loadTestServer(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/bond/COI052815/calendar", nil)
+	w := httptest.NewRecorder()
+
+	server.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/calendar") {
+		t.Errorf("got content type %s, want text/calendar", got)
+	}
+
+	body := w.Body.String()
+	// three coupons, three rate resets, the ends of the windows without the fee after the purchase
+	// and the first coupon, the exchange deadline and the maturity
+	if got := strings.Count(body, "BEGIN:VEVENT"); got != 10 {
+		t.Errorf("got %d events, want 10", got)
+	}
+	for _, want := range []string{
+		"UID:COI052815-coupon-0@obligacje\r\n",
+		"SUMMARY:COI052815: last day to redeem without fee\r\n",
+		"SUMMARY:COI052815: last day to order exchange\r\nDESCRIPTION:",
+		"DTSTART;VALUE=DATE:20280514\r\n",
+		"UID:COI052815-maturity-3@obligacje\r\nDTSTAMP:",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("got calendar without %q", want)
+		}
+	}
+}
+
+func TestHandlePortfolioCalendar(t *testing.T) {
+	server := loadTestServer(t)
+
+	tests := []struct {
+		name       string
+		method     string
+		url        string
+		body       string
+		wantEvents int
+	}{
+		// 3 events of TOS and 12 of EDO
+		{name: "query", method: http.MethodGet, url: "/v1/portfolio/calendar?bond=TOS112501&bond=EDO083401", wantEvents: 15},
+		// 12 events of EDO, 10 of COI, 3 of TOS and 25 of ROR, with windows without the fee of periods with known rates
+		{name: "body", method: http.MethodPost, url: "/v1/portfolio/calendar", body: testPortfolio, wantEvents: 12 + 10 + 3 + 25},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			server.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("got status %d, want 200; body: %s", w.Code, w.Body.String())
+			}
+			if got := strings.Count(w.Body.String(), "BEGIN:VEVENT"); got != tt.wantEvents {
+				t.Errorf("got %d events, want %d", got, tt.wantEvents)
+			}
+		})
+	}
+}
+
+func TestHandlePortfolioCalendar_Errors(t *testing.T) {
+	server := loadTestServer(t)
+
+	tests := []struct {
+		name     string
+		method   string
+		url      string
+		body     string
+		wantCode int
+	}{
+		{name: "missing bond", method: http.MethodGet, url: "/v1/portfolio/calendar", wantCode: http.StatusBadRequest},
+		{name: "invalid bond", method: http.MethodGet, url: "/v1/portfolio/calendar?bond=EDO", wantCode: http.StatusBadRequest},
+		{name: "unknown bond", method: http.MethodGet, url: "/v1/portfolio/calendar?bond=XYZ999901", wantCode: http.StatusBadRequest},
+		{name: "invalid purchase day", method: http.MethodGet, url: "/v1/portfolio/calendar?bond=TOS112531", wantCode: http.StatusBadRequest},
+		{name: "malformed body", method: http.MethodPost, url: "/v1/portfolio/calendar", body: `{"holdings": [`, wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			server.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("got status %d, want %d; body: %s", w.Code, tt.wantCode, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+type CashFlow struct {
+	Period      int      `json:"period"`
+	Start       string   `json:"start"`
+	End         string   `json:"end"`
+	Rate        *float64 `json:"rate"`
+	RateKnown   bool     `json:"rate_known"`
+	RateDerived bool     `json:"rate_derived"`
+	Interest    *float64 `json:"interest"`
+	PaidOut     bool     `json:"paid_out"`
+	Payment     *float64 `json:"payment"`
+}
+
+type CashFlowsResponse struct {
+	Name         string     `json:"name"`
+	ISIN         string     `json:"isin"`
+	PurchaseDate string     `json:"purchase_date"`
+	MaturityDate string     `json:"maturity_date"`
+	Periods      []CashFlow `json:"periods"`
+	Redemption   *float64   `json:"redemption"`
+	Currency     string     `json:"currency"`
+}
+
+func (s *Server) handleCashFlows(w http.ResponseWriter, r *http.Request) {
+	bnd, purchaseDay, ok := s.lookupPurchasedBond(w, r)
+	if !ok {
+		return
+	}
+
+	schedule, err := s.calc.CashFlows(bnd, purchaseDay)
+	if err != nil {
+		s.log.Warn("error calculating cash flows", "name", bnd.Name, "purchase_day", purchaseDay, "err", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	periods := make([]CashFlow, 0, len(schedule.Periods))
+	for _, p := range schedule.Periods {
+		flow := CashFlow{
+			Period:      p.Index + 1,
+			Start:       p.Start.Format("2006-01-02"),
+			End:         p.End.Format("2006-01-02"),
+			PaidOut:     p.PaidOut,
+			RateKnown:   p.RateKnown,
+			RateDerived: p.RateDerived,
+		}
+		// amounts of periods with unknown rates are reported as null rather than zero
+		if p.RateKnown {
+			rate, interest, payment := float64(p.Rate), float64(p.Interest), float64(p.Payment)
+			flow.Rate, flow.Interest, flow.Payment = &rate, &interest, &payment
+		}
+		periods = append(periods, flow)
+	}
+
+	resp := CashFlowsResponse{
+		Name:         r.PathValue("name"),
+		ISIN:         bnd.ISIN,
+		PurchaseDate: schedule.PurchaseDate.Format("2006-01-02"),
+		MaturityDate: schedule.MaturityDate.Format("2006-01-02"),
+		Periods:      periods,
+		Currency:     "PLN",
+	}
+	if schedule.RedemptionKnown {
+		redemption := float64(schedule.Redemption)
+		resp.Redemption = &redemption
+	}
+
+	s.log.Info("calculated cash flows", "name", bnd.Name, "purchase_day", purchaseDay, "periods", len(periods))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
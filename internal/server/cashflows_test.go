@@ -0,0 +1,186 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/calculator"
+	"github.com/maciekmm/obligacje/index"
+	"github.com/maciekmm/obligacje/internal/testutil"
+)
+
+// unpublishedRepository hides all but the first interest rate of bonds,
+// as if the issuer hasn't published the following ones yet.
+type unpublishedRepository struct {
+	bond.Repository
+}
+
+func (r unpublishedRepository) Lookup(name string) (bond.Bond, error) {
+	bnd, err := r.Repository.Lookup(name)
+	if err != nil {
+		return bond.Bond{}, err
+	}
+	bnd.InterestPeriods = bnd.InterestPeriods[:min(len(bnd.InterestPeriods), 1)]
+	return bnd, nil
+}
+
+func TestHandleCashFlows_DerivedRates(t *testing.T) {
+	cpi, err := index.LoadCPI(testutil.CPIDataFile())
+	if err != nil {
+		t.Fatalf("failed to load CPI: %v", err)
+	}
+	server := NewServer(unpublishedRepository{loadTestServer(t).repo}, slog.Default(), calculator.WithInflation(cpi))
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/bond/COI052815/cashflows", nil)
+	w := httptest.NewRecorder()
+
+	server.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+
+	var resp CashFlowsResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode JSON: %v", err)
+	}
+
+	if len(resp.Periods) != 4 {
+		t.Fatalf("got %d periods, want 4", len(resp.Periods))
+	}
+	if resp.Periods[0].RateDerived {
+		t.Error("expected first period rate to be official")
+	}
+	second := resp.Periods[1]
+	if !second.RateKnown || !second.RateDerived {
+		t.Errorf("expected derived second period rate, got %+v", second)
+	}
+	if second.Payment == nil || math.Abs(*second.Payment-6.15) > 1e-9 {
+		t.Errorf("got second payment %v, want 6.15", second.Payment)
+	}
+	if resp.Periods[2].RateKnown {
+		t.Error("expected third period rate to be unknown without CPI for March 2026")
+	}
+}
+
+func TestHandleCashFlows(t *testing.T) {
+	server := loadTestServer(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/bond/COI052815/cashflows", nil)
+	w := httptest.NewRecorder()
+
+	server.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("got Content-Type %q, want application/json", ct)
+	}
+
+	var resp CashFlowsResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode JSON: %v", err)
+	}
+
+	if resp.PurchaseDate != "2024-05-15" {
+		t.Errorf("got purchase_date %q, want 2024-05-15", resp.PurchaseDate)
+	}
+	if resp.MaturityDate != "2028-05-15" {
+		t.Errorf("got maturity_date %q, want 2028-05-15", resp.MaturityDate)
+	}
+	if len(resp.Periods) != 4 {
+		t.Fatalf("got %d periods, want 4", len(resp.Periods))
+	}
+
+	first := resp.Periods[0]
+	if first.Start != "2024-05-15" || first.End != "2025-05-15" {
+		t.Errorf("got first period %s - %s, want 2024-05-15 - 2025-05-15", first.Start, first.End)
+	}
+	if !first.PaidOut {
+		t.Error("expected COI coupon to be paid out")
+	}
+	if first.Payment == nil || math.Abs(*first.Payment-6.55) > 1e-9 {
+		t.Errorf("got first payment %v, want 6.55", first.Payment)
+	}
+
+	last := resp.Periods[3]
+	if last.RateKnown || last.Rate != nil || last.Payment != nil {
+		t.Errorf("expected unknown last period, got %+v", last)
+	}
+	if resp.Redemption != nil {
+		t.Errorf("expected unknown redemption, got %v", *resp.Redemption)
+	}
+}
+
+func TestHandleCashFlows_Redemption(t *testing.T) {
+	server := loadTestServer(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/bond/TOS072815/cashflows", nil)
+	w := httptest.NewRecorder()
+
+	server.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+
+	var resp CashFlowsResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode JSON: %v", err)
+	}
+
+	if resp.Redemption == nil || math.Abs(*resp.Redemption-117.93) > 1e-9 {
+		t.Errorf("got redemption %v, want 117.93", resp.Redemption)
+	}
+	for _, p := range resp.Periods {
+		if p.PaidOut {
+			t.Errorf("period %d: expected TOS interest to be capitalised", p.Period)
+		}
+	}
+}
+
+func TestHandleCashFlows_Errors(t *testing.T) {
+	server := loadTestServer(t)
+
+	tests := []struct {
+		name     string
+		bondName string
+		wantCode int
+	}{
+		{
+			name:     "bond not found",
+			bondName: "NONEXIST01",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "missing purchase day",
+			bondName: "COI05XX",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid purchase day",
+			bondName: "COI052800",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/v1/bond/%s/cashflows", tt.bondName), nil)
+			w := httptest.NewRecorder()
+
+			server.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("got status %d, want %d; body: %s", w.Code, tt.wantCode, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/calculator"
+	"github.com/maciekmm/obligacje/civil"
+)
+
+type ComparisonResponse struct {
+	PurchasedAt string              `json:"purchased_at"`
+	Horizon     string              `json:"horizon"`
+	Bonds       []CandidateResponse `json:"bonds"`
+	Currency    string              `json:"currency"`
+}
+
+type CandidateResponse struct {
+	Name            string  `json:"name"`
+	ISIN            string  `json:"isin"`
+	MaturityDate    string  `json:"maturity_date"`
+	ValuatedAt      string  `json:"valuated_at"`
+	Price           float64 `json:"price"`
+	PaidCoupons     float64 `json:"paid_coupons"`
+	GrossValue      float64 `json:"gross_value"`
+	EarlyRedemption bool    `json:"early_redemption"`
+	Fee             float64 `json:"fee"`
+	Tax             float64 `json:"tax"`
+	Payout          float64 `json:"payout"`
+	NetPaidCoupons  float64 `json:"net_paid_coupons"`
+	NetValue        float64 `json:"net_value"`
+	DerivedRates    bool    `json:"derived_rates"`
+}
+
+func (s *Server) handleCompare(w http.ResponseWriter, r *http.Request) {
+	horizonQ := r.URL.Query().Get("horizon")
+	if horizonQ == "" {
+		http.Error(w, "missing horizon", http.StatusBadRequest)
+		return
+	}
+	horizon, err := civil.Parse(horizonQ)
+	if err != nil {
+		http.Error(w, "invalid horizon", http.StatusBadRequest)
+		return
+	}
+
+	purchasedAt := civil.Today()
+	if purchasedQ := r.URL.Query().Get("purchased_at"); purchasedQ != "" {
+		purchasedAt, err = civil.Parse(purchasedQ)
+		if err != nil {
+			http.Error(w, "invalid purchased_at", http.StatusBadRequest)
+			return
+		}
+	}
+	if !horizon.After(purchasedAt) {
+		http.Error(w, "horizon must be after purchased_at", http.StatusBadRequest)
+		return
+	}
+
+	taxation, ok := taxationFromQuery(w, r)
+	if !ok {
+		return
+	}
+
+	var scenario calculator.Indices
+	if scenario.Inflation, ok = constantFromQuery(w, r, "inflation"); !ok {
+		return
+	}
+	if scenario.ReferenceRate, ok = constantFromQuery(w, r, "reference_rate"); !ok {
+		return
+	}
+
+	candidates, ok := s.candidatesFromQuery(w, r, purchasedAt)
+	if !ok {
+		return
+	}
+
+	comparisons, err := s.calc.WithScenario(scenario).Compare(candidates, purchasedAt, horizon, taxation)
+	if errors.Is(err, calculator.ErrValuationDateAfterMaturity) {
+		s.log.Info("comparison past known rates", "purchased_at", purchasedAt, "horizon", horizon, "err", err)
+		http.Error(w, "interest rates up to the horizon are unknown, provide inflation or reference_rate", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		s.log.Warn("error comparing bonds", "purchased_at", purchasedAt, "horizon", horizon, "err", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	s.log.Info("compared bonds", "purchased_at", purchasedAt, "horizon", horizon, "bonds_no", len(comparisons))
+
+	resp := ComparisonResponse{
+		PurchasedAt: purchasedAt.Format("2006-01-02"),
+		Horizon:     horizon.Format("2006-01-02"),
+		Bonds:       make([]CandidateResponse, 0, len(comparisons)),
+		Currency:    "PLN",
+	}
+	for _, comparison := range comparisons {
+		resp.Bonds = append(resp.Bonds, CandidateResponse{
+			Name:            comparison.Bond.Name + purchasedAt.Format("02"),
+			ISIN:            comparison.Bond.ISIN,
+			MaturityDate:    comparison.Maturity.Format("2006-01-02"),
+			ValuatedAt:      comparison.Horizon.Format("2006-01-02"),
+			Price:           float64(comparison.Valuation.Price),
+			PaidCoupons:     float64(comparison.Valuation.PaidCoupons),
+			GrossValue:      float64(comparison.Gross),
+			EarlyRedemption: comparison.Redemption.Early,
+			Fee:             float64(comparison.Redemption.Fee),
+			Tax:             float64(comparison.Redemption.Tax),
+			Payout:          float64(comparison.Redemption.Net),
+			NetPaidCoupons:  float64(comparison.NetCoupons),
+			NetValue:        float64(comparison.Net),
+			DerivedRates:    comparison.Valuation.Derived,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// candidatesFromQuery resolves the issues on sale at purchasedAt of the series listed in the series query parameter,
+// e.g. series=EDO,COI, or of every supported series if it's empty or all.
+// On failure it writes the error response and returns false.
+func (s *Server) candidatesFromQuery(w http.ResponseWriter, r *http.Request, purchasedAt civil.Date) ([]bond.Bond, bool) {
+	supported := calculator.Series()
+	all := false
+	var series []string
+	switch q := r.URL.Query().Get("series"); q {
+	case "", "all":
+		all = true
+		series = supported
+	default:
+		series = strings.Split(strings.ToUpper(q), ",")
+	}
+
+	candidates := make([]bond.Bond, 0, len(series))
+	for _, name := range series {
+		if !slices.Contains(supported, name) {
+			http.Error(w, "invalid series", http.StatusBadRequest)
+			return nil, false
+		}
+		bnd, err := bond.OnSale(s.repo, name, purchasedAt)
+		if errors.Is(err, bond.ErrNotOnSale) {
+			if all {
+				continue
+			}
+			s.log.Info("series not on sale", "series", name, "purchased_at", purchasedAt)
+			http.Error(w, name+" is not on sale at purchased_at", http.StatusBadRequest)
+			return nil, false
+		}
+		if err != nil {
+			s.log.Warn("error looking up bond on sale", "series", name, "purchased_at", purchasedAt, "err", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return nil, false
+		}
+		candidates = append(candidates, bnd)
+	}
+	return candidates, true
+}
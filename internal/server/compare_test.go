@@ -0,0 +1,127 @@
+package server
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandleCompare(t *testing.T) {
+	server := loadTestServer(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/compare?purchased_at=2024-11-05&horizon=2025-08-05&series=coi,ROR,OTS", nil)
+	w := httptest.NewRecorder()
+
+	server.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+
+	var resp ComparisonResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode JSON: %v", err)
+	}
+
+	want := []struct {
+		name       string
+		valuatedAt string
+		early      bool
+		grossValue float64
+		netValue   float64
+	}{
+		{name: "COI112805", valuatedAt: "2025-08-05", early: true, grossValue: 104.71, netValue: 103.25},
+		{name: "ROR112505", valuatedAt: "2025-08-05", early: true, grossValue: 104.24, netValue: 103.45},
+		{name: "OTS022505", valuatedAt: "2025-02-05", early: false, grossValue: 100.76, netValue: 100.62},
+	}
+	if len(resp.Bonds) != len(want) {
+		t.Fatalf("got %d bonds, want %d", len(resp.Bonds), len(want))
+	}
+	for i, w := range want {
+		got := resp.Bonds[i]
+		if got.Name != w.name {
+			t.Errorf("got bonds[%d].name %q, want %q", i, got.Name, w.name)
+		}
+		if got.ValuatedAt != w.valuatedAt {
+			t.Errorf("got %s valuated_at %q, want %q", w.name, got.ValuatedAt, w.valuatedAt)
+		}
+		if got.EarlyRedemption != w.early {
+			t.Errorf("got %s early_redemption %v, want %v", w.name, got.EarlyRedemption, w.early)
+		}
+		if math.Abs(got.GrossValue-w.grossValue) > 1e-9 {
+			t.Errorf("got %s gross_value %v, want %v", w.name, got.GrossValue, w.grossValue)
+		}
+		if math.Abs(got.NetValue-w.netValue) > 1e-9 {
+			t.Errorf("got %s net_value %v, want %v", w.name, got.NetValue, w.netValue)
+		}
+	}
+}
+
+func TestHandleCompare_AllOnSale(t *testing.T) {
+	server := loadTestServer(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/compare?purchased_at=2024-11-05&horizon=2025-08-05", nil)
+	w := httptest.NewRecorder()
+
+	server.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+
+	var resp ComparisonResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode JSON: %v", err)
+	}
+
+	// every supported series but DOS, which wasn't sold at the time
+	var names []string
+	for _, bnd := range resp.Bonds {
+		names = append(names, bnd.Name)
+	}
+	want := []string{"COI112805", "DOR112605", "EDO113405", "OTS022505", "ROD113605", "ROR112505", "ROS113005", "TOS112705"}
+	if len(names) != len(want) {
+		t.Fatalf("got bonds %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("got bonds %v, want %v", names, want)
+			break
+		}
+	}
+}
+
+func TestHandleCompare_Errors(t *testing.T) {
+	server := loadTestServer(t)
+
+	tests := []struct {
+		name     string
+		url      string
+		wantCode int
+	}{
+		{name: "missing horizon", url: "/v1/compare?purchased_at=2024-11-05", wantCode: http.StatusBadRequest},
+		{name: "invalid horizon", url: "/v1/compare?purchased_at=2024-11-05&horizon=abc", wantCode: http.StatusBadRequest},
+		{name: "invalid purchased_at", url: "/v1/compare?purchased_at=abc&horizon=2025-08-05", wantCode: http.StatusBadRequest},
+		{name: "horizon before purchase", url: "/v1/compare?purchased_at=2024-11-05&horizon=2024-11-01", wantCode: http.StatusBadRequest},
+		{name: "invalid series", url: "/v1/compare?purchased_at=2024-11-05&horizon=2025-08-05&series=XYZ", wantCode: http.StatusBadRequest},
+		{name: "series not on sale", url: "/v1/compare?purchased_at=2024-11-05&horizon=2025-08-05&series=DOS", wantCode: http.StatusBadRequest},
+		{name: "invalid wrapper", url: "/v1/compare?purchased_at=2024-11-05&horizon=2025-08-05&wrapper=abc", wantCode: http.StatusBadRequest},
+		{name: "invalid inflation", url: "/v1/compare?purchased_at=2024-11-05&horizon=2025-08-05&inflation=abc", wantCode: http.StatusBadRequest},
+		{name: "rates unknown", url: "/v1/compare?purchased_at=2024-11-05&horizon=2030-11-05&series=EDO", wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			w := httptest.NewRecorder()
+
+			server.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("got status %d, want %d; body: %s", w.Code, tt.wantCode, w.Body.String())
+			}
+		})
+	}
+}
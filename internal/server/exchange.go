@@ -0,0 +1,149 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/calculator"
+	"github.com/maciekmm/obligacje/civil"
+)
+
+type ExchangeResponse struct {
+	Name           string                 `json:"name"`
+	ISIN           string                 `json:"isin"`
+	Quantity       int                    `json:"quantity"`
+	ExchangeDate   string                 `json:"exchange_date"`
+	Redemption     RedemptionResponse     `json:"redemption"`
+	InterestPayout float64                `json:"interest_payout"`
+	Target         ExchangeTargetResponse `json:"target"`
+	Leftover       float64                `json:"leftover"`
+	Currency       string                 `json:"currency"`
+}
+
+type ExchangeTargetResponse struct {
+	Name         string  `json:"name"`
+	ISIN         string  `json:"isin"`
+	Quantity     int     `json:"quantity"`
+	CostBasis    float64 `json:"cost_basis"`
+	ValuatedAt   string  `json:"valuated_at"`
+	Price        float64 `json:"price"`
+	PaidCoupons  float64 `json:"paid_coupons"`
+	NetPrice     float64 `json:"net_price"`
+	DerivedRates bool    `json:"derived_rates"`
+}
+
+func (s *Server) handleExchange(w http.ResponseWriter, r *http.Request) {
+	series := r.URL.Query().Get("target")
+	if series == "" {
+		http.Error(w, "missing target", http.StatusBadRequest)
+		return
+	}
+
+	quantity, ok := quantityFromQuery(w, r)
+	if !ok {
+		return
+	}
+
+	var valuatedAt civil.Date
+	var err error
+	if dateQ := r.URL.Query().Get("valuated_at"); dateQ != "" {
+		valuatedAt, err = civil.Parse(dateQ)
+		if err != nil {
+			http.Error(w, "invalid valuated_at", http.StatusBadRequest)
+			return
+		}
+	} else {
+		valuatedAt = civil.Today()
+	}
+
+	taxation, ok := taxationFromQuery(w, r)
+	if !ok {
+		return
+	}
+
+	old, purchaseDay, ok := s.lookupPurchasedBond(w, r)
+	if !ok {
+		return
+	}
+
+	_, maturity, err := old.Period(old.InterestPeriodCount()-1, purchaseDay)
+	if err != nil {
+		s.log.Warn("error calculating maturity", "name", old.Name, "purchase_day", purchaseDay, "err", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	target, err := bond.OnSale(s.repo, series, maturity)
+	if errors.Is(err, bond.ErrNotOnSale) {
+		s.log.Info("exchange target not on sale", "name", old.Name, "target", series, "exchange_date", maturity)
+		http.Error(w, "target is not on sale at maturity", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		s.log.Warn("error looking up exchange target", "target", series, "exchange_date", maturity, "err", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	exchange, err := s.calc.Exchange(old, purchaseDay, quantity, target, valuatedAt, taxation)
+	if errors.Is(err, calculator.ErrExchangeNotAvailable) {
+		s.log.Info("exchange not available", "name", old.Name, "target", target.Name)
+		http.Error(w, "target can't be acquired in an exchange", http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, calculator.ErrValuationDateBeforePurchaseDate) {
+		http.Error(w, "valuated_at is before exchange date", http.StatusBadRequest)
+		return
+	}
+	if err != nil && !errors.Is(err, calculator.ErrValuationDateAfterMaturity) {
+		s.log.Warn("error calculating exchange", "name", old.Name, "purchase_day", purchaseDay, "target", target.Name, "err", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	total, err := redemptionTotalResponse(exchange.Redemption, quantity)
+	if err != nil {
+		s.log.Warn("error calculating total", "name", old.Name, "purchase_day", purchaseDay, "quantity", quantity, "target", target.Name, "err", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	s.log.Info("exchanged bond", "name", old.Name, "purchase_day", purchaseDay, "quantity", quantity, "target", target.Name, "target_quantity", exchange.Quantity, "valuated_at", valuatedAt)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ExchangeResponse{
+		Name:         r.PathValue("name"),
+		ISIN:         old.ISIN,
+		Quantity:     quantity,
+		ExchangeDate: exchange.Date.Format("2006-01-02"),
+		Redemption: RedemptionResponse{
+			Name:       r.PathValue("name"),
+			ISIN:       old.ISIN,
+			RedeemedAt: exchange.Date.Format("2006-01-02"),
+			GrossValue: float64(exchange.Redemption.Gross),
+			Fee:        float64(exchange.Redemption.Fee),
+			Tax:        float64(exchange.Redemption.Tax),
+			Payout:     float64(exchange.Redemption.Net),
+			Quantity:   quantity,
+			Currency:   "PLN",
+			Total:      total,
+		},
+		InterestPayout: float64(exchange.InterestPayout),
+		Target: ExchangeTargetResponse{
+			Name:         target.Name + exchange.Date.Format("02"),
+			ISIN:         target.ISIN,
+			Quantity:     exchange.Quantity,
+			CostBasis:    float64(exchange.CostBasis),
+			ValuatedAt:   valuatedAt.Format("2006-01-02"),
+			Price:        float64(exchange.Valuation.Price),
+			PaidCoupons:  float64(exchange.Valuation.PaidCoupons),
+			NetPrice:     float64(exchange.NetValue.Net),
+			DerivedRates: exchange.Valuation.Derived,
+		},
+		Leftover: float64(exchange.Leftover),
+		Currency: "PLN",
+	})
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandleExchange(t *testing.T) {
+	server := loadTestServer(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/bond/TOS112501/exchange?target=EDO&quantity=10&valuated_at=2026-11-01", nil)
+	w := httptest.NewRecorder()
+
+	server.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+
+	var resp ExchangeResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode JSON: %v", err)
+	}
+
+	if resp.ExchangeDate != "2025-11-01" {
+		t.Errorf("got exchange_date %q, want 2025-11-01", resp.ExchangeDate)
+	}
+	if resp.Target.Name != "EDO113501" {
+		t.Errorf("got target name %q, want EDO113501", resp.Target.Name)
+	}
+	if resp.Target.Quantity != 10 {
+		t.Errorf("got target quantity %d, want 10", resp.Target.Quantity)
+	}
+
+	prices := []struct {
+		field string
+		got   float64
+		want  float64
+	}{
+		{field: "redemption.payout", got: resp.Redemption.Payout, want: 117.81},
+		{field: "interest_payout", got: resp.InterestPayout, want: 178.10},
+		{field: "leftover", got: resp.Leftover, want: 1.00},
+		{field: "target.cost_basis", got: resp.Target.CostBasis, want: 99.90},
+		{field: "target.price", got: resp.Target.Price, want: 105.75},
+		{field: "target.net_price", got: resp.Target.NetPrice, want: 103.02},
+	}
+	for _, p := range prices {
+		if math.Abs(p.got-p.want) > 1e-9 {
+			t.Errorf("got %s %v, want %v", p.field, p.got, p.want)
+		}
+	}
+}
+
+func TestHandleExchange_Errors(t *testing.T) {
+	server := loadTestServer(t)
+
+	tests := []struct {
+		name     string
+		url      string
+		wantCode int
+	}{
+		{name: "missing target", url: "/v1/bond/TOS112501/exchange", wantCode: http.StatusBadRequest},
+		{name: "invalid quantity", url: "/v1/bond/TOS112501/exchange?target=EDO&quantity=0", wantCode: http.StatusBadRequest},
+		{name: "invalid valuated_at", url: "/v1/bond/TOS112501/exchange?target=EDO&valuated_at=abc", wantCode: http.StatusBadRequest},
+		{name: "target not on sale", url: "/v1/bond/EDO083401/exchange?target=EDO", wantCode: http.StatusBadRequest},
+		{name: "valuated before exchange", url: "/v1/bond/TOS112501/exchange?target=EDO&valuated_at=2025-10-01", wantCode: http.StatusBadRequest},
+		{name: "bond not found", url: "/v1/bond/NONEXIST01/exchange?target=EDO", wantCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			w := httptest.NewRecorder()
+
+			server.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("got status %d, want %d; body: %s", w.Code, tt.wantCode, w.Body.String())
+			}
+		})
+	}
+}
@@ -4,19 +4,25 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
-	"time"
 
-	"github.com/maciekmm/obligacje/bond"
 	"github.com/maciekmm/obligacje/calculator"
-	"github.com/maciekmm/obligacje/tz"
+	"github.com/maciekmm/obligacje/civil"
 )
 
 type Valuation struct {
-	Date  string  `json:"date"`
-	Price float64 `json:"price"`
+	Date           string  `json:"date"`
+	Price          float64 `json:"price"`
+	PaidCoupons    float64 `json:"paid_coupons"`
+	NetPrice       float64 `json:"net_price"`
+	NetPaidCoupons float64 `json:"net_paid_coupons"`
+	DerivedRates   bool    `json:"derived_rates"`
+
+	Total ValuationTotalResponse `json:"total"`
+	Real  *RealValueResponse     `json:"real,omitempty"`
 }
 
 type HistoricalResponse struct {
+	Quantity   int         `json:"quantity"`
 	Valuations []Valuation `json:"valuations"`
 }
 
@@ -29,13 +35,13 @@ func (s *Server) handleHistorical(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	from, err := time.ParseInLocation("2006-01-02", fromStr, tz.UnifiedTimezone)
+	from, err := civil.Parse(fromStr)
 	if err != nil {
 		http.Error(w, "invalid from date", http.StatusBadRequest)
 		return
 	}
 
-	to, err := time.ParseInLocation("2006-01-02", toStr, tz.UnifiedTimezone)
+	to, err := civil.Parse(toStr)
 	if err != nil {
 		http.Error(w, "invalid to date", http.StatusBadRequest)
 		return
@@ -46,24 +52,23 @@ func (s *Server) handleHistorical(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	nameWithPurchaseDay := r.PathValue("name")
-	purchaseDay, err := extractPurchaseDayFromName(nameWithPurchaseDay)
-	if err != nil {
-		s.log.Info("invalid name", "name", nameWithPurchaseDay, "err", err)
-		http.Error(w, "invalid name", http.StatusBadRequest)
+	realTerms, ok := boolFromQuery(w, r, "real")
+	if !ok {
 		return
 	}
-	name := nameWithPurchaseDay[:len(nameWithPurchaseDay)-2]
 
-	bnd, err := s.repo.Lookup(name)
-	if errors.Is(err, bond.ErrNameNotFound) {
-		s.log.Info("bond not found", "name", name)
-		http.Error(w, "invalid name", http.StatusNotFound)
+	quantity, ok := quantityFromQuery(w, r)
+	if !ok {
 		return
 	}
-	if err != nil {
-		s.log.Info("error looking up bond", "name", name, "err", err)
-		http.Error(w, "internal error", http.StatusInternalServerError)
+
+	taxation, ok := taxationFromQuery(w, r)
+	if !ok {
+		return
+	}
+
+	bnd, purchaseDay, ok := s.lookupPurchasedBond(w, r)
+	if !ok {
 		return
 	}
 
@@ -72,33 +77,65 @@ func (s *Server) handleHistorical(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if to.After(time.Now().Truncate(24*time.Hour).AddDate(0, 0, 1)) {
+	if to.After(civil.Today()) {
 		http.Error(w, "to must not be after today", http.StatusBadRequest)
 		return
 	}
 
-	valuations := make([]Valuation, 0, int(to.Sub(from).Hours())/24+1)
+	valuations := make([]Valuation, 0, to.DaysSince(from)+1)
 	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
-		price, err := s.calc.Calculate(bnd, purchaseDay, d)
+		valuation, err := s.calc.Valuate(bnd, purchaseDay, d)
 		if errors.Is(err, calculator.ErrValuationDateBeforePurchaseDate) {
 			continue
 		}
 		if err != nil && !errors.Is(err, calculator.ErrValuationDateAfterMaturity) {
-			s.log.Warn("error calculating price", "name", name, "purchase_day", purchaseDay, "date", d, "err", err)
+			s.log.Warn("error calculating price", "name", bnd.Name, "purchase_day", purchaseDay, "date", d, "err", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		net, err := s.calc.NetValue(bnd, purchaseDay, d, taxation)
+		if err != nil && !errors.Is(err, calculator.ErrValuationDateAfterMaturity) {
+			s.log.Warn("error calculating net value", "name", bnd.Name, "purchase_day", purchaseDay, "date", d, "err", err)
 			http.Error(w, "internal error", http.StatusInternalServerError)
 			return
 		}
+		total, err := valuationTotalResponse(valuation, net, taxation, quantity)
+		if err != nil {
+			s.log.Warn("error calculating total", "name", bnd.Name, "purchase_day", purchaseDay, "quantity", quantity, "date", d, "err", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		var realValue *RealValueResponse
+		if realTerms {
+			deflated, err := s.calc.RealValue(bnd, purchaseDay, d, taxation)
+			if err != nil && !errors.Is(err, calculator.ErrValuationDateAfterMaturity) && !errors.Is(err, calculator.ErrInflationUnknown) {
+				s.log.Warn("error calculating real value", "name", bnd.Name, "purchase_day", purchaseDay, "date", d, "err", err)
+				http.Error(w, "internal error", http.StatusInternalServerError)
+				return
+			}
+			// the real value is left out on days the inflation isn't known for
+			if !errors.Is(err, calculator.ErrInflationUnknown) {
+				realValue = realValueResponse(deflated)
+			}
+		}
 		valuations = append(valuations, Valuation{
-			Date:  d.Format("2006-01-02"),
-			Price: float64(price),
+			Date:           d.Format("2006-01-02"),
+			Price:          float64(valuation.Price),
+			PaidCoupons:    float64(valuation.PaidCoupons),
+			NetPrice:       float64(net.Net),
+			NetPaidCoupons: float64(taxation.NetCoupons(valuation)),
+			DerivedRates:   valuation.Derived,
+			Total:          total,
+			Real:           realValue,
 		})
 	}
 
-	s.log.Info("historical valuation", "name", name, "purchase_day", purchaseDay, "from", from, "to", to, "days", len(valuations))
+	s.log.Info("historical valuation", "name", bnd.Name, "purchase_day", purchaseDay, "quantity", quantity, "from", from, "to", to, "days", len(valuations))
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(HistoricalResponse{
+		Quantity:   quantity,
 		Valuations: valuations,
 	})
 }
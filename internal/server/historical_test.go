@@ -8,6 +8,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	"github.com/maciekmm/obligacje/calculator"
+	"github.com/maciekmm/obligacje/index"
+	"github.com/maciekmm/obligacje/internal/testutil"
 )
 
 func TestHandleHistorical_HappyPath(t *testing.T) {
@@ -122,6 +126,12 @@ func TestHandleHistorical_Errors(t *testing.T) {
 			query:    "from=not-a-date&to=2023-03-27",
 			wantCode: http.StatusBadRequest,
 		},
+		{
+			name:     "invalid quantity",
+			bondName: "TOS112501",
+			query:    "from=2023-03-25&to=2023-03-27&quantity=0",
+			wantCode: http.StatusBadRequest,
+		},
 		{
 			name:     "invalid to date",
 			bondName: "TOS112501",
@@ -254,6 +264,45 @@ func TestHandleHistorical_AfterMaturity(t *testing.T) {
 	}
 }
 
+func TestHandleHistorical_NetOfTax(t *testing.T) {
+	server := loadTestServer(t)
+
+	tests := []struct {
+		name         string
+		query        string
+		wantNetPrice float64
+	}{
+		{name: "taxable", wantNetPrice: 117.81},
+		{name: "IKE", query: "&wrapper=ike", wantNetPrice: 121.99},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := "/v1/bond/TOS112501/historical?from=2025-12-01&to=2025-12-01" + tt.query
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+			w := httptest.NewRecorder()
+
+			server.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("got status %d, want 200; body: %s", w.Code, w.Body.String())
+			}
+
+			var resp HistoricalResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode JSON: %v", err)
+			}
+
+			if len(resp.Valuations) != 1 {
+				t.Fatalf("got %d valuation days, want 1", len(resp.Valuations))
+			}
+			if got := resp.Valuations[0].NetPrice; math.Abs(got-tt.wantNetPrice) > 1e-9 {
+				t.Errorf("got net_price %v, want %v", got, tt.wantNetPrice)
+			}
+		})
+	}
+}
+
 func TestHandleHistorical_ExactlyMaxSpan(t *testing.T) {
 	_ = slog.Default() // ensure the test server logger is available
 	server := loadTestServer(t)
@@ -279,3 +328,73 @@ func TestHandleHistorical_ExactlyMaxSpan(t *testing.T) {
 		t.Error("expected non-empty valuations for max span")
 	}
 }
+
+func TestHandleHistorical_Quantity(t *testing.T) {
+	server := loadTestServer(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/bond/TOS112501/historical?from=2025-11-30&to=2025-12-01&quantity=10", nil)
+	w := httptest.NewRecorder()
+
+	server.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+
+	var resp HistoricalResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode JSON: %v", err)
+	}
+
+	if resp.Quantity != 10 {
+		t.Errorf("got quantity %d, want 10", resp.Quantity)
+	}
+	if len(resp.Valuations) != 2 {
+		t.Fatalf("got %d valuation days, want 2", len(resp.Valuations))
+	}
+	for _, v := range resp.Valuations {
+		if math.Abs(v.Total.Price-10*v.Price) > 1e-6 {
+			t.Errorf("got total.price %v on %s, want 10 * %v", v.Total.Price, v.Date, v.Price)
+		}
+		if math.Abs(v.Total.NetPrice-10*v.NetPrice) > 1e-6 {
+			t.Errorf("got total.net_price %v on %s, want 10 * %v", v.Total.NetPrice, v.Date, v.NetPrice)
+		}
+	}
+	if got := resp.Valuations[1].Total.NetPrice; math.Abs(got-1178.10) > 1e-9 {
+		t.Errorf("got total.net_price %v at maturity, want 1178.10", got)
+	}
+}
+
+func TestHandleHistorical_RealValue(t *testing.T) {
+	cpi, err := index.LoadCPI(testutil.CPIDataFile())
+	if err != nil {
+		t.Fatalf("failed to load CPI: %v", err)
+	}
+	server := NewServer(loadTestServer(t).repo, slog.Default(), calculator.WithInflation(cpi))
+
+	// the test CPI ends in 2025-10, so the inflation is unknown from 2026-02 onwards
+	req := httptest.NewRequest(http.MethodGet, "/v1/bond/EDO083401/historical?from=2025-08-15&to=2026-02-01&real=true", nil)
+	w := httptest.NewRecorder()
+
+	server.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+
+	var resp HistoricalResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode JSON: %v", err)
+	}
+
+	first, last := resp.Valuations[0], resp.Valuations[len(resp.Valuations)-1]
+	if first.Real == nil {
+		t.Fatalf("expected real value on %s", first.Date)
+	}
+	if math.Abs(first.Real.Value-104.03) > 1e-9 {
+		t.Errorf("got real value %v on %s, want 104.03", first.Real.Value, first.Date)
+	}
+	if last.Real != nil {
+		t.Errorf("got real value %+v on %s, want none", last.Real, last.Date)
+	}
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/maciekmm/obligacje/portfolio"
+)
+
+const maxExportRequestSize = 4 << 20
+
+// PortfolioImportResponse holds the holdings in the request format of the portfolio valuation.
+type PortfolioImportResponse struct {
+	Holdings  []portfolio.Holding    `json:"holdings"`
+	Unmatched []UnmatchedRowResponse `json:"unmatched"`
+}
+
+type UnmatchedRowResponse struct {
+	Row   int      `json:"row"`
+	Cells []string `json:"cells"`
+	Error string   `json:"error"`
+}
+
+func (s *Server) handlePortfolioImport(w http.ResponseWriter, r *http.Request) {
+	imported, err := portfolio.ParseExport(s.repo, http.MaxBytesReader(w, r.Body, maxExportRequestSize))
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		http.Error(w, "export too large", http.StatusRequestEntityTooLarge)
+		return
+	case errors.Is(err, portfolio.ErrInvalidExport), errors.Is(err, portfolio.ErrInvalidPortfolio):
+		s.log.Info("invalid account export", "err", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		s.log.Warn("error importing account export", "err", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	s.log.Info("imported account export", "holdings_no", len(imported.Portfolio.Holdings), "unmatched_no", len(imported.Unmatched))
+
+	resp := PortfolioImportResponse{
+		Holdings:  imported.Portfolio.Holdings,
+		Unmatched: make([]UnmatchedRowResponse, len(imported.Unmatched)),
+	}
+	for i, u := range imported.Unmatched {
+		resp.Unmatched[i] = UnmatchedRowResponse{
+			Row:   u.Row,
+			Cells: u.Cells,
+			Error: u.Err.Error(),
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
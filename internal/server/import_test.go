@@ -0,0 +1,71 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testExport = `Rachunek;Emisja;Data zakupu;Liczba obligacji (szt.)
+12345;EDO0834;15.08.2024;10
+12345;COI0528;01.05.2024;5
+12345;XYZ9999;01.05.2024;1
+`
+
+func TestHandlePortfolioImport(t *testing.T) {
+	server := loadTestServer(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/portfolio/import", strings.NewReader(testExport))
+	w := httptest.NewRecorder()
+
+	server.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+
+	var resp PortfolioImportResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode JSON: %v", err)
+	}
+
+	if len(resp.Holdings) != 2 {
+		t.Fatalf("got %d holdings, want 2", len(resp.Holdings))
+	}
+	if h := resp.Holdings[0]; h.Series != "EDO0834" || h.PurchaseDay != 15 || h.Quantity != 10 || h.Account != "12345" {
+		t.Errorf("got holding %+v, want 10 EDO083415 in account 12345", h)
+	}
+	if len(resp.Unmatched) != 1 || resp.Unmatched[0].Row != 4 || resp.Unmatched[0].Error == "" {
+		t.Errorf("got unmatched %+v, want row 4 with an error", resp.Unmatched)
+	}
+}
+
+func TestHandlePortfolioImport_Errors(t *testing.T) {
+	server := loadTestServer(t)
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		{name: "no header", body: "EDO0834;15.08.2024;10\n", wantCode: http.StatusBadRequest},
+		{name: "legacy XLS", body: "\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1", wantCode: http.StatusBadRequest},
+		{name: "too many bonds", body: "Emisja;Data zakupu;Liczba obligacji\nEDO0834;15.08.2024;600000\nEDO0834;16.08.2024;600000\n", wantCode: http.StatusBadRequest},
+		{name: "too large", body: strings.Repeat("x", maxExportRequestSize+1), wantCode: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/portfolio/import", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			server.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("got status %d, want %d; body: %s", w.Code, tt.wantCode, w.Body.String())
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/civil"
 )
 
 type MetadataResponse struct {
@@ -20,6 +21,11 @@ type MetadataResponse struct {
 	SaleStart               string    `json:"sale_start"`
 	SaleEnd                 string    `json:"sale_end"`
 	MaturityDate            string    `json:"maturity_date,omitempty"`
+	EarlyRedeemable         bool      `json:"early_redeemable"`
+	EarlyRedemptionFee      float64   `json:"early_redemption_fee"`
+
+	// Lifecycle is the stage of life of a purchased bond today, left out until it's bought.
+	Lifecycle *LifecycleResponse `json:"lifecycle,omitempty"`
 }
 
 func (s *Server) handleMetadata(w http.ResponseWriter, r *http.Request) {
@@ -67,6 +73,8 @@ func (s *Server) handleMetadata(w http.ResponseWriter, r *http.Request) {
 		CouponPaymentsFrequency: int(bnd.CouponPaymentsFrequency),
 		SaleStart:               bnd.SaleStart.Format("2006-01-02"),
 		SaleEnd:                 bnd.SaleEnd.Format("2006-01-02"),
+		EarlyRedeemable:         bnd.EarlyRedeemable,
+		EarlyRedemptionFee:      float64(bnd.EarlyRedemptionFee),
 	}
 
 	if purchaseDay > 0 {
@@ -74,6 +82,12 @@ func (s *Server) handleMetadata(w http.ResponseWriter, r *http.Request) {
 		if err == nil {
 			resp.MaturityDate = endAt.Format("2006-01-02")
 		}
+
+		lifecycle, err := s.calc.Lifecycle(bnd, purchaseDay, civil.Today())
+		if err == nil {
+			lifecycleResp := lifecycleResponse(lifecycle)
+			resp.Lifecycle = &lifecycleResp
+		}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
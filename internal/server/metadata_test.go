@@ -78,3 +78,36 @@ func TestHandleMetadata(t *testing.T) {
 		})
 	}
 }
+
+func TestHandleMetadata_Lifecycle(t *testing.T) {
+	server := loadTestServer(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/bond/TOS112501", nil)
+	w := httptest.NewRecorder()
+
+	server.ServeHTTP(w, req)
+
+	var resp MetadataResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode JSON: %v", err)
+	}
+	if resp.Lifecycle == nil {
+		t.Fatal("expected lifecycle for a purchased bond")
+	}
+	if resp.Lifecycle.Status != "matured" || resp.Lifecycle.CurrentPeriod != nil || resp.Lifecycle.NextInterest != "" {
+		t.Errorf("got lifecycle %+v, want matured without upcoming events", resp.Lifecycle)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/bond/TOS1125", nil)
+	w = httptest.NewRecorder()
+
+	server.ServeHTTP(w, req)
+
+	resp = MetadataResponse{}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode JSON: %v", err)
+	}
+	if resp.Lifecycle != nil {
+		t.Errorf("got lifecycle %+v for a series, want none", resp.Lifecycle)
+	}
+}
@@ -0,0 +1,136 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/calculator"
+	"github.com/maciekmm/obligacje/civil"
+	"github.com/maciekmm/obligacje/portfolio"
+)
+
+const maxPortfolioRequestSize = 256 << 10
+
+type PortfolioValuationResponse struct {
+	ValuatedAt   string                     `json:"valuated_at"`
+	Holdings     []PortfolioHoldingResponse `json:"holdings"`
+	Total        ValuationTotalResponse     `json:"total"`
+	DerivedRates bool                       `json:"derived_rates"`
+	Currency     string                     `json:"currency"`
+}
+
+// PortfolioHoldingResponse is the value of a single holding. Amounts are per bond, while Total holds
+// the value of all bonds of the holding. Holdings not bought yet or redeemed early are reported without a lifecycle.
+type PortfolioHoldingResponse struct {
+	Name           string                 `json:"name"`
+	ISIN           string                 `json:"isin"`
+	Account        string                 `json:"account,omitempty"`
+	Wrapper        string                 `json:"wrapper,omitempty"`
+	Quantity       int                    `json:"quantity"`
+	Held           bool                   `json:"held"`
+	Redeemed       bool                   `json:"redeemed"`
+	Lifecycle      *LifecycleResponse     `json:"lifecycle,omitempty"`
+	Price          float64                `json:"price"`
+	PaidCoupons    float64                `json:"paid_coupons"`
+	NetPrice       float64                `json:"net_price"`
+	NetPaidCoupons float64                `json:"net_paid_coupons"`
+	DerivedRates   bool                   `json:"derived_rates"`
+	Total          ValuationTotalResponse `json:"total"`
+}
+
+func (s *Server) handlePortfolioValuation(w http.ResponseWriter, r *http.Request) {
+	valuatedAt := civil.Today()
+	if valAtQ := r.URL.Query().Get("valuated_at"); valAtQ != "" {
+		var err error
+		valuatedAt, err = civil.Parse(valAtQ)
+		if err != nil {
+			http.Error(w, "invalid valuated_at", http.StatusBadRequest)
+			return
+		}
+	}
+
+	p, ok := s.portfolioFromBody(w, r)
+	if !ok {
+		return
+	}
+
+	valuation, err := portfolio.Valuate(s.repo, s.calc, p, valuatedAt)
+	if !s.checkPortfolioError(w, err) {
+		return
+	}
+
+	s.log.Info("valuated portfolio", "holdings_no", len(p.Holdings), "valuated_at", valuatedAt, "price", valuation.Total.Price)
+
+	resp := PortfolioValuationResponse{
+		ValuatedAt:   valuatedAt.Format("2006-01-02"),
+		Holdings:     make([]PortfolioHoldingResponse, len(valuation.Holdings)),
+		Total:        portfolioTotalResponse(valuation.Total),
+		DerivedRates: valuation.Derived,
+		Currency:     "PLN",
+	}
+	for i, hv := range valuation.Holdings {
+		resp.Holdings[i] = PortfolioHoldingResponse{
+			Name:           hv.Holding.Name(),
+			ISIN:           hv.Bond.ISIN,
+			Account:        hv.Holding.Account,
+			Wrapper:        hv.Holding.Wrapper,
+			Quantity:       hv.Holding.Quantity,
+			Held:           hv.Held,
+			Redeemed:       hv.Redeemed,
+			Price:          float64(hv.PerBond.Price),
+			PaidCoupons:    float64(hv.PerBond.PaidCoupons),
+			NetPrice:       float64(hv.PerBond.NetPrice),
+			NetPaidCoupons: float64(hv.PerBond.NetPaidCoupons),
+			DerivedRates:   hv.Derived,
+			Total:          portfolioTotalResponse(hv.Total),
+		}
+		redeemedEarly := hv.Redeemed && hv.Lifecycle.Status != calculator.StatusMatured
+		if hv.Lifecycle.Status != "" && !redeemedEarly {
+			lifecycle := lifecycleResponse(hv.Lifecycle)
+			resp.Holdings[i].Lifecycle = &lifecycle
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// portfolioFromBody reads the holdings from the request body.
+// On failure it writes the error response and returns false.
+func (s *Server) portfolioFromBody(w http.ResponseWriter, r *http.Request) (portfolio.Portfolio, bool) {
+	p, err := portfolio.Parse(http.MaxBytesReader(w, r.Body, maxPortfolioRequestSize))
+	if err != nil {
+		s.log.Info("invalid portfolio", "err", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return portfolio.Portfolio{}, false
+	}
+	return p, true
+}
+
+// checkPortfolioError writes the error response for errors valuating a portfolio and returns false,
+// or returns true if the portfolio could be valued. Unknown rates are ignored like in valuations of single bonds.
+func (s *Server) checkPortfolioError(w http.ResponseWriter, err error) bool {
+	switch {
+	case err == nil || errors.Is(err, calculator.ErrValuationDateAfterMaturity):
+		return true
+	case errors.Is(err, portfolio.ErrInvalidPortfolio), errors.Is(err, bond.ErrNameNotFound), errors.Is(err, calculator.ErrUnsupportedSeries):
+		s.log.Info("invalid portfolio", "err", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		s.log.Warn("error valuating portfolio", "err", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+	return false
+}
+
+func portfolioTotalResponse(amounts portfolio.Amounts) ValuationTotalResponse {
+	return ValuationTotalResponse{
+		Price:          float64(amounts.Price),
+		PaidCoupons:    float64(amounts.PaidCoupons),
+		NetPrice:       float64(amounts.NetPrice),
+		NetPaidCoupons: float64(amounts.NetPaidCoupons),
+	}
+}
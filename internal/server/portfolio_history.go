@@ -0,0 +1,145 @@
+package server
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/maciekmm/obligacje/civil"
+	"github.com/maciekmm/obligacje/portfolio"
+)
+
+// maxPortfolioHistoryPoints limits the length of portfolio histories, about three years of daily points.
+const maxPortfolioHistoryPoints = 1100
+
+type PortfolioHistoryResponse struct {
+	From        string                  `json:"from"`
+	To          string                  `json:"to"`
+	Granularity string                  `json:"granularity"`
+	Points      []PortfolioHistoryPoint `json:"points"`
+	Currency    string                  `json:"currency"`
+}
+
+type PortfolioHistoryPoint struct {
+	Date           string  `json:"date"`
+	Value          float64 `json:"value"`
+	Invested       float64 `json:"invested"`
+	Interest       float64 `json:"interest"`
+	PaidCoupons    float64 `json:"paid_coupons"`
+	NetValue       float64 `json:"net_value"`
+	NetPaidCoupons float64 `json:"net_paid_coupons"`
+	Redeemed       float64 `json:"redeemed"`
+	DerivedRates   bool    `json:"derived_rates"`
+}
+
+func (s *Server) handlePortfolioHistory(w http.ResponseWriter, r *http.Request) {
+	fromStr := r.URL.Query().Get("from")
+	toStr := r.URL.Query().Get("to")
+
+	if fromStr == "" || toStr == "" {
+		http.Error(w, "from and to parameters are required", http.StatusBadRequest)
+		return
+	}
+
+	from, err := civil.Parse(fromStr)
+	if err != nil {
+		http.Error(w, "invalid from date", http.StatusBadRequest)
+		return
+	}
+
+	to, err := civil.Parse(toStr)
+	if err != nil {
+		http.Error(w, "invalid to date", http.StatusBadRequest)
+		return
+	}
+
+	if to.Before(from) {
+		http.Error(w, "to must not be before from", http.StatusBadRequest)
+		return
+	}
+
+	if to.After(civil.Today()) {
+		http.Error(w, "to must not be after today", http.StatusBadRequest)
+		return
+	}
+
+	granularity, err := portfolio.ParseGranularity(r.URL.Query().Get("granularity"))
+	if err != nil {
+		http.Error(w, "invalid granularity", http.StatusBadRequest)
+		return
+	}
+
+	if len(granularity.Dates(from, to)) > maxPortfolioHistoryPoints {
+		http.Error(w, "too many points, use a coarser granularity", http.StatusBadRequest)
+		return
+	}
+
+	p, ok := s.portfolioFromBody(w, r)
+	if !ok {
+		return
+	}
+
+	history, err := portfolio.History(s.repo, s.calc, p, from, to, granularity)
+	if !s.checkPortfolioError(w, err) {
+		return
+	}
+
+	s.log.Info("portfolio history", "holdings_no", len(p.Holdings), "from", from, "to", to, "granularity", granularity, "points", len(history))
+
+	points := make([]PortfolioHistoryPoint, len(history))
+	for i, point := range history {
+		points[i] = PortfolioHistoryPoint{
+			Date:           point.Date.Format("2006-01-02"),
+			Value:          float64(point.Value),
+			Invested:       float64(point.Invested),
+			Interest:       float64(point.Interest),
+			PaidCoupons:    float64(point.PaidCoupons),
+			NetValue:       float64(point.NetValue),
+			NetPaidCoupons: float64(point.NetPaidCoupons),
+			Redeemed:       float64(point.Redeemed),
+			DerivedRates:   point.Derived,
+		}
+	}
+
+	if strings.Contains(r.Header.Get("Accept"), "text/csv") {
+		w.Header().Set("Content-Type", "text/csv")
+		w.WriteHeader(http.StatusOK)
+		writePortfolioHistoryCSV(w, points)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(PortfolioHistoryResponse{
+		From:        from.Format("2006-01-02"),
+		To:          to.Format("2006-01-02"),
+		Granularity: granularity.String(),
+		Points:      points,
+		Currency:    "PLN",
+	})
+}
+
+func writePortfolioHistoryCSV(w http.ResponseWriter, points []PortfolioHistoryPoint) {
+	amount := func(v float64) string {
+		return strconv.FormatFloat(v, 'f', 2, 64)
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"date", "value", "invested", "interest", "paid_coupons", "net_value", "net_paid_coupons", "redeemed", "derived_rates"})
+	for _, p := range points {
+		writer.Write([]string{
+			p.Date,
+			amount(p.Value),
+			amount(p.Invested),
+			amount(p.Interest),
+			amount(p.PaidCoupons),
+			amount(p.NetValue),
+			amount(p.NetPaidCoupons),
+			amount(p.Redeemed),
+			strconv.FormatBool(p.DerivedRates),
+		})
+	}
+	writer.Flush()
+}
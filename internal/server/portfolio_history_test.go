@@ -0,0 +1,116 @@
+package server
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandlePortfolioHistory(t *testing.T) {
+	server := loadTestServer(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/portfolio/history?from=2025-09-15&to=2025-12-10&granularity=monthly", strings.NewReader(testPortfolio))
+	w := httptest.NewRecorder()
+
+	server.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+
+	var resp PortfolioHistoryResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode JSON: %v", err)
+	}
+
+	if resp.Granularity != "monthly" || len(resp.Points) != 5 {
+		t.Fatalf("got %s history of %d points, want monthly of 5", resp.Granularity, len(resp.Points))
+	}
+	if got := resp.Points[2].Date; got != "2025-10-31" {
+		t.Errorf("got date %s, want 2025-10-31", got)
+	}
+
+	last := resp.Points[4]
+	prices := []struct {
+		field string
+		got   float64
+		want  float64
+	}{
+		{field: "invested", got: last.Invested, want: 3500},
+		{field: "value", got: last.Value, want: 3612.20},
+		{field: "interest", got: last.Interest, want: 112.20},
+		{field: "redeemed", got: last.Redeemed, want: 365.97},
+		{field: "paid_coupons", got: last.PaidCoupons, want: 32.75},
+	}
+	for _, p := range prices {
+		if math.Abs(p.got-p.want) > 1e-9 {
+			t.Errorf("got %s %v, want %v", p.field, p.got, p.want)
+		}
+	}
+}
+
+func TestHandlePortfolioHistory_CSV(t *testing.T) {
+	server := loadTestServer(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/portfolio/history?from=2025-09-15&to=2025-10-01&granularity=weekly", strings.NewReader(testPortfolio))
+	req.Header.Set("Accept", "text/csv")
+	w := httptest.NewRecorder()
+
+	server.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Content-Type"); got != "text/csv" {
+		t.Errorf("got content type %s, want text/csv", got)
+	}
+
+	records, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	if len(records) != 5 {
+		t.Fatalf("got %d records, want a header and 4 points", len(records))
+	}
+	if got := records[0][0] + "," + records[0][1]; got != "date,value" {
+		t.Errorf("got header %v, want date and value first", records[0])
+	}
+	if got := records[1]; got[0] != "2025-09-15" || got[1] != "1950.49" || got[2] != "1800.00" {
+		t.Errorf("got first point %v, want 1950.49 invested 1800.00 on 2025-09-15", got)
+	}
+}
+
+func TestHandlePortfolioHistory_Errors(t *testing.T) {
+	server := loadTestServer(t)
+
+	tests := []struct {
+		name     string
+		query    string
+		body     string
+		wantCode int
+	}{
+		{name: "missing from", query: "to=2025-11-01", body: testPortfolio, wantCode: http.StatusBadRequest},
+		{name: "to before from", query: "from=2025-11-01&to=2025-10-01", body: testPortfolio, wantCode: http.StatusBadRequest},
+		{name: "to after today", query: "from=2025-11-01&to=2999-01-01", body: testPortfolio, wantCode: http.StatusBadRequest},
+		{name: "invalid granularity", query: "from=2025-01-01&to=2025-11-01&granularity=yearly", body: testPortfolio, wantCode: http.StatusBadRequest},
+		{name: "too many points", query: "from=2015-01-01&to=2025-11-01", body: testPortfolio, wantCode: http.StatusBadRequest},
+		{name: "malformed body", query: "from=2025-01-01&to=2025-11-01", body: `{"holdings": [`, wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/portfolio/history?"+tt.query, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			server.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("got status %d, want %d; body: %s", w.Code, tt.wantCode, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testPortfolio = `{
+  "holdings": [
+    {"series": "EDO0834", "purchase_day": 1, "quantity": 10, "account": "main"},
+    {"series": "COI0528", "purchase_day": 1, "quantity": 5, "account": "retirement", "wrapper": "ike"},
+    {"series": "TOS1125", "purchase_day": 1, "quantity": 3},
+    {"series": "ROR1226", "purchase_day": 1, "quantity": 20}
+  ]
+}`
+
+func TestHandlePortfolioValuation(t *testing.T) {
+	server := loadTestServer(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/portfolio/valuation?valuated_at=2025-11-01", strings.NewReader(testPortfolio))
+	w := httptest.NewRecorder()
+
+	server.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+
+	var resp PortfolioValuationResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode JSON: %v", err)
+	}
+
+	if len(resp.Holdings) != 4 {
+		t.Fatalf("got %d holdings, want 4", len(resp.Holdings))
+	}
+	coi := resp.Holdings[1]
+	if coi.Name != "COI052801" || coi.Account != "retirement" || !coi.Held || coi.Lifecycle == nil || coi.Lifecycle.Status != "active" {
+		t.Errorf("got holding %+v, want held COI052801 in the retirement account", coi)
+	}
+	if tos := resp.Holdings[2]; tos.Held || !tos.Redeemed || tos.Lifecycle == nil || tos.Lifecycle.Status != "matured" {
+		t.Errorf("got holding %+v, want matured TOS112501", tos)
+	}
+	if ror := resp.Holdings[3]; ror.Held || ror.Lifecycle != nil {
+		t.Errorf("got holding %+v, want ROR122601 not bought yet", ror)
+	}
+
+	prices := []struct {
+		field string
+		got   float64
+		want  float64
+	}{
+		{field: "holdings[1].net_price", got: coi.NetPrice, want: 102.40},
+		{field: "holdings[1].total.price", got: coi.Total.Price, want: 515.50},
+		// 10 EDO at 108.44 and 5 COI at 103.10
+		{field: "total.price", got: resp.Total.Price, want: 1599.90},
+		{field: "total.net_price", got: resp.Total.NetPrice, want: 1564.20},
+		{field: "total.paid_coupons", got: resp.Total.PaidCoupons, want: 32.75},
+	}
+	for _, p := range prices {
+		if math.Abs(p.got-p.want) > 1e-9 {
+			t.Errorf("got %s %v, want %v", p.field, p.got, p.want)
+		}
+	}
+}
+
+func TestHandlePortfolioValuation_Errors(t *testing.T) {
+	server := loadTestServer(t)
+
+	tests := []struct {
+		name     string
+		query    string
+		body     string
+		wantCode int
+	}{
+		{name: "invalid valuated_at", query: "valuated_at=2025-13-01", body: testPortfolio, wantCode: http.StatusBadRequest},
+		{name: "malformed body", body: `{"holdings": [`, wantCode: http.StatusBadRequest},
+		{name: "invalid quantity", body: `{"holdings": [{"series": "EDO0834", "purchase_day": 1, "quantity": -1}]}`, wantCode: http.StatusBadRequest},
+		{name: "unknown series", body: `{"holdings": [{"series": "XYZ9999", "purchase_day": 1, "quantity": 1}]}`, wantCode: http.StatusBadRequest},
+		{name: "invalid purchase day", body: `{"holdings": [{"series": "TOS1125", "purchase_day": 31, "quantity": 1}]}`, wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/portfolio/valuation?"+tt.query, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			server.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("got status %d, want %d; body: %s", w.Code, tt.wantCode, w.Body.String())
+			}
+		})
+	}
+}
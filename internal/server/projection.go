@@ -0,0 +1,194 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/calculator"
+	"github.com/maciekmm/obligacje/civil"
+	"github.com/maciekmm/obligacje/index"
+)
+
+// ProjectionRequest is the body of a projection with per-year rate curves.
+// Rates are yearly percentages keyed by calendar year.
+type ProjectionRequest struct {
+	To            string          `json:"to"`
+	Wrapper       string          `json:"wrapper"`
+	Inflation     map[int]float64 `json:"inflation"`
+	ReferenceRate map[int]float64 `json:"reference_rate"`
+}
+
+type ProjectionResponse struct {
+	Name           string  `json:"name"`
+	ISIN           string  `json:"isin"`
+	ProjectedAt    string  `json:"projected_at"`
+	MaturityDate   string  `json:"maturity_date"`
+	Price          float64 `json:"price"`
+	PaidCoupons    float64 `json:"paid_coupons"`
+	NetPrice       float64 `json:"net_price"`
+	NetPaidCoupons float64 `json:"net_paid_coupons"`
+	DerivedRates   bool    `json:"derived_rates"`
+	Currency       string  `json:"currency"`
+}
+
+const maxProjectionRequestSize = 64 << 10
+
+// maxScenarioRate bounds assumed yearly rates in percent either way,
+// which keeps values compounded over decades within the range of decimals.
+const maxScenarioRate = 100
+
+func (s *Server) handleProjection(w http.ResponseWriter, r *http.Request) {
+	taxation, ok := taxationFromQuery(w, r)
+	if !ok {
+		return
+	}
+
+	var scenario calculator.Indices
+	if scenario.Inflation, ok = constantFromQuery(w, r, "inflation"); !ok {
+		return
+	}
+	if scenario.ReferenceRate, ok = constantFromQuery(w, r, "reference_rate"); !ok {
+		return
+	}
+
+	bnd, purchaseDay, ok := s.lookupPurchasedBond(w, r)
+	if !ok {
+		return
+	}
+
+	s.project(w, r, bnd, purchaseDay, r.URL.Query().Get("to"), taxation, scenario)
+}
+
+func (s *Server) handleProjectionCurve(w http.ResponseWriter, r *http.Request) {
+	var req ProjectionRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxProjectionRequestSize)).Decode(&req); err != nil {
+		s.log.Info("invalid projection request", "err", err)
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	taxation, err := calculator.ParseTaxation(req.Wrapper)
+	if err != nil {
+		http.Error(w, "invalid wrapper", http.StatusBadRequest)
+		return
+	}
+
+	for _, curve := range []map[int]float64{req.Inflation, req.ReferenceRate} {
+		for _, rate := range curve {
+			if !validScenarioRate(rate) {
+				http.Error(w, "invalid rate", http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
+	var scenario calculator.Indices
+	if len(req.Inflation) > 0 {
+		scenario.Inflation = yearlyCurve(req.Inflation)
+	}
+	if len(req.ReferenceRate) > 0 {
+		scenario.ReferenceRate = yearlyCurve(req.ReferenceRate)
+	}
+
+	bnd, purchaseDay, ok := s.lookupPurchasedBond(w, r)
+	if !ok {
+		return
+	}
+
+	s.project(w, r, bnd, purchaseDay, req.To, taxation, scenario)
+}
+
+// constantFromQuery reads an optional yearly rate in percent from the query parameter, see validScenarioRate.
+// On failure it writes the error response and returns false.
+func constantFromQuery(w http.ResponseWriter, r *http.Request, param string) (index.Index, bool) {
+	q := r.URL.Query().Get(param)
+	if q == "" {
+		return nil, true
+	}
+	rate, err := strconv.ParseFloat(q, 64)
+	if err != nil || !validScenarioRate(rate) {
+		http.Error(w, "invalid "+param, http.StatusBadRequest)
+		return nil, false
+	}
+	return index.Constant(rate / 100), true
+}
+
+// validScenarioRate reports whether an assumed yearly rate in percent is within maxScenarioRate.
+func validScenarioRate(rate float64) bool {
+	return rate >= -maxScenarioRate && rate <= maxScenarioRate
+}
+
+func yearlyCurve(rates map[int]float64) index.Yearly {
+	curve := make(index.Yearly, len(rates))
+	for year, rate := range rates {
+		curve[year] = bond.Percentage(rate / 100)
+	}
+	return curve
+}
+
+// project writes the value of the bond at to (maturity if empty or later) under the rate scenario.
+func (s *Server) project(w http.ResponseWriter, r *http.Request, bnd bond.Bond, purchaseDay int, to string, taxation calculator.Taxation, scenario calculator.Indices) {
+	_, maturity, err := bnd.Period(bnd.InterestPeriodCount()-1, purchaseDay)
+	if err != nil {
+		s.log.Warn("error calculating maturity", "name", bnd.Name, "purchase_day", purchaseDay, "err", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	projectedAt := maturity
+	if to != "" {
+		projectedAt, err = civil.Parse(to)
+		if err != nil {
+			http.Error(w, "invalid to", http.StatusBadRequest)
+			return
+		}
+		if projectedAt.After(maturity) {
+			// bonds don't earn interest after maturity
+			projectedAt = maturity
+		}
+	}
+
+	calc := s.calc.WithScenario(scenario)
+	valuation, err := calc.Valuate(bnd, purchaseDay, projectedAt)
+	if errors.Is(err, calculator.ErrValuationDateBeforePurchaseDate) {
+		http.Error(w, "to is before purchase date", http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, calculator.ErrValuationDateAfterMaturity) {
+		s.log.Info("projection past known rates", "name", bnd.Name, "purchase_day", purchaseDay, "projected_at", projectedAt)
+		http.Error(w, "interest rates up to the date are unknown, provide inflation or reference_rate", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		s.log.Warn("error projecting price", "name", bnd.Name, "purchase_day", purchaseDay, "projected_at", projectedAt, "err", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	net, err := calc.NetValue(bnd, purchaseDay, projectedAt, taxation)
+	if err != nil {
+		s.log.Warn("error projecting net value", "name", bnd.Name, "purchase_day", purchaseDay, "projected_at", projectedAt, "err", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	s.log.Info("projected bond", "name", bnd.Name, "purchase_day", purchaseDay, "projected_at", projectedAt, "price", valuation.Price)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ProjectionResponse{
+		Name:           r.PathValue("name"),
+		ISIN:           bnd.ISIN,
+		ProjectedAt:    projectedAt.Format("2006-01-02"),
+		MaturityDate:   maturity.Format("2006-01-02"),
+		Price:          float64(valuation.Price),
+		PaidCoupons:    float64(valuation.PaidCoupons),
+		NetPrice:       float64(net.Net),
+		NetPaidCoupons: float64(taxation.NetCoupons(valuation)),
+		DerivedRates:   valuation.Derived,
+		Currency:       "PLN",
+	})
+}
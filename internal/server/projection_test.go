@@ -0,0 +1,184 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandleProjection(t *testing.T) {
+	server := loadTestServer(t)
+
+	tests := []struct {
+		name             string
+		bondName         string
+		query            string
+		wantProjectedAt  string
+		wantPrice        float64
+		wantNetPrice     float64
+		wantDerivedRates bool
+	}{
+		{
+			name:             "EDO at maturity with constant inflation",
+			bondName:         "EDO103501",
+			query:            "inflation=3",
+			wantProjectedAt:  "2035-10-01",
+			wantPrice:        164.44,
+			wantNetPrice:     152.20,
+			wantDerivedRates: true,
+		},
+		{
+			name:            "fixed rate TOS needs no assumptions",
+			bondName:        "TOS112501",
+			query:           "to=2030-01-01",
+			wantProjectedAt: "2025-11-01",
+			wantPrice:       121.99,
+			wantNetPrice:    117.81,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := fmt.Sprintf("/v1/bond/%s/projection?%s", tt.bondName, tt.query)
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+			w := httptest.NewRecorder()
+
+			server.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("got status %d, want 200; body: %s", w.Code, w.Body.String())
+			}
+
+			var resp ProjectionResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode JSON: %v", err)
+			}
+
+			if resp.ProjectedAt != tt.wantProjectedAt {
+				t.Errorf("got projected_at %q, want %q", resp.ProjectedAt, tt.wantProjectedAt)
+			}
+			if math.Abs(resp.Price-tt.wantPrice) > 1e-9 {
+				t.Errorf("got price %v, want %v", resp.Price, tt.wantPrice)
+			}
+			if math.Abs(resp.NetPrice-tt.wantNetPrice) > 1e-9 {
+				t.Errorf("got net_price %v, want %v", resp.NetPrice, tt.wantNetPrice)
+			}
+			if resp.DerivedRates != tt.wantDerivedRates {
+				t.Errorf("got derived_rates %v, want %v", resp.DerivedRates, tt.wantDerivedRates)
+			}
+		})
+	}
+}
+
+func TestHandleProjectionCurve(t *testing.T) {
+	server := loadTestServer(t)
+
+	project := func(t *testing.T, body string) ProjectionResponse {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/v1/bond/EDO103501/projection", strings.NewReader(body))
+		w := httptest.NewRecorder()
+
+		server.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("got status %d, want 200; body: %s", w.Code, w.Body.String())
+		}
+		var resp ProjectionResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode JSON: %v", err)
+		}
+		return resp
+	}
+
+	flat := project(t, `{"inflation": {"2025": 3}}`)
+	// the same as a constant inflation of 3%
+	if math.Abs(flat.Price-164.44) > 1e-9 {
+		t.Errorf("got flat curve price %v, want 164.44", flat.Price)
+	}
+
+	curve := project(t, `{"to": "2030-10-01", "wrapper": "ike", "inflation": {"2025": 3, "2027": 5}}`)
+	if curve.ProjectedAt != "2030-10-01" {
+		t.Errorf("got projected_at %q, want 2030-10-01", curve.ProjectedAt)
+	}
+	// 6% in the first year, then 3% + 2% margin once and 5% + 2% margin three times
+	if math.Abs(curve.Price-136.35) > 1e-9 {
+		t.Errorf("got curve price %v, want 136.35", curve.Price)
+	}
+	// early redemption fee, no tax in IKE
+	if math.Abs(curve.NetPrice-134.35) > 1e-9 {
+		t.Errorf("got curve net_price %v, want 134.35", curve.NetPrice)
+	}
+}
+
+func TestHandleProjection_Errors(t *testing.T) {
+	server := loadTestServer(t)
+
+	tests := []struct {
+		name     string
+		method   string
+		bondName string
+		query    string
+		body     string
+		wantCode int
+	}{
+		{
+			name:     "rates unknown without assumptions",
+			method:   http.MethodGet,
+			bondName: "EDO103501",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid inflation",
+			method:   http.MethodGet,
+			bondName: "EDO103501",
+			query:    "inflation=abc",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid to",
+			method:   http.MethodGet,
+			bondName: "EDO103501",
+			query:    "inflation=3&to=not-a-date",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "to before purchase",
+			method:   http.MethodGet,
+			bondName: "EDO103501",
+			query:    "inflation=3&to=2025-09-01",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid body",
+			method:   http.MethodPost,
+			bondName: "EDO103501",
+			body:     `{"inflation": {"next year": 3}}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "bond not found",
+			method:   http.MethodPost,
+			bondName: "NONEXIST01",
+			body:     `{}`,
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := fmt.Sprintf("/v1/bond/%s/projection?%s", tt.bondName, tt.query)
+			req := httptest.NewRequest(tt.method, url, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			server.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("got status %d, want %d; body: %s", w.Code, tt.wantCode, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/maciekmm/obligacje/calculator"
+	"github.com/maciekmm/obligacje/civil"
+)
+
+type RedemptionResponse struct {
+	Name       string  `json:"name"`
+	ISIN       string  `json:"isin"`
+	RedeemedAt string  `json:"redeemed_at"`
+	Early      bool    `json:"early"`
+	GrossValue float64 `json:"gross_value"`
+	Fee        float64 `json:"fee"`
+	Tax        float64 `json:"tax"`
+	Payout     float64 `json:"payout"`
+	Quantity   int     `json:"quantity"`
+	Currency   string  `json:"currency"`
+
+	Total RedemptionTotalResponse `json:"total"`
+}
+
+// RedemptionTotalResponse is the redemption of all bonds redeemed, calculated per bond and then multiplied.
+type RedemptionTotalResponse struct {
+	GrossValue float64 `json:"gross_value"`
+	Fee        float64 `json:"fee"`
+	Tax        float64 `json:"tax"`
+	Payout     float64 `json:"payout"`
+}
+
+func (s *Server) handleRedemption(w http.ResponseWriter, r *http.Request) {
+	var redeemedAt civil.Date
+	var err error
+	if dateQ := r.URL.Query().Get("date"); dateQ != "" {
+		redeemedAt, err = civil.Parse(dateQ)
+		if err != nil {
+			http.Error(w, "invalid date", http.StatusBadRequest)
+			return
+		}
+	} else {
+		redeemedAt = civil.Today()
+	}
+
+	quantity, ok := quantityFromQuery(w, r)
+	if !ok {
+		return
+	}
+
+	taxation, ok := taxationFromQuery(w, r)
+	if !ok {
+		return
+	}
+
+	bnd, purchaseDay, ok := s.lookupPurchasedBond(w, r)
+	if !ok {
+		return
+	}
+
+	redemption, err := s.calc.Redeem(bnd, purchaseDay, redeemedAt, taxation)
+	if errors.Is(err, calculator.ErrValuationDateBeforePurchaseDate) {
+		s.log.Info("redemption date before purchase date", "name", bnd.Name, "purchase_day", purchaseDay, "redeemed_at", redeemedAt)
+		http.Error(w, "redemption date is before purchase date", http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, calculator.ErrEarlyRedemptionNotAllowed) {
+		s.log.Info("early redemption not allowed", "name", bnd.Name, "purchase_day", purchaseDay, "redeemed_at", redeemedAt)
+		http.Error(w, "bond can't be redeemed before maturity", http.StatusBadRequest)
+		return
+	}
+	if err != nil && !errors.Is(err, calculator.ErrValuationDateAfterMaturity) {
+		s.log.Warn("error calculating redemption", "name", bnd.Name, "purchase_day", purchaseDay, "redeemed_at", redeemedAt, "err", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	total, err := redemptionTotalResponse(redemption, quantity)
+	if err != nil {
+		s.log.Warn("error calculating total", "name", bnd.Name, "purchase_day", purchaseDay, "quantity", quantity, "redeemed_at", redeemedAt, "err", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	s.log.Info("redeemed bond", "name", bnd.Name, "purchase_day", purchaseDay, "quantity", quantity, "redeemed_at", redeemedAt, "gross", redemption.Gross, "fee", redemption.Fee, "tax", redemption.Tax)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(RedemptionResponse{
+		Name:       r.PathValue("name"),
+		ISIN:       bnd.ISIN,
+		RedeemedAt: redeemedAt.Format("2006-01-02"),
+		Early:      redemption.Early,
+		GrossValue: float64(redemption.Gross),
+		Fee:        float64(redemption.Fee),
+		Tax:        float64(redemption.Tax),
+		Payout:     float64(redemption.Net),
+		Quantity:   quantity,
+		Currency:   "PLN",
+		Total:      total,
+	})
+}
+
+func redemptionTotalResponse(redemption calculator.Redemption, quantity int) (RedemptionTotalResponse, error) {
+	total, err := redemption.Times(quantity)
+	if err != nil {
+		return RedemptionTotalResponse{}, err
+	}
+	return RedemptionTotalResponse{
+		GrossValue: float64(total.Gross),
+		Fee:        float64(total.Fee),
+		Tax:        float64(total.Tax),
+		Payout:     float64(total.Net),
+	}, nil
+}
@@ -0,0 +1,187 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandleRedemption(t *testing.T) {
+	server := loadTestServer(t)
+
+	tests := []struct {
+		name       string
+		bondName   string
+		query      string
+		date       string
+		wantEarly  bool
+		wantGross  float64
+		wantFee    float64
+		wantTax    float64
+		wantPayout float64
+	}{
+		{
+			name:       "EDO redeemed early",
+			bondName:   "EDO083401",
+			date:       "2025-08-01",
+			wantEarly:  true,
+			wantGross:  106.80,
+			wantFee:    2.00,
+			wantTax:    0.91,
+			wantPayout: 103.89,
+		},
+		{
+			name:       "EDO redeemed early from IKZE",
+			bondName:   "EDO083401",
+			query:      "&wrapper=ikze",
+			date:       "2025-08-01",
+			wantEarly:  true,
+			wantGross:  106.80,
+			wantFee:    2.00,
+			wantPayout: 104.80,
+		},
+		{
+			name:       "TOS redeemed after maturity",
+			bondName:   "TOS112501",
+			date:       "2025-12-01",
+			wantGross:  121.99,
+			wantTax:    4.18,
+			wantPayout: 117.81,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := fmt.Sprintf("/v1/bond/%s/redemption?date=%s%s", tt.bondName, tt.date, tt.query)
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+			w := httptest.NewRecorder()
+
+			server.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("got status %d, want 200; body: %s", w.Code, w.Body.String())
+			}
+
+			var resp RedemptionResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode JSON: %v", err)
+			}
+
+			if resp.RedeemedAt != tt.date {
+				t.Errorf("got redeemed_at %q, want %q", resp.RedeemedAt, tt.date)
+			}
+			if resp.Early != tt.wantEarly {
+				t.Errorf("got early %v, want %v", resp.Early, tt.wantEarly)
+			}
+			if math.Abs(resp.GrossValue-tt.wantGross) > 1e-9 {
+				t.Errorf("got gross_value %v, want %v", resp.GrossValue, tt.wantGross)
+			}
+			if math.Abs(resp.Fee-tt.wantFee) > 1e-9 {
+				t.Errorf("got fee %v, want %v", resp.Fee, tt.wantFee)
+			}
+			if math.Abs(resp.Tax-tt.wantTax) > 1e-9 {
+				t.Errorf("got tax %v, want %v", resp.Tax, tt.wantTax)
+			}
+			if math.Abs(resp.Payout-tt.wantPayout) > 1e-9 {
+				t.Errorf("got payout %v, want %v", resp.Payout, tt.wantPayout)
+			}
+		})
+	}
+}
+
+func TestHandleRedemption_Errors(t *testing.T) {
+	server := loadTestServer(t)
+
+	tests := []struct {
+		name     string
+		bondName string
+		query    string
+		wantCode int
+	}{
+		{
+			name:     "invalid date",
+			bondName: "EDO083401",
+			query:    "date=not-a-date",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid quantity",
+			bondName: "EDO083401",
+			query:    "date=2025-08-01&quantity=abc",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "date before purchase",
+			bondName: "EDO093502",
+			query:    "date=2025-09-01",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "OTS can't be redeemed early",
+			bondName: "OTS082501",
+			query:    "date=2025-07-01",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "bond not found",
+			bondName: "NONEXIST01",
+			query:    "date=2025-07-01",
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := fmt.Sprintf("/v1/bond/%s/redemption?%s", tt.bondName, tt.query)
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+			w := httptest.NewRecorder()
+
+			server.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("got status %d, want %d; body: %s", w.Code, tt.wantCode, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandleRedemption_Quantity(t *testing.T) {
+	server := loadTestServer(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/bond/EDO083401/redemption?date=2025-08-01&quantity=3", nil)
+	w := httptest.NewRecorder()
+
+	server.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+
+	var resp RedemptionResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode JSON: %v", err)
+	}
+
+	if resp.Quantity != 3 {
+		t.Errorf("got quantity %d, want 3", resp.Quantity)
+	}
+	prices := []struct {
+		field string
+		got   float64
+		want  float64
+	}{
+		{field: "payout", got: resp.Payout, want: 103.89},
+		{field: "total.gross_value", got: resp.Total.GrossValue, want: 320.40},
+		{field: "total.fee", got: resp.Total.Fee, want: 6.00},
+		{field: "total.tax", got: resp.Total.Tax, want: 2.73},
+		{field: "total.payout", got: resp.Total.Payout, want: 311.67},
+	}
+	for _, p := range prices {
+		if math.Abs(p.got-p.want) > 1e-9 {
+			t.Errorf("got %s %v, want %v", p.field, p.got, p.want)
+		}
+	}
+}
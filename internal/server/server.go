@@ -15,10 +15,12 @@ type Server struct {
 	log     *slog.Logger
 }
 
-func NewServer(repo bond.Repository, logger *slog.Logger) *Server {
+// NewServer creates a server valuating bonds from repo. The calculator options configure
+// market indices used to derive interest rates the issuer hasn't published yet.
+func NewServer(repo bond.Repository, logger *slog.Logger, opts ...calculator.Option) *Server {
 	server := &Server{
 		repo:    repo,
-		calc:    calculator.NewCalculator(),
+		calc:    calculator.NewCalculator(opts...),
 		handler: http.NewServeMux(),
 		log:     logger,
 	}
@@ -34,12 +36,27 @@ func (s *Server) setupRoutes() {
 	})
 	s.handler.HandleFunc("GET /v1/bond/{name}/valuation", s.handleValuation)
 	s.handler.HandleFunc("GET /v1/bond/{name}/historical", s.handleHistorical)
+	s.handler.HandleFunc("GET /v1/bond/{name}/cashflows", s.handleCashFlows)
+	s.handler.HandleFunc("GET /v1/bond/{name}/redemption", s.handleRedemption)
+	s.handler.HandleFunc("GET /v1/bond/{name}/exchange", s.handleExchange)
+	s.handler.HandleFunc("GET /v1/bond/{name}/yield", s.handleYield)
+	s.handler.HandleFunc("GET /v1/bond/{name}/simulation", s.handleSimulation)
+	s.handler.HandleFunc("GET /v1/bond/{name}/calendar", s.handleCalendar)
+	s.handler.HandleFunc("GET /v1/bond/{name}/projection", s.handleProjection)
+	s.handler.HandleFunc("POST /v1/bond/{name}/projection", s.handleProjectionCurve)
 	s.handler.HandleFunc("GET /v1/bond/{name}", s.handleMetadata)
+	s.handler.HandleFunc("GET /v1/compare", s.handleCompare)
+	s.handler.HandleFunc("POST /v1/portfolio/valuation", s.handlePortfolioValuation)
+	s.handler.HandleFunc("POST /v1/portfolio/history", s.handlePortfolioHistory)
+	s.handler.HandleFunc("POST /v1/portfolio/taxes", s.handlePortfolioTaxes)
+	s.handler.HandleFunc("POST /v1/portfolio/import", s.handlePortfolioImport)
+	s.handler.HandleFunc("GET /v1/portfolio/calendar", s.handlePortfolioCalendar)
+	s.handler.HandleFunc("POST /v1/portfolio/calendar", s.handlePortfolioCalendar)
 }
 
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
 	w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type")
 
 	if r.Method == "OPTIONS" {
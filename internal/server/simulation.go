@@ -0,0 +1,176 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/maciekmm/obligacje/calculator"
+	"github.com/maciekmm/obligacje/civil"
+	"github.com/maciekmm/obligacje/decimal"
+)
+
+// maxSimulationYears limits how far simulations walk forward from the purchase.
+const maxSimulationYears = 30
+
+type SimulationResponse struct {
+	Name     string                   `json:"name"`
+	ISIN     string                   `json:"isin"`
+	Rule     string                   `json:"rule"`
+	Until    string                   `json:"until"`
+	Quantity int                      `json:"quantity"`
+	Steps    []SimulationStepResponse `json:"steps"`
+	Currency string                   `json:"currency"`
+}
+
+type SimulationStepResponse struct {
+	Date         string                    `json:"date"`
+	Events       []SimulationEventResponse `json:"events"`
+	Holdings     []HoldingResponse         `json:"holdings"`
+	Cash         float64                   `json:"cash"`
+	Value        float64                   `json:"value"`
+	NetValue     float64                   `json:"net_value"`
+	DerivedRates bool                      `json:"derived_rates"`
+}
+
+type SimulationEventResponse struct {
+	Kind           string  `json:"kind"`
+	Bond           string  `json:"bond"`
+	Quantity       int     `json:"quantity"`
+	Amount         float64 `json:"amount"`
+	Target         string  `json:"target,omitempty"`
+	TargetQuantity int     `json:"target_quantity,omitempty"`
+}
+
+type HoldingResponse struct {
+	Name      string  `json:"name"`
+	ISIN      string  `json:"isin,omitempty"`
+	Quantity  int     `json:"quantity"`
+	CostBasis float64 `json:"cost_basis"`
+	Assumed   bool    `json:"assumed"`
+	Value     float64 `json:"value"`
+	NetValue  float64 `json:"net_value"`
+}
+
+func (s *Server) handleSimulation(w http.ResponseWriter, r *http.Request) {
+	untilQ := r.URL.Query().Get("until")
+	if untilQ == "" {
+		http.Error(w, "missing until", http.StatusBadRequest)
+		return
+	}
+	until, err := civil.Parse(untilQ)
+	if err != nil {
+		http.Error(w, "invalid until", http.StatusBadRequest)
+		return
+	}
+
+	rule, err := calculator.ParseRule(r.URL.Query().Get("rule"))
+	if err != nil {
+		http.Error(w, "invalid rule", http.StatusBadRequest)
+		return
+	}
+
+	quantity, ok := quantityFromQuery(w, r)
+	if !ok {
+		return
+	}
+
+	taxation, ok := taxationFromQuery(w, r)
+	if !ok {
+		return
+	}
+
+	var scenario calculator.Indices
+	if scenario.Inflation, ok = constantFromQuery(w, r, "inflation"); !ok {
+		return
+	}
+	if scenario.ReferenceRate, ok = constantFromQuery(w, r, "reference_rate"); !ok {
+		return
+	}
+
+	bnd, purchaseDay, ok := s.lookupPurchasedBond(w, r)
+	if !ok {
+		return
+	}
+
+	purchaseDate := civil.New(bnd.SaleStart.Year(), bnd.SaleStart.Month(), purchaseDay)
+	if until.After(purchaseDate.AddDate(maxSimulationYears, 0, 0)) {
+		http.Error(w, "until must be within 30 years of the purchase", http.StatusBadRequest)
+		return
+	}
+
+	steps, err := s.calc.WithScenario(scenario).Simulate(s.repo, bnd, purchaseDay, quantity, until, rule, taxation)
+	if errors.Is(err, calculator.ErrValuationDateBeforePurchaseDate) {
+		http.Error(w, "until is before purchase date", http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, calculator.ErrValuationDateAfterMaturity) {
+		s.log.Info("simulation past known rates", "name", bnd.Name, "purchase_day", purchaseDay, "until", until, "err", err)
+		http.Error(w, "interest rates up to the date are unknown, provide inflation or reference_rate", http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, decimal.ErrOverflow) {
+		s.log.Info("simulated amounts too large", "name", bnd.Name, "purchase_day", purchaseDay, "quantity", quantity, "until", until)
+		http.Error(w, "simulated amounts are too large, lower quantity or the assumed rates", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		s.log.Warn("error simulating bond", "name", bnd.Name, "purchase_day", purchaseDay, "until", until, "rule", rule, "err", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	resp := SimulationResponse{
+		Name:     r.PathValue("name"),
+		ISIN:     bnd.ISIN,
+		Rule:     rule.String(),
+		Until:    until.Format("2006-01-02"),
+		Quantity: quantity,
+		Steps:    make([]SimulationStepResponse, len(steps)),
+		Currency: "PLN",
+	}
+	for i, step := range steps {
+		resp.Steps[i] = simulationStepResponse(step)
+	}
+
+	s.log.Info("simulated bond", "name", bnd.Name, "purchase_day", purchaseDay, "quantity", quantity, "until", until, "rule", rule, "value", steps[len(steps)-1].Value)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
+
+func simulationStepResponse(step calculator.SimulationStep) SimulationStepResponse {
+	resp := SimulationStepResponse{
+		Date:         step.Date.Format("2006-01-02"),
+		Events:       make([]SimulationEventResponse, len(step.Events)),
+		Holdings:     make([]HoldingResponse, len(step.Holdings)),
+		Cash:         float64(step.Cash),
+		Value:        float64(step.Value),
+		NetValue:     float64(step.NetValue),
+		DerivedRates: step.Derived,
+	}
+	for i, e := range step.Events {
+		resp.Events[i] = SimulationEventResponse{
+			Kind:           string(e.Kind),
+			Bond:           e.Bond,
+			Quantity:       e.Quantity,
+			Amount:         float64(e.Amount),
+			Target:         e.Target,
+			TargetQuantity: e.TargetQuantity,
+		}
+	}
+	for i, h := range step.Holdings {
+		resp.Holdings[i] = HoldingResponse{
+			Name:      fmt.Sprintf("%s%02d", h.Bond.Name, h.PurchaseDay),
+			ISIN:      h.Bond.ISIN,
+			Quantity:  h.Quantity,
+			CostBasis: float64(h.CostBasis),
+			Assumed:   h.Assumed,
+			Value:     float64(h.Value),
+			NetValue:  float64(h.NetValue),
+		}
+	}
+	return resp
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandleSimulation(t *testing.T) {
+	server := loadTestServer(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/bond/TOS112501/simulation?until=2026-03-01&quantity=10", nil)
+	w := httptest.NewRecorder()
+
+	server.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+
+	var resp SimulationResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode JSON: %v", err)
+	}
+
+	if resp.Rule != "roll_over" || resp.Quantity != 10 {
+		t.Errorf("got rule %q and quantity %d, want roll_over and 10", resp.Rule, resp.Quantity)
+	}
+	if len(resp.Steps) != 5 {
+		t.Fatalf("got %d steps, want 5", len(resp.Steps))
+	}
+
+	rollOver := resp.Steps[3]
+	if rollOver.Date != "2025-11-01" || len(rollOver.Events) != 1 || rollOver.Events[0].Kind != "roll_over" {
+		t.Fatalf("got step %+v, want a roll over on 2025-11-01", rollOver)
+	}
+	if got := rollOver.Events[0]; got.Target != "TOS1128" || got.TargetQuantity != 10 || math.Abs(got.Amount-179.10) > 1e-9 {
+		t.Errorf("got roll over %+v, want 10 bonds of TOS1128 and 179.10 in cash", got)
+	}
+	if got := rollOver.Holdings; len(got) != 1 || got[0].Name != "TOS112801" || got[0].CostBasis != 99.90 {
+		t.Errorf("got holdings %+v, want TOS112801 at 99.90", got)
+	}
+
+	last := resp.Steps[len(resp.Steps)-1]
+	if last.Date != "2026-03-01" || math.Abs(last.Value-1195.20) > 1e-9 {
+		t.Errorf("got last step %s worth %v, want 2026-03-01 worth 1195.20", last.Date, last.Value)
+	}
+}
+
+func TestHandleSimulation_Errors(t *testing.T) {
+	server := loadTestServer(t)
+
+	tests := []struct {
+		name     string
+		bondName string
+		query    string
+		wantCode int
+	}{
+		{name: "missing until", bondName: "TOS112501", query: "", wantCode: http.StatusBadRequest},
+		{name: "invalid until", bondName: "TOS112501", query: "until=2026-13-01", wantCode: http.StatusBadRequest},
+		{name: "invalid rule", bondName: "TOS112501", query: "until=2026-03-01&rule=ladder", wantCode: http.StatusBadRequest},
+		{name: "invalid quantity", bondName: "TOS112501", query: "until=2026-03-01&quantity=0", wantCode: http.StatusBadRequest},
+		{name: "invalid inflation", bondName: "EDO083401", query: "until=2030-01-01&inflation=abc", wantCode: http.StatusBadRequest},
+		{name: "inflation too high", bondName: "EDO083401", query: "until=2030-01-01&inflation=1000", wantCode: http.StatusBadRequest},
+		{name: "inflation not a number", bondName: "EDO083401", query: "until=2030-01-01&inflation=NaN", wantCode: http.StatusBadRequest},
+		{name: "amounts too large", bondName: "EDO083401", query: "until=2054-08-01&inflation=100&rule=reinvest&quantity=1000000", wantCode: http.StatusBadRequest},
+		{name: "until before purchase", bondName: "TOS112501", query: "until=2022-10-31", wantCode: http.StatusBadRequest},
+		{name: "until too far", bondName: "TOS112501", query: "until=2052-11-02", wantCode: http.StatusBadRequest},
+		{name: "rates unknown", bondName: "EDO083401", query: "until=2030-01-01", wantCode: http.StatusBadRequest},
+		{name: "bond not found", bondName: "XYZ999901", query: "until=2030-01-01", wantCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := fmt.Sprintf("/v1/bond/%s/simulation?%s", tt.bondName, tt.query)
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+			w := httptest.NewRecorder()
+
+			server.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("got status %d, want %d; body: %s", w.Code, tt.wantCode, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/maciekmm/obligacje/civil"
+	"github.com/maciekmm/obligacje/portfolio"
+)
+
+const xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+type TaxReportResponse struct {
+	Year     int                `json:"year"`
+	Events   []TaxEventResponse `json:"events"`
+	Total    TaxTotalsResponse  `json:"total"`
+	Currency string             `json:"currency"`
+}
+
+type TaxEventResponse struct {
+	Date         string  `json:"date"`
+	Kind         string  `json:"kind"`
+	Name         string  `json:"name"`
+	ISIN         string  `json:"isin"`
+	Account      string  `json:"account,omitempty"`
+	Wrapper      string  `json:"wrapper,omitempty"`
+	Quantity     int     `json:"quantity"`
+	Interest     float64 `json:"interest"`
+	Fee          float64 `json:"fee"`
+	Tax          float64 `json:"tax"`
+	Net          float64 `json:"net"`
+	Payout       float64 `json:"payout"`
+	DerivedRates bool    `json:"derived_rates"`
+}
+
+type TaxTotalsResponse struct {
+	Interest float64 `json:"interest"`
+	Fee      float64 `json:"fee"`
+	Tax      float64 `json:"tax"`
+	Net      float64 `json:"net"`
+	Payout   float64 `json:"payout"`
+}
+
+func (s *Server) handlePortfolioTaxes(w http.ResponseWriter, r *http.Request) {
+	yearQ := r.URL.Query().Get("year")
+	if yearQ == "" {
+		http.Error(w, "missing year", http.StatusBadRequest)
+		return
+	}
+	year, err := strconv.Atoi(yearQ)
+	if err != nil || year < 1 {
+		http.Error(w, "invalid year", http.StatusBadRequest)
+		return
+	}
+	if year > civil.Today().Year() {
+		http.Error(w, "year must not be after the current year", http.StatusBadRequest)
+		return
+	}
+
+	p, ok := s.portfolioFromBody(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := portfolio.ReportTaxes(s.repo, s.calc, p, year)
+	if !s.checkPortfolioError(w, err) {
+		return
+	}
+
+	s.log.Info("reported portfolio taxes", "holdings_no", len(p.Holdings), "year", year, "events_no", len(report.Events), "tax", report.Total.Tax)
+
+	accept := r.Header.Get("Accept")
+	switch {
+	case strings.Contains(accept, "text/csv"):
+		w.Header().Set("Content-Type", "text/csv")
+		w.WriteHeader(http.StatusOK)
+		report.WriteCSV(w)
+		return
+	case strings.Contains(accept, xlsxContentType):
+		w.Header().Set("Content-Type", xlsxContentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="taxes-%d.xlsx"`, year))
+		w.WriteHeader(http.StatusOK)
+		if err := report.WriteXLSX(w); err != nil {
+			s.log.Warn("error writing tax report", "year", year, "err", err)
+		}
+		return
+	}
+
+	resp := TaxReportResponse{
+		Year:   year,
+		Events: make([]TaxEventResponse, len(report.Events)),
+		Total: TaxTotalsResponse{
+			Interest: float64(report.Total.Interest),
+			Fee:      float64(report.Total.Fee),
+			Tax:      float64(report.Total.Tax),
+			Net:      float64(report.Total.Net),
+			Payout:   float64(report.Total.Payout),
+		},
+		Currency: "PLN",
+	}
+	for i, e := range report.Events {
+		resp.Events[i] = TaxEventResponse{
+			Date:         e.Date.Format("2006-01-02"),
+			Kind:         string(e.Kind),
+			Name:         e.Holding.Name(),
+			ISIN:         e.Bond.ISIN,
+			Account:      e.Holding.Account,
+			Wrapper:      e.Holding.Wrapper,
+			Quantity:     e.Holding.Quantity,
+			Interest:     float64(e.Interest),
+			Fee:          float64(e.Fee),
+			Tax:          float64(e.Tax),
+			Net:          float64(e.Net),
+			Payout:       float64(e.Payout),
+			DerivedRates: e.Derived,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
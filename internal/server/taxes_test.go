@@ -0,0 +1,130 @@
+package server
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const testTaxedPortfolio = `{
+  "holdings": [
+    {"series": "COI0528", "purchase_day": 1, "quantity": 5},
+    {"series": "TOS1125", "purchase_day": 1, "quantity": 3, "exchanged_into": "TOS1128"},
+    {"series": "EDO0834", "purchase_day": 1, "quantity": 10, "redeemed_at": "2025-09-15", "account": "main"}
+  ]
+}`
+
+func TestHandlePortfolioTaxes(t *testing.T) {
+	server := loadTestServer(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/portfolio/taxes?year=2025", strings.NewReader(testTaxedPortfolio))
+	w := httptest.NewRecorder()
+
+	server.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+
+	var resp TaxReportResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode JSON: %v", err)
+	}
+
+	if len(resp.Events) != 3 {
+		t.Fatalf("got %d events, want 3", len(resp.Events))
+	}
+	if e := resp.Events[1]; e.Kind != "early_redemption" || e.Name != "EDO083401" || e.Account != "main" || e.Date != "2025-09-15" {
+		t.Errorf("got event %+v, want the early redemption of EDO083401", e)
+	}
+	if e := resp.Events[2]; e.Kind != "exchange" || math.Abs(e.Payout-53.73) > 1e-9 {
+		t.Errorf("got event %+v, want the exchange paying out 53.73", e)
+	}
+	// 6.20 from the coupon, 10.60 from the early redemption and 12.54 from the exchange
+	if math.Abs(resp.Total.Tax-29.34) > 1e-9 {
+		t.Errorf("got total tax %v, want 29.34", resp.Total.Tax)
+	}
+}
+
+func TestHandlePortfolioTaxes_Formats(t *testing.T) {
+	server := loadTestServer(t)
+
+	t.Run("csv", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/v1/portfolio/taxes?year=2025", strings.NewReader(testTaxedPortfolio))
+		req.Header.Set("Accept", "text/csv")
+		w := httptest.NewRecorder()
+
+		server.ServeHTTP(w, req)
+
+		if got := w.Header().Get("Content-Type"); w.Code != http.StatusOK || got != "text/csv" {
+			t.Fatalf("got status %d with %s, want 200 with text/csv; body: %s", w.Code, got, w.Body.String())
+		}
+		records, err := csv.NewReader(w.Body).ReadAll()
+		if err != nil {
+			t.Fatalf("failed to read CSV: %v", err)
+		}
+		if len(records) != 4 || records[1][1] != "coupon" {
+			t.Errorf("got records %v, want a header and 3 events starting with the coupon", records)
+		}
+	})
+
+	t.Run("xlsx", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/v1/portfolio/taxes?year=2025", strings.NewReader(testTaxedPortfolio))
+		req.Header.Set("Accept", xlsxContentType)
+		w := httptest.NewRecorder()
+
+		server.ServeHTTP(w, req)
+
+		if got := w.Header().Get("Content-Type"); w.Code != http.StatusOK || got != xlsxContentType {
+			t.Fatalf("got status %d with %s, want 200 with %s", w.Code, got, xlsxContentType)
+		}
+		f, err := excelize.OpenReader(w.Body)
+		if err != nil {
+			t.Fatalf("failed to open XLSX: %v", err)
+		}
+		defer f.Close()
+		rows, err := f.GetRows("2025")
+		if err != nil {
+			t.Fatalf("failed to read rows: %v", err)
+		}
+		if len(rows) != 5 {
+			t.Errorf("got %d rows, want a header, 3 events and totals", len(rows))
+		}
+	})
+}
+
+func TestHandlePortfolioTaxes_Errors(t *testing.T) {
+	server := loadTestServer(t)
+
+	tests := []struct {
+		name     string
+		query    string
+		body     string
+		wantCode int
+	}{
+		{name: "missing year", body: testTaxedPortfolio, wantCode: http.StatusBadRequest},
+		{name: "invalid year", query: "year=last", body: testTaxedPortfolio, wantCode: http.StatusBadRequest},
+		{name: "future year", query: "year=2999", body: testTaxedPortfolio, wantCode: http.StatusBadRequest},
+		{name: "target not on sale", query: "year=2025", body: `{"holdings": [{"series": "TOS1125", "purchase_day": 1, "quantity": 1, "exchanged_into": "TOS1127"}]}`, wantCode: http.StatusBadRequest},
+		{name: "redeemed after maturity", query: "year=2025", body: `{"holdings": [{"series": "TOS1125", "purchase_day": 1, "quantity": 1, "redeemed_at": "2025-12-01"}]}`, wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/portfolio/taxes?"+tt.query, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			server.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("got status %d, want %d; body: %s", w.Code, tt.wantCode, w.Body.String())
+			}
+		})
+	}
+}
@@ -2,7 +2,11 @@ package server
 
 import (
 	"errors"
+	"net/http"
 	"strconv"
+
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/calculator"
 )
 
 func extractPurchaseDayFromName(name string) (int, error) {
@@ -22,3 +26,71 @@ func extractPurchaseDayFromName(name string) (int, error) {
 
 	return purchasedDay, nil
 }
+
+// lookupPurchasedBond resolves the bond and purchase day from the {name} path value,
+// e.g. COI052515. On failure it writes the error response and returns false.
+func (s *Server) lookupPurchasedBond(w http.ResponseWriter, r *http.Request) (bond.Bond, int, bool) {
+	nameWithPurchaseDay := r.PathValue("name")
+	purchaseDay, err := extractPurchaseDayFromName(nameWithPurchaseDay)
+	if err != nil {
+		s.log.Info("invalid name", "name", nameWithPurchaseDay, "err", err)
+		http.Error(w, "invalid name", http.StatusBadRequest)
+		return bond.Bond{}, 0, false
+	}
+	name := nameWithPurchaseDay[:len(nameWithPurchaseDay)-2]
+
+	bnd, err := s.repo.Lookup(name)
+	if errors.Is(err, bond.ErrNameNotFound) {
+		s.log.Info("bond not found", "name", name)
+		http.Error(w, "invalid name", http.StatusNotFound)
+		return bond.Bond{}, 0, false
+	}
+	if err != nil {
+		s.log.Info("error looking up bond", "name", name, "err", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return bond.Bond{}, 0, false
+	}
+
+	return bnd, purchaseDay, true
+}
+
+// boolFromQuery reads an optional boolean flag from the query parameter, e.g. explain=true.
+// On failure it writes the error response and returns false.
+func boolFromQuery(w http.ResponseWriter, r *http.Request, param string) (bool, bool) {
+	q := r.URL.Query().Get(param)
+	if q == "" {
+		return false, true
+	}
+	value, err := strconv.ParseBool(q)
+	if err != nil {
+		http.Error(w, "invalid "+param, http.StatusBadRequest)
+		return false, false
+	}
+	return value, true
+}
+
+// quantityFromQuery reads the optional number of bonds from the quantity query parameter, defaulting to 1.
+// Quantities above calculator.MaxQuantity are rejected. On failure it writes the error response and returns false.
+func quantityFromQuery(w http.ResponseWriter, r *http.Request) (int, bool) {
+	q := r.URL.Query().Get("quantity")
+	if q == "" {
+		return 1, true
+	}
+	quantity, err := strconv.Atoi(q)
+	if err != nil || quantity < 1 || quantity > calculator.MaxQuantity {
+		http.Error(w, "invalid quantity", http.StatusBadRequest)
+		return 0, false
+	}
+	return quantity, true
+}
+
+// taxationFromQuery resolves the taxation from the optional wrapper query parameter, e.g. wrapper=ike.
+// On failure it writes the error response and returns false.
+func taxationFromQuery(w http.ResponseWriter, r *http.Request) (calculator.Taxation, bool) {
+	taxation, err := calculator.ParseTaxation(r.URL.Query().Get("wrapper"))
+	if err != nil {
+		http.Error(w, "invalid wrapper", http.StatusBadRequest)
+		return calculator.Taxable, false
+	}
+	return taxation, true
+}
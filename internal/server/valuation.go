@@ -6,84 +6,282 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
-	"time"
 
-	"github.com/maciekmm/obligacje/bond"
 	"github.com/maciekmm/obligacje/calculator"
-	"github.com/maciekmm/obligacje/tz"
+	"github.com/maciekmm/obligacje/civil"
 )
 
 type ValuationResponse struct {
-	Name       string  `json:"name"`
-	ISIN       string  `json:"isin"`
-	ValuatedAt string  `json:"valuated_at"`
-	Price      float64 `json:"price"`
-	Currency   string  `json:"currency"`
+	Name           string  `json:"name"`
+	ISIN           string  `json:"isin"`
+	ValuatedAt     string  `json:"valuated_at"`
+	Price          float64 `json:"price"`
+	PaidCoupons    float64 `json:"paid_coupons"`
+	NetPrice       float64 `json:"net_price"`
+	NetPaidCoupons float64 `json:"net_paid_coupons"`
+	DerivedRates   bool    `json:"derived_rates"`
+	Quantity       int     `json:"quantity"`
+	Currency       string  `json:"currency"`
+
+	Total     ValuationTotalResponse `json:"total"`
+	Lifecycle LifecycleResponse      `json:"lifecycle"`
+
+	Real        *RealValueResponse   `json:"real,omitempty"`
+	Explanation *ExplanationResponse `json:"explanation,omitempty"`
+}
+
+// ValuationTotalResponse is the value of all bonds valuated, rounded per bond and then multiplied.
+type ValuationTotalResponse struct {
+	Price          float64 `json:"price"`
+	PaidCoupons    float64 `json:"paid_coupons"`
+	NetPrice       float64 `json:"net_price"`
+	NetPaidCoupons float64 `json:"net_paid_coupons"`
+}
+
+// LifecycleResponse describes where the bond is in its life at the valuation date.
+// Dates of events that won't happen anymore are left out.
+type LifecycleResponse struct {
+	Status                string `json:"status"`
+	CurrentPeriod         *int   `json:"current_period,omitempty"`
+	NextRateReset         string `json:"next_rate_reset,omitempty"`
+	NextInterest          string `json:"next_interest,omitempty"`
+	NextInterestTreatment string `json:"next_interest_treatment,omitempty"`
+	MaturityDate          string `json:"maturity_date"`
+}
+
+// RealValueResponse is the value of a single bond in PLN of its purchase date.
+type RealValueResponse struct {
+	PricesAt                 string  `json:"prices_at"`
+	Inflation                float64 `json:"inflation"`
+	Value                    float64 `json:"value"`
+	NetValue                 float64 `json:"net_value"`
+	PreservedPurchasingPower bool    `json:"preserved_purchasing_power"`
+}
+
+// ExplanationResponse breaks the price down into the interest of each period walked.
+// Amounts are unrounded unless stated otherwise.
+type ExplanationResponse struct {
+	Periods        []PeriodExplanationResponse `json:"periods"`
+	UnroundedPrice float64                     `json:"unrounded_price"`
+	Rounding       float64                     `json:"rounding"`
+}
+
+type PeriodExplanationResponse struct {
+	Index             int     `json:"index"`
+	Start             string  `json:"start"`
+	End               string  `json:"end"`
+	Rate              float64 `json:"rate"`
+	RateDerived       bool    `json:"rate_derived"`
+	HeldDays          int     `json:"held_days"`
+	PeriodDays        int     `json:"period_days"`
+	Principal         float64 `json:"principal"`
+	UnroundedInterest float64 `json:"unrounded_interest"`
+	Interest          float64 `json:"interest"`
+	Rounding          float64 `json:"rounding"`
+	Treatment         string  `json:"treatment"`
+	Price             float64 `json:"price"`
 }
 
 func (s *Server) handleValuation(w http.ResponseWriter, r *http.Request) {
-	var valuatedAt time.Time
+	var valuatedAt civil.Date
 	var err error
 	if valAtQ := r.URL.Query().Get("valuated_at"); valAtQ != "" {
-		valuatedAt, err = time.ParseInLocation("2006-01-02", valAtQ, tz.UnifiedTimezone)
+		valuatedAt, err = civil.Parse(valAtQ)
 		if err != nil {
 			http.Error(w, "invalid valuated_at", http.StatusBadRequest)
 			return
 		}
 	} else {
-		valuatedAt = time.Now().In(tz.UnifiedTimezone)
+		valuatedAt = civil.Today()
 	}
 
-	nameWithPurchaseDay := r.PathValue("name")
-	purchaseDay, err := extractPurchaseDayFromName(nameWithPurchaseDay)
-	if err != nil {
-		s.log.Info("invalid name", "name", nameWithPurchaseDay, "err", err)
-		http.Error(w, "invalid name", http.StatusBadRequest)
+	explain, ok := boolFromQuery(w, r, "explain")
+	if !ok {
 		return
 	}
-	name := nameWithPurchaseDay[:len(nameWithPurchaseDay)-2]
 
-	bnd, err := s.repo.Lookup(name)
-	if errors.Is(err, bond.ErrNameNotFound) {
-		s.log.Info("bond not found", "name", name)
-		http.Error(w, "invalid name", http.StatusNotFound)
+	realTerms, ok := boolFromQuery(w, r, "real")
+	if !ok {
 		return
 	}
-	if err != nil {
-		s.log.Info("error looking up bond", "name", name, "err", err)
-		http.Error(w, "internal error", http.StatusInternalServerError)
+
+	quantity, ok := quantityFromQuery(w, r)
+	if !ok {
+		return
+	}
+
+	taxation, ok := taxationFromQuery(w, r)
+	if !ok {
 		return
 	}
 
-	price, err := s.calc.Calculate(bnd, purchaseDay, valuatedAt)
+	bnd, purchaseDay, ok := s.lookupPurchasedBond(w, r)
+	if !ok {
+		return
+	}
+
+	var explanation calculator.Explanation
+	var valuation calculator.Valuation
+	if explain {
+		explanation, err = s.calc.Explain(bnd, purchaseDay, valuatedAt)
+		valuation = explanation.Valuation
+	} else {
+		valuation, err = s.calc.Valuate(bnd, purchaseDay, valuatedAt)
+	}
 	if errors.Is(err, calculator.ErrValuationDateBeforePurchaseDate) {
-		s.log.Info("valuation date before purchase date", "name", name, "purchase_day", purchaseDay, "valuated_at", valuatedAt)
+		s.log.Info("valuation date before purchase date", "name", bnd.Name, "purchase_day", purchaseDay, "valuated_at", valuatedAt)
 		http.Error(w, "valuation date is before purchase date", http.StatusBadRequest)
 		return
 	}
 	if err != nil && !errors.Is(err, calculator.ErrValuationDateAfterMaturity) {
-		s.log.Warn("error calculating price", "name", name, "purchase_day", purchaseDay, "valuated_at", valuatedAt, "err", err)
+		s.log.Warn("error calculating price", "name", bnd.Name, "purchase_day", purchaseDay, "valuated_at", valuatedAt, "err", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	net, err := s.calc.NetValue(bnd, purchaseDay, valuatedAt, taxation)
+	if err != nil && !errors.Is(err, calculator.ErrValuationDateAfterMaturity) {
+		s.log.Warn("error calculating net value", "name", bnd.Name, "purchase_day", purchaseDay, "valuated_at", valuatedAt, "err", err)
 		http.Error(w, "internal error", http.StatusInternalServerError)
 		return
 	}
 
-	s.log.Info("valuated bond", "name", name, "purchase_day", purchaseDay, "valuated_at", valuatedAt, "price", price)
+	lifecycle, err := s.calc.Lifecycle(bnd, purchaseDay, valuatedAt)
+	if err != nil {
+		s.log.Warn("error calculating lifecycle", "name", bnd.Name, "purchase_day", purchaseDay, "valuated_at", valuatedAt, "err", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	var realValue calculator.RealValue
+	if realTerms {
+		realValue, err = s.calc.RealValue(bnd, purchaseDay, valuatedAt, taxation)
+		if errors.Is(err, calculator.ErrInflationUnknown) {
+			s.log.Info("inflation unknown", "name", bnd.Name, "purchase_day", purchaseDay, "valuated_at", valuatedAt)
+			http.Error(w, "inflation since purchase is unknown", http.StatusBadRequest)
+			return
+		}
+		if err != nil && !errors.Is(err, calculator.ErrValuationDateAfterMaturity) {
+			s.log.Warn("error calculating real value", "name", bnd.Name, "purchase_day", purchaseDay, "valuated_at", valuatedAt, "err", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	total, err := valuationTotalResponse(valuation, net, taxation, quantity)
+	if err != nil {
+		s.log.Warn("error calculating total", "name", bnd.Name, "purchase_day", purchaseDay, "quantity", quantity, "valuated_at", valuatedAt, "err", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	s.log.Info("valuated bond", "name", bnd.Name, "purchase_day", purchaseDay, "quantity", quantity, "valuated_at", valuatedAt, "price", valuation.Price, "paid_coupons", valuation.PaidCoupons, "net_price", net.Net)
 
 	accept := r.Header.Get("Accept")
-	if strings.Contains(accept, "application/json") {
+	if explain || realTerms || strings.Contains(accept, "application/json") {
+		resp := ValuationResponse{
+			Name:           r.PathValue("name"),
+			ISIN:           bnd.ISIN,
+			ValuatedAt:     valuatedAt.Format("2006-01-02"),
+			Price:          float64(valuation.Price),
+			PaidCoupons:    float64(valuation.PaidCoupons),
+			NetPrice:       float64(net.Net),
+			NetPaidCoupons: float64(taxation.NetCoupons(valuation)),
+			DerivedRates:   valuation.Derived,
+			Quantity:       quantity,
+			Currency:       "PLN",
+			Total:          total,
+			Lifecycle:      lifecycleResponse(lifecycle),
+		}
+		if realTerms {
+			resp.Real = realValueResponse(realValue)
+		}
+		if explain {
+			resp.Explanation = explanationResponse(explanation)
+		}
+
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(ValuationResponse{
-			Name:       nameWithPurchaseDay,
-			ISIN:       bnd.ISIN,
-			ValuatedAt: valuatedAt.Format("2006-01-02"),
-			Price:      float64(price),
-			Currency:   "PLN",
-		})
+		json.NewEncoder(w).Encode(resp)
 		return
 	}
 
 	w.Header().Set("Content-Type", "text/plain")
 	w.WriteHeader(http.StatusOK)
-	fmt.Fprintf(w, "%.2f", price)
+	fmt.Fprintf(w, "%.2f", total.Price)
+}
+
+func valuationTotalResponse(valuation calculator.Valuation, net calculator.Redemption, taxation calculator.Taxation, quantity int) (ValuationTotalResponse, error) {
+	total, err := valuation.Times(quantity)
+	if err != nil {
+		return ValuationTotalResponse{}, err
+	}
+	netPrice, err := net.Net.Times(quantity)
+	if err != nil {
+		return ValuationTotalResponse{}, err
+	}
+	netPaidCoupons, err := taxation.NetCoupons(valuation).Times(quantity)
+	if err != nil {
+		return ValuationTotalResponse{}, err
+	}
+	return ValuationTotalResponse{
+		Price:          float64(total.Price),
+		PaidCoupons:    float64(total.PaidCoupons),
+		NetPrice:       float64(netPrice),
+		NetPaidCoupons: float64(netPaidCoupons),
+	}, nil
+}
+
+func lifecycleResponse(lifecycle calculator.Lifecycle) LifecycleResponse {
+	resp := LifecycleResponse{
+		Status:                string(lifecycle.Status),
+		NextInterestTreatment: string(lifecycle.NextInterestTreatment),
+		MaturityDate:          lifecycle.Maturity.Format("2006-01-02"),
+	}
+	if lifecycle.Period >= 0 {
+		resp.CurrentPeriod = &lifecycle.Period
+	}
+	if !lifecycle.NextRateReset.IsZero() {
+		resp.NextRateReset = lifecycle.NextRateReset.Format("2006-01-02")
+	}
+	if !lifecycle.NextInterest.IsZero() {
+		resp.NextInterest = lifecycle.NextInterest.Format("2006-01-02")
+	}
+	return resp
+}
+
+func realValueResponse(realValue calculator.RealValue) *RealValueResponse {
+	return &RealValueResponse{
+		PricesAt:                 realValue.PricesAt.Format("2006-01"),
+		Inflation:                float64(realValue.Inflation.Round()),
+		Value:                    float64(realValue.Value),
+		NetValue:                 float64(realValue.Net),
+		PreservedPurchasingPower: realValue.Preserved,
+	}
+}
+
+func explanationResponse(explanation calculator.Explanation) *ExplanationResponse {
+	periods := make([]PeriodExplanationResponse, len(explanation.Periods))
+	for i, p := range explanation.Periods {
+		periods[i] = PeriodExplanationResponse{
+			Index:             p.Index,
+			Start:             p.Start.Format("2006-01-02"),
+			End:               p.End.Format("2006-01-02"),
+			Rate:              float64(p.Rate),
+			RateDerived:       p.RateDerived,
+			HeldDays:          p.HeldDays,
+			PeriodDays:        p.PeriodDays,
+			Principal:         p.Principal.Float64(),
+			UnroundedInterest: p.Unrounded.Float64(),
+			Interest:          p.Interest.Float64(),
+			Rounding:          (p.Interest - p.Unrounded).Float64(),
+			Treatment:         string(p.Treatment),
+			Price:             p.Price.Float64(),
+		}
+	}
+	return &ExplanationResponse{
+		Periods:        periods,
+		UnroundedPrice: explanation.Unrounded.Float64(),
+		Rounding:       (explanation.Valuation.Price.Decimal() - explanation.Unrounded).Float64(),
+	}
 }
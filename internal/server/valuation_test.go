@@ -12,6 +12,8 @@ import (
 	"testing"
 
 	"github.com/maciekmm/obligacje/bondxls"
+	"github.com/maciekmm/obligacje/calculator"
+	"github.com/maciekmm/obligacje/index"
 	"github.com/maciekmm/obligacje/internal/testutil"
 )
 
@@ -122,6 +124,13 @@ func TestHandleValuation_JSON(t *testing.T) {
 			wantPrice: 101.56,
 			wantCode:  http.StatusOK,
 		},
+		{
+			name:      "OTS bond at maturity",
+			bondName:  "OTS082501",
+			valuateAt: "2025-08-01",
+			wantPrice: 100.76,
+			wantCode:  http.StatusOK,
+		},
 	}
 
 	for _, tt := range tests {
@@ -165,6 +174,92 @@ func TestHandleValuation_JSON(t *testing.T) {
 	}
 }
 
+func TestHandleValuation_Coupons(t *testing.T) {
+	server := loadTestServer(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/bond/COI052801/valuation?valuated_at=2025-11-01", nil)
+	req.Header.Set("Accept", "application/json")
+	w := httptest.NewRecorder()
+
+	server.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+
+	var resp ValuationResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode JSON response: %v", err)
+	}
+
+	if math.Abs(resp.Price-103.10) > 1e-9 {
+		t.Errorf("got price %v, want 103.10", resp.Price)
+	}
+	if math.Abs(resp.PaidCoupons-6.55) > 1e-9 {
+		t.Errorf("got paid_coupons %v, want 6.55", resp.PaidCoupons)
+	}
+}
+
+func TestHandleValuation_NetOfTax(t *testing.T) {
+	server := loadTestServer(t)
+
+	tests := []struct {
+		name               string
+		bondName           string
+		query              string
+		wantNetPrice       float64
+		wantNetPaidCoupons float64
+	}{
+		{
+			name:               "COI early redemption fee and tax on coupons",
+			bondName:           "COI052801",
+			query:              "valuated_at=2025-11-01",
+			wantNetPrice:       101.94,
+			wantNetPaidCoupons: 5.31,
+		},
+		{
+			name:               "COI held in IKE",
+			bondName:           "COI052801",
+			query:              "valuated_at=2025-11-01&wrapper=ike",
+			wantNetPrice:       102.40,
+			wantNetPaidCoupons: 6.55,
+		},
+		{
+			name:         "OTS before maturity is taxed without a fee",
+			bondName:     "OTS082501",
+			query:        "valuated_at=2025-06-15",
+			wantNetPrice: 100.30,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := fmt.Sprintf("/v1/bond/%s/valuation?%s", tt.bondName, tt.query)
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+			req.Header.Set("Accept", "application/json")
+			w := httptest.NewRecorder()
+
+			server.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("got status %d, want 200; body: %s", w.Code, w.Body.String())
+			}
+
+			var resp ValuationResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode JSON response: %v", err)
+			}
+
+			if math.Abs(resp.NetPrice-tt.wantNetPrice) > 1e-9 {
+				t.Errorf("got net_price %v, want %v", resp.NetPrice, tt.wantNetPrice)
+			}
+			if math.Abs(resp.NetPaidCoupons-tt.wantNetPaidCoupons) > 1e-9 {
+				t.Errorf("got net_paid_coupons %v, want %v", resp.NetPaidCoupons, tt.wantNetPaidCoupons)
+			}
+		})
+	}
+}
+
 func TestHandleValuation_Errors(t *testing.T) {
 	server := loadTestServer(t)
 
@@ -196,6 +291,27 @@ func TestHandleValuation_Errors(t *testing.T) {
 			accept:   "text/plain",
 			wantCode: http.StatusBadRequest,
 		},
+		{
+			name:     "invalid quantity",
+			bondName: "EDO083401",
+			query:    "valuated_at=2025-12-06&quantity=-1",
+			accept:   "application/json",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "quantity too large",
+			bondName: "EDO083412",
+			query:    "valuated_at=2025-12-06&quantity=1000000000",
+			accept:   "application/json",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid explain",
+			bondName: "EDO083401",
+			query:    "valuated_at=2025-12-06&explain=maybe",
+			accept:   "application/json",
+			wantCode: http.StatusBadRequest,
+		},
 		{
 			name:     "invalid name too short",
 			bondName: "AB01",
@@ -217,6 +333,13 @@ func TestHandleValuation_Errors(t *testing.T) {
 			accept:   "text/plain",
 			wantCode: http.StatusNotFound,
 		},
+		{
+			name:     "unknown wrapper",
+			bondName: "EDO083412",
+			query:    "valuated_at=2025-12-06&wrapper=ppk",
+			accept:   "application/json",
+			wantCode: http.StatusBadRequest,
+		},
 		{
 			name:     "valuation date before purchase date",
 			bondName: "EDO093502",
@@ -224,6 +347,21 @@ func TestHandleValuation_Errors(t *testing.T) {
 			accept:   "application/json",
 			wantCode: http.StatusBadRequest,
 		},
+		{
+			name:     "invalid real",
+			bondName: "EDO083401",
+			query:    "valuated_at=2025-12-06&real=maybe",
+			accept:   "application/json",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			// the test server has no CPI
+			name:     "real value without inflation",
+			bondName: "EDO083401",
+			query:    "valuated_at=2025-12-06&real=true",
+			accept:   "application/json",
+			wantCode: http.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range tests {
@@ -310,3 +448,273 @@ func TestHandleValuation_DefaultDate(t *testing.T) {
 		t.Errorf("expected positive price, got %v", resp.Price)
 	}
 }
+
+func TestHandleValuation_DerivedRates(t *testing.T) {
+	cpi, err := index.LoadCPI(testutil.CPIDataFile())
+	if err != nil {
+		t.Fatalf("failed to load CPI: %v", err)
+	}
+	referenceRate, err := index.LoadReferenceRate(testutil.ReferenceRateDataFile())
+	if err != nil {
+		t.Fatalf("failed to load reference rate: %v", err)
+	}
+	server := NewServer(unpublishedRepository{loadTestServer(t).repo}, slog.Default(),
+		calculator.WithInflation(cpi), calculator.WithReferenceRate(referenceRate))
+
+	tests := []struct {
+		name      string
+		bondName  string
+		valuateAt string
+		wantPrice float64
+	}{
+		{
+			name:      "COI rate derived from CPI",
+			bondName:  "COI052801",
+			valuateAt: "2025-11-01",
+			wantPrice: 103.10,
+		},
+		{
+			name:      "ROR rate derived from the NBP reference rate",
+			bondName:  "ROR122601",
+			valuateAt: "2026-01-16",
+			wantPrice: 100.16,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := fmt.Sprintf("/v1/bond/%s/valuation?valuated_at=%s", tt.bondName, tt.valuateAt)
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+			req.Header.Set("Accept", "application/json")
+			w := httptest.NewRecorder()
+
+			server.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("got status %d, want 200; body: %s", w.Code, w.Body.String())
+			}
+
+			var resp ValuationResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode JSON response: %v", err)
+			}
+
+			if !resp.DerivedRates {
+				t.Error("expected derived_rates to be true")
+			}
+			if math.Abs(resp.Price-tt.wantPrice) > 1e-9 {
+				t.Errorf("got price %v, want %v", resp.Price, tt.wantPrice)
+			}
+		})
+	}
+}
+
+func TestHandleValuation_Explain(t *testing.T) {
+	server := loadTestServer(t)
+
+	// no Accept header, explanations are always JSON
+	req := httptest.NewRequest(http.MethodGet, "/v1/bond/COI052801/valuation?valuated_at=2025-11-01&explain=true", nil)
+	w := httptest.NewRecorder()
+
+	server.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+
+	var resp ValuationResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode JSON response: %v", err)
+	}
+	if resp.Explanation == nil {
+		t.Fatal("expected explanation")
+	}
+	if math.Abs(resp.Price-103.10) > 1e-9 {
+		t.Errorf("got price %v, want 103.10", resp.Price)
+	}
+
+	periods := resp.Explanation.Periods
+	if len(periods) != 2 {
+		t.Fatalf("got %d periods, want 2", len(periods))
+	}
+	if periods[0].Start != "2024-05-01" || periods[0].End != "2025-05-01" || periods[0].Treatment != "paid_out" {
+		t.Errorf("got first period %+v, want 2024-05-01 - 2025-05-01 paid out", periods[0])
+	}
+	accrued := periods[1]
+	if accrued.HeldDays != 184 || accrued.PeriodDays != 365 || accrued.Treatment != "accrued" {
+		t.Errorf("got second period %+v, want 184 of 365 days accrued", accrued)
+	}
+	if math.Abs(accrued.UnroundedInterest-3.10027397) > 1e-9 || math.Abs(accrued.Interest-3.10) > 1e-9 {
+		t.Errorf("got interest %v rounded to %v, want 3.10027397 rounded to 3.10", accrued.UnroundedInterest, accrued.Interest)
+	}
+	if math.Abs(accrued.Rounding+0.00027397) > 1e-9 {
+		t.Errorf("got rounding %v, want -0.00027397", accrued.Rounding)
+	}
+}
+
+func TestHandleValuation_Quantity(t *testing.T) {
+	server := loadTestServer(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/bond/COI052801/valuation?valuated_at=2025-11-01&quantity=1000", nil)
+	req.Header.Set("Accept", "application/json")
+	w := httptest.NewRecorder()
+
+	server.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+
+	var resp ValuationResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode JSON: %v", err)
+	}
+
+	if resp.Quantity != 1000 {
+		t.Errorf("got quantity %d, want 1000", resp.Quantity)
+	}
+	prices := []struct {
+		field string
+		got   float64
+		want  float64
+	}{
+		{field: "price", got: resp.Price, want: 103.10},
+		{field: "total.price", got: resp.Total.Price, want: 103100.00},
+		{field: "total.paid_coupons", got: resp.Total.PaidCoupons, want: 6550.00},
+		// 103.10 - 0.70 fee - 19% of 2.40
+		{field: "total.net_price", got: resp.Total.NetPrice, want: 101940.00},
+		// 6.55 - 19% of 6.55
+		{field: "total.net_paid_coupons", got: resp.Total.NetPaidCoupons, want: 5310.00},
+	}
+	for _, p := range prices {
+		if math.Abs(p.got-p.want) > 1e-9 {
+			t.Errorf("got %s %v, want %v", p.field, p.got, p.want)
+		}
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/bond/COI052801/valuation?valuated_at=2025-11-01&quantity=1000", nil)
+	w = httptest.NewRecorder()
+
+	server.ServeHTTP(w, req)
+
+	if got := w.Body.String(); got != "103100.00" {
+		t.Errorf("got plain text body %q, want 103100.00", got)
+	}
+}
+
+func TestHandleValuation_RealValue(t *testing.T) {
+	cpi, err := index.LoadCPI(testutil.CPIDataFile())
+	if err != nil {
+		t.Fatalf("failed to load CPI: %v", err)
+	}
+	server := NewServer(loadTestServer(t).repo, slog.Default(), calculator.WithInflation(cpi))
+
+	// no Accept header, real values are always JSON
+	req := httptest.NewRequest(http.MethodGet, "/v1/bond/EDO083401/valuation?valuated_at=2025-08-15&real=true", nil)
+	w := httptest.NewRecorder()
+
+	server.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+
+	var resp ValuationResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode JSON response: %v", err)
+	}
+	if resp.Real == nil {
+		t.Fatal("expected real value")
+	}
+	if resp.Real.PricesAt != "2025-08" {
+		t.Errorf("got prices_at %s, want 2025-08", resp.Real.PricesAt)
+	}
+	if math.Abs(resp.Real.Value-104.03) > 1e-9 {
+		t.Errorf("got real value %v, want 104.03", resp.Real.Value)
+	}
+	if math.Abs(resp.Real.NetValue-101.16) > 1e-9 {
+		t.Errorf("got real net value %v, want 101.16", resp.Real.NetValue)
+	}
+	if !resp.Real.PreservedPurchasingPower {
+		t.Error("expected purchasing power to be preserved")
+	}
+	if resp.Real.Value >= resp.Price {
+		t.Errorf("got real value %v, want less than nominal price %v", resp.Real.Value, resp.Price)
+	}
+}
+
+func TestHandleValuation_Lifecycle(t *testing.T) {
+	server := loadTestServer(t)
+
+	tests := []struct {
+		name     string
+		bondName string
+		query    string
+		want     LifecycleResponse
+	}{
+		{
+			name:     "series on sale",
+			bondName: "EDO083401",
+			query:    "valuated_at=2024-08-20",
+			want: LifecycleResponse{
+				Status:                "on_sale",
+				CurrentPeriod:         new(int),
+				NextRateReset:         "2025-08-01",
+				NextInterest:          "2025-08-01",
+				NextInterestTreatment: "capitalised",
+				MaturityDate:          "2034-08-01",
+			},
+		},
+		{
+			name:     "fixed rate bond",
+			bondName: "TOS112501",
+			query:    "valuated_at=2022-12-01",
+			want: LifecycleResponse{
+				Status:                "active",
+				CurrentPeriod:         new(int),
+				NextInterest:          "2023-11-01",
+				NextInterestTreatment: "capitalised",
+				MaturityDate:          "2025-11-01",
+			},
+		},
+		{
+			name:     "matured bond",
+			bondName: "OTS022505",
+			query:    "valuated_at=2025-03-01",
+			want: LifecycleResponse{
+				Status:       "matured",
+				MaturityDate: "2025-02-05",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := fmt.Sprintf("/v1/bond/%s/valuation?%s", tt.bondName, tt.query)
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+			req.Header.Set("Accept", "application/json")
+			w := httptest.NewRecorder()
+
+			server.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("got status %d, want 200; body: %s", w.Code, w.Body.String())
+			}
+
+			var resp ValuationResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode JSON response: %v", err)
+			}
+
+			got := resp.Lifecycle
+			if (got.CurrentPeriod == nil) != (tt.want.CurrentPeriod == nil) ||
+				got.CurrentPeriod != nil && *got.CurrentPeriod != *tt.want.CurrentPeriod {
+				t.Errorf("got current_period %v, want %v", got.CurrentPeriod, tt.want.CurrentPeriod)
+			}
+			got.CurrentPeriod, tt.want.CurrentPeriod = nil, nil
+			if got != tt.want {
+				t.Errorf("got lifecycle %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
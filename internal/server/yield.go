@@ -0,0 +1,118 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/calculator"
+	"github.com/maciekmm/obligacje/civil"
+)
+
+type YieldResponse struct {
+	Name             string  `json:"name"`
+	ISIN             string  `json:"isin"`
+	From             string  `json:"from"`
+	To               string  `json:"to"`
+	Net              bool    `json:"net"`
+	Invested         float64 `json:"invested"`
+	Coupons          float64 `json:"coupons"`
+	FinalValue       float64 `json:"final_value"`
+	TotalReturn      float64 `json:"total_return"`
+	AnnualisedReturn float64 `json:"annualised_return"`
+	IRR              float64 `json:"irr"`
+	DerivedRates     bool    `json:"derived_rates"`
+	Currency         string  `json:"currency"`
+}
+
+func (s *Server) handleYield(w http.ResponseWriter, r *http.Request) {
+	net, ok := boolFromQuery(w, r, "net")
+	if !ok {
+		return
+	}
+
+	taxation, ok := taxationFromQuery(w, r)
+	if !ok {
+		return
+	}
+
+	bnd, purchaseDay, ok := s.lookupPurchasedBond(w, r)
+	if !ok {
+		return
+	}
+
+	purchaseDate, _, err := bnd.Period(0, purchaseDay)
+	if err != nil {
+		s.log.Info("invalid purchase day", "name", bnd.Name, "purchase_day", purchaseDay, "err", err)
+		http.Error(w, "invalid name", http.StatusBadRequest)
+		return
+	}
+	_, maturity, err := bnd.Period(bnd.InterestPeriodCount()-1, purchaseDay)
+	if err != nil {
+		s.log.Warn("error calculating maturity", "name", bnd.Name, "purchase_day", purchaseDay, "err", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	from := purchaseDate
+	if fromQ := r.URL.Query().Get("from"); fromQ != "" {
+		from, err = civil.Parse(fromQ)
+		if err != nil {
+			http.Error(w, "invalid from", http.StatusBadRequest)
+			return
+		}
+	}
+	to := civil.Today()
+	if toQ := r.URL.Query().Get("to"); toQ != "" {
+		to, err = civil.Parse(toQ)
+		if err != nil {
+			http.Error(w, "invalid to", http.StatusBadRequest)
+			return
+		}
+	}
+	if to.After(maturity) {
+		// bonds don't earn interest after maturity
+		to = maturity
+	}
+
+	yield, err := s.calc.Yield(bnd, purchaseDay, from, to, net, taxation)
+	if errors.Is(err, calculator.ErrValuationDateBeforePurchaseDate) {
+		http.Error(w, "from is before purchase date", http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, calculator.ErrInvalidYieldPeriod) {
+		http.Error(w, "to must be after from", http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, calculator.ErrValuationDateAfterMaturity) {
+		s.log.Info("yield past known rates", "name", bnd.Name, "purchase_day", purchaseDay, "to", to)
+		http.Error(w, "interest rates up to the date are unknown", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		s.log.Warn("error calculating yield", "name", bnd.Name, "purchase_day", purchaseDay, "from", from, "to", to, "err", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	s.log.Info("calculated yield", "name", bnd.Name, "purchase_day", purchaseDay, "from", from, "to", to, "net", net, "irr", yield.IRR)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(YieldResponse{
+		Name:             r.PathValue("name"),
+		ISIN:             bnd.ISIN,
+		From:             from.Format("2006-01-02"),
+		To:               to.Format("2006-01-02"),
+		Net:              net,
+		Invested:         float64(yield.Invested),
+		Coupons:          float64(yield.Coupons),
+		FinalValue:       float64(yield.Final),
+		TotalReturn:      float64(bond.Percentage(yield.Return).Round()),
+		AnnualisedReturn: float64(bond.Percentage(yield.Annualised).Round()),
+		IRR:              float64(bond.Percentage(yield.IRR).Round()),
+		DerivedRates:     yield.Derived,
+		Currency:         "PLN",
+	})
+}
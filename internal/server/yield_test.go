@@ -0,0 +1,118 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandleYield(t *testing.T) {
+	server := loadTestServer(t)
+
+	tests := []struct {
+		name           string
+		bondName       string
+		query          string
+		wantFrom       string
+		wantTo         string
+		wantFinal      float64
+		wantAnnualised float64
+		wantIRR        float64
+	}{
+		{
+			name:           "TOS from purchase, to capped at maturity",
+			bondName:       "TOS112501",
+			query:          "to=2030-01-01",
+			wantFrom:       "2022-11-01",
+			wantTo:         "2025-11-01",
+			wantFinal:      121.99,
+			wantAnnualised: 0.068436,
+			wantIRR:        0.068436,
+		},
+		{
+			name:           "COI net of tax",
+			bondName:       "COI052801",
+			query:          "to=2025-11-01&net=true",
+			wantFrom:       "2024-05-01",
+			wantTo:         "2025-11-01",
+			wantFinal:      101.94,
+			wantAnnualised: 0.047634,
+			wantIRR:        0.048466,
+		},
+		{
+			name:           "COI net in IKE pays only the fee",
+			bondName:       "COI052801",
+			query:          "from=2024-05-01&to=2025-11-01&net=true&wrapper=ike",
+			wantFrom:       "2024-05-01",
+			wantTo:         "2025-11-01",
+			wantFinal:      102.40,
+			wantAnnualised: 0.058645,
+			wantIRR:        0.059904,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := fmt.Sprintf("/v1/bond/%s/yield?%s", tt.bondName, tt.query)
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+			w := httptest.NewRecorder()
+
+			server.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("got status %d, want 200; body: %s", w.Code, w.Body.String())
+			}
+
+			var resp YieldResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode JSON: %v", err)
+			}
+
+			if resp.From != tt.wantFrom || resp.To != tt.wantTo {
+				t.Errorf("got period %s - %s, want %s - %s", resp.From, resp.To, tt.wantFrom, tt.wantTo)
+			}
+			if math.Abs(resp.FinalValue-tt.wantFinal) > 1e-9 {
+				t.Errorf("got final_value %v, want %v", resp.FinalValue, tt.wantFinal)
+			}
+			if math.Abs(resp.AnnualisedReturn-tt.wantAnnualised) > 1e-9 {
+				t.Errorf("got annualised_return %v, want %v", resp.AnnualisedReturn, tt.wantAnnualised)
+			}
+			if math.Abs(resp.IRR-tt.wantIRR) > 1e-9 {
+				t.Errorf("got irr %v, want %v", resp.IRR, tt.wantIRR)
+			}
+		})
+	}
+}
+
+func TestHandleYield_Errors(t *testing.T) {
+	server := loadTestServer(t)
+
+	tests := []struct {
+		name     string
+		url      string
+		wantCode int
+	}{
+		{name: "invalid net", url: "/v1/bond/COI052801/yield?net=maybe", wantCode: http.StatusBadRequest},
+		{name: "invalid from", url: "/v1/bond/COI052801/yield?from=abc", wantCode: http.StatusBadRequest},
+		{name: "from before purchase", url: "/v1/bond/COI052801/yield?from=2024-04-01&to=2025-05-01", wantCode: http.StatusBadRequest},
+		{name: "to before from", url: "/v1/bond/COI052801/yield?from=2025-05-01&to=2025-04-01", wantCode: http.StatusBadRequest},
+		{name: "rates unknown", url: "/v1/bond/COI052801/yield?to=2028-05-01", wantCode: http.StatusBadRequest},
+		{name: "bond not found", url: "/v1/bond/NONEXIST01/yield", wantCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			w := httptest.NewRecorder()
+
+			server.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("got status %d, want %d; body: %s", w.Code, tt.wantCode, w.Body.String())
+			}
+		})
+	}
+}
@@ -10,3 +10,22 @@ func TestDataDirectory() string {
 	dir := filepath.Dir(filename)
 	return filepath.Join(dir, "testdata")
 }
+
+// BondDataFile returns the path of the bond data sheet of the Ministry of Finance
+// kept with the bondxls tests, for packages which don't need a copy of their own.
+func BondDataFile() string {
+	_, filename, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(filename), "..", "..", "bondxls", "testdata", "data.xlsx")
+}
+
+// CPIDataFile returns the path of the GUS inflation table kept with the index tests.
+func CPIDataFile() string {
+	_, filename, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(filename), "..", "..", "index", "testdata", "cpi.csv")
+}
+
+// ReferenceRateDataFile returns the path of the NBP reference rate history kept with the index tests.
+func ReferenceRateDataFile() string {
+	_, filename, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(filename), "..", "..", "index", "testdata", "nbp.csv")
+}
@@ -0,0 +1,46 @@
+package portfolio
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/calculator"
+)
+
+// HoldingEvent is an event in the life of the bonds of a holding, see calculator.Calculator.Calendar.
+type HoldingEvent struct {
+	Event   calculator.CalendarEvent
+	Holding Holding
+	Bond    bond.Bond
+}
+
+// Calendar returns the events in the life of all holdings of the portfolio ordered by date.
+// Bonds redeemed early have no events after the redemption.
+func Calendar(repo bond.Repository, calc *calculator.Calculator, p Portfolio) ([]HoldingEvent, error) {
+	var events []HoldingEvent
+	for _, h := range p.Holdings {
+		bnd, err := repo.Lookup(h.Series)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", h.Name(), err)
+		}
+		if err := checkHolding(bnd, h); err != nil {
+			return nil, fmt.Errorf("%s: %w", h.Name(), err)
+		}
+		calendar, err := calc.Calendar(repo, bnd, h.PurchaseDay)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", h.Name(), err)
+		}
+		for _, e := range calendar {
+			if !h.RedeemedAt.IsZero() && e.Date.After(h.RedeemedAt) {
+				break
+			}
+			events = append(events, HoldingEvent{Event: e, Holding: h, Bond: bnd})
+		}
+	}
+
+	slices.SortStableFunc(events, func(a, b HoldingEvent) int {
+		return a.Event.Date.Compare(b.Event.Date)
+	})
+	return events, nil
+}
@@ -0,0 +1,43 @@
+package portfolio_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maciekmm/obligacje/calculator"
+	"github.com/maciekmm/obligacje/civil"
+	"github.com/maciekmm/obligacje/portfolio"
+)
+
+func TestCalendar(t *testing.T) {
+	p := portfolio.Portfolio{Holdings: []portfolio.Holding{
+		{Series: "TOS1125", PurchaseDay: 1, Quantity: 3},
+		{Series: "COI0528", PurchaseDay: 1, Quantity: 5, RedeemedAt: civil.New(2026, time.June, 1)},
+	}}
+
+	got, err := portfolio.Calendar(LoadBondRepository(), calculator.NewCalculator(), p)
+	if err != nil {
+		t.Fatalf("Calendar() error = %v", err)
+	}
+
+	var kinds []string
+	for _, e := range got {
+		kinds = append(kinds, e.Holding.Series+" "+string(e.Event.Kind)+" "+e.Event.Date.String())
+	}
+	want := []string{
+		"TOS1125 fee_free_end 2022-11-01",
+		"COI0528 fee_free_end 2024-05-01",
+		"COI0528 coupon 2025-05-01",
+		"COI0528 rate_reset 2025-05-01",
+		"COI0528 fee_free_end 2025-05-01",
+		"TOS1125 exchange_deadline 2025-10-31",
+		"TOS1125 maturity 2025-11-01",
+		// no events after the early redemption
+		"COI0528 coupon 2026-05-01",
+		"COI0528 rate_reset 2026-05-01",
+	}
+	if strings.Join(kinds, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Calendar() = %v, want %v", kinds, want)
+	}
+}
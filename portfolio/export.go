@@ -0,0 +1,304 @@
+package portfolio
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/calculator"
+	"github.com/maciekmm/obligacje/civil"
+	"github.com/maciekmm/obligacje/internal/xlsconv"
+	"github.com/xuri/excelize/v2"
+	"golang.org/x/text/encoding/charmap"
+)
+
+var (
+	ErrInvalidExport = errors.New("invalid account export")
+)
+
+// maxUnzippedExportSize limits the size of an XLSX export after unpacking, which is kept in memory.
+// Exports of even thousands of holdings unpack to a few megabytes, while zip bombs are rejected
+// before they exhaust memory or the disk.
+const maxUnzippedExportSize = 32 << 20
+
+var (
+	xlsxMagic = []byte("PK\x03\x04")
+	xlsMagic  = []byte("\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1")
+	utf8BOM   = []byte("\xef\xbb\xbf")
+
+	seriesCode = regexp.MustCompile(`\b[A-Z]{3}\d{4}\b`)
+	// quantityFormat is a whole number of bonds, possibly formatted with a zero fraction such as 10,00.
+	// Three digits after a separator are a thousands separator or a fraction, so they're rejected as ambiguous.
+	quantityFormat = regexp.MustCompile(`^(\d+)(?:[.,]0{1,2})?$`)
+)
+
+// Column headers of the holdings export of the savings bonds account at obligacjeskarbowe.pl,
+// compared after lower-casing and dropping a parenthesised suffix such as "(szt.)".
+var (
+	seriesHeaders       = []string{"emisja", "seria", "kod emisji", "nazwa emisji", "seria obligacji"}
+	purchaseDateHeaders = []string{"data zakupu", "data nabycia", "data zakupu obligacji"}
+	quantityHeaders     = []string{"liczba obligacji", "liczba", "ilość", "ilość obligacji", "dostępne", "dostępnych", "liczba dostępnych obligacji"}
+	accountHeaders      = []string{"rachunek", "numer rachunku", "nr rachunku"}
+)
+
+// purchaseDateLayouts are the formats purchase dates are exported in.
+// Spreadsheets show dates in the format of the cell, with 01-02-06 being the default of excelize.
+var purchaseDateLayouts = []string{civil.Layout, "02.01.2006", "2.1.2006", "_2/01/2006", "01-02-06"}
+
+// UnmatchedRow is a row of an account export which couldn't be turned into a holding.
+type UnmatchedRow struct {
+	// Row is the one-based number of the row in the file.
+	Row   int
+	Cells []string
+	Err   error
+}
+
+// Import are the holdings read from an account export.
+type Import struct {
+	Portfolio Portfolio
+	// Unmatched are the rows following the header which don't describe a bond of the repository.
+	Unmatched []UnmatchedRow
+}
+
+// LoadExport reads holdings from an account export file, see ParseExport.
+// Files in the legacy XLS format are converted to XLSX with LibreOffice first.
+func LoadExport(repo bond.Repository, path string) (Import, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Import{}, err
+	}
+	if !bytes.HasPrefix(data, xlsMagic) {
+		return ParseExport(repo, bytes.NewReader(data))
+	}
+
+	// the converted file is written next to the input, so keep the user's directory clean
+	dir, err := os.MkdirTemp("", "obligacje-export")
+	if err != nil {
+		return Import{}, err
+	}
+	defer os.RemoveAll(dir)
+
+	xlsFile := filepath.Join(dir, "export.xls")
+	if err := os.WriteFile(xlsFile, data, 0600); err != nil {
+		return Import{}, err
+	}
+	xlsxFile, err := xlsconv.ToXLSX(xlsFile)
+	if err != nil {
+		return Import{}, err
+	}
+	f, err := os.Open(xlsxFile)
+	if err != nil {
+		return Import{}, err
+	}
+	defer f.Close()
+
+	return ParseExport(repo, f)
+}
+
+// ParseExport reads holdings from the export of a savings bonds account at obligacjeskarbowe.pl (PKO BP).
+// The export is either an XLSX spreadsheet, of which the first sheet is read, or a CSV file separated
+// with semicolons or commas and encoded in UTF-8 or Windows-1250. Legacy XLS files are not supported, see LoadExport.
+//
+// Rows before the header, the first row naming the series, purchase date and quantity columns, are skipped.
+// Each following row becomes a holding of the bond named in the series column, e.g. EDO0834, bought on
+// the purchase date. Rows which can't be resolved to a bond in the repository, or whose purchase date falls
+// outside the sale of the bond, are reported as unmatched. Empty rows are skipped.
+// Like Parse, it returns ErrInvalidPortfolio if the holdings add up to more than calculator.MaxQuantity bonds.
+func ParseExport(repo bond.Repository, r io.Reader) (Import, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Import{}, err
+	}
+
+	var rows [][]string
+	switch {
+	case bytes.HasPrefix(data, xlsxMagic):
+		rows, err = xlsxRows(data)
+	case bytes.HasPrefix(data, xlsMagic):
+		return Import{}, fmt.Errorf("%w: XLS files must be converted to XLSX", ErrInvalidExport)
+	default:
+		rows, err = csvRows(data)
+	}
+	if err != nil {
+		return Import{}, fmt.Errorf("%w: %w", ErrInvalidExport, err)
+	}
+
+	return importRows(repo, rows)
+}
+
+func xlsxRows(data []byte) ([][]string, error) {
+	xls, err := excelize.OpenReader(bytes.NewReader(data), excelize.Options{
+		UnzipSizeLimit:    maxUnzippedExportSize,
+		UnzipXMLSizeLimit: maxUnzippedExportSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer xls.Close()
+
+	sheets := xls.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, errors.New("no sheets")
+	}
+	return xls.GetRows(sheets[0])
+}
+
+func csvRows(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, utf8BOM)
+	if !utf8.Valid(data) {
+		var err error
+		if data, err = charmap.Windows1250.NewDecoder().Bytes(data); err != nil {
+			return nil, err
+		}
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	// decimal commas make semicolons the usual separator of Polish exports
+	if bytes.Count(data, []byte(";")) > bytes.Count(data, []byte(",")) {
+		reader.Comma = ';'
+	}
+	return reader.ReadAll()
+}
+
+// exportColumns are the zero-based indices of the columns of an export, or -1 if missing.
+type exportColumns struct {
+	series, purchaseDate, quantity, account int
+}
+
+func findColumns(header []string) (exportColumns, bool) {
+	columns := exportColumns{series: -1, purchaseDate: -1, quantity: -1, account: -1}
+	for i, cell := range header {
+		name, _, _ := strings.Cut(strings.ToLower(cell), "(")
+		name = strings.Join(strings.Fields(strings.TrimSuffix(strings.TrimSpace(name), ":")), " ")
+		switch {
+		case columns.series < 0 && slices.Contains(seriesHeaders, name):
+			columns.series = i
+		case columns.purchaseDate < 0 && slices.Contains(purchaseDateHeaders, name):
+			columns.purchaseDate = i
+		case columns.quantity < 0 && slices.Contains(quantityHeaders, name):
+			columns.quantity = i
+		case columns.account < 0 && slices.Contains(accountHeaders, name):
+			columns.account = i
+		}
+	}
+	return columns, columns.series >= 0 && columns.purchaseDate >= 0 && columns.quantity >= 0
+}
+
+func importRows(repo bond.Repository, rows [][]string) (Import, error) {
+	headerRow := -1
+	var columns exportColumns
+	for i, row := range rows {
+		var ok bool
+		if columns, ok = findColumns(row); ok {
+			headerRow = i
+			break
+		}
+	}
+	if headerRow < 0 {
+		return Import{}, fmt.Errorf("%w: no header with series, purchase date and quantity columns", ErrInvalidExport)
+	}
+
+	imported := Import{Portfolio: Portfolio{Holdings: []Holding{}}}
+	quantity := 0
+	for i := headerRow + 1; i < len(rows); i++ {
+		row := rows[i]
+		if !slices.ContainsFunc(row, func(cell string) bool { return strings.TrimSpace(cell) != "" }) {
+			continue
+		}
+		h, err := importRow(repo, columns, row)
+		if err != nil {
+			imported.Unmatched = append(imported.Unmatched, UnmatchedRow{Row: i + 1, Cells: row, Err: err})
+			continue
+		}
+		// the same limit as in Parse, so that the imported holdings can be valued
+		if quantity += h.Quantity; quantity > calculator.MaxQuantity {
+			return Import{}, fmt.Errorf("%w: more than %d bonds", ErrInvalidPortfolio, calculator.MaxQuantity)
+		}
+		imported.Portfolio.Holdings = append(imported.Portfolio.Holdings, h)
+	}
+	return imported, nil
+}
+
+func importRow(repo bond.Repository, columns exportColumns, row []string) (Holding, error) {
+	cell := func(column int) string {
+		if column < 0 || column >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[column])
+	}
+
+	series := seriesCode.FindString(strings.ToUpper(cell(columns.series)))
+	if series == "" {
+		return Holding{}, fmt.Errorf("no series in %q", cell(columns.series))
+	}
+	bnd, err := repo.Lookup(series)
+	if err != nil {
+		return Holding{}, fmt.Errorf("%s: %w", series, err)
+	}
+
+	purchaseDate, err := parsePurchaseDate(cell(columns.purchaseDate))
+	if err != nil {
+		return Holding{}, err
+	}
+	if purchaseDate.Before(bnd.SaleStart) || purchaseDate.After(bnd.SaleEnd) {
+		return Holding{}, fmt.Errorf("purchase date %s outside the sale of %s from %s to %s", purchaseDate, series, bnd.SaleStart, bnd.SaleEnd)
+	}
+
+	quantity, err := parseQuantity(cell(columns.quantity))
+	if err != nil {
+		return Holding{}, err
+	}
+
+	return Holding{
+		Series:      series,
+		PurchaseDay: purchaseDate.Day(),
+		Quantity:    quantity,
+		Account:     cell(columns.account),
+	}, nil
+}
+
+func parsePurchaseDate(cell string) (civil.Date, error) {
+	for _, layout := range purchaseDateLayouts {
+		if date, err := civil.ParseFormat(layout, cell); err == nil {
+			return date, nil
+		}
+	}
+	// dates in cells without a date format are shown as serial numbers
+	if serial, err := strconv.ParseFloat(cell, 64); err == nil {
+		if t, err := excelize.ExcelDateToTime(serial, false); err == nil {
+			return civil.Of(t), nil
+		}
+	}
+	return civil.Date{}, fmt.Errorf("invalid purchase date %q", cell)
+}
+
+func parseQuantity(cell string) (int, error) {
+	number := strings.TrimSuffix(strings.TrimSpace(cell), "szt.")
+	number = strings.Map(func(r rune) rune {
+		if r == ' ' || r == '\u00a0' {
+			return -1
+		}
+		return r
+	}, number)
+	match := quantityFormat.FindStringSubmatch(number)
+	if match == nil {
+		return 0, fmt.Errorf("invalid quantity %q", cell)
+	}
+	n, err := strconv.Atoi(match[1])
+	if err != nil || n < 1 || n > calculator.MaxQuantity {
+		return 0, fmt.Errorf("invalid quantity %q", cell)
+	}
+	return n, nil
+}
@@ -0,0 +1,179 @@
+package portfolio_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/internal/testutil"
+	"github.com/maciekmm/obligacje/portfolio"
+	"github.com/xuri/excelize/v2"
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestLoadExport_CSV(t *testing.T) {
+	got, err := portfolio.LoadExport(LoadBondRepository(), filepath.Join(testutil.TestDataDirectory(), "export.csv"))
+	if err != nil {
+		t.Fatalf("LoadExport() error = %v", err)
+	}
+
+	want := []portfolio.Holding{
+		{Series: "EDO0834", PurchaseDay: 15, Quantity: 10, Account: "12345"},
+		{Series: "COI0528", PurchaseDay: 1, Quantity: 5, Account: "12345"},
+	}
+	if !slices.Equal(got.Portfolio.Holdings, want) {
+		t.Errorf("LoadExport() holdings = %+v, want %+v", got.Portfolio.Holdings, want)
+	}
+
+	// unknown series, purchase outside the sale, invalid quantity and the summary row
+	var rows []int
+	for _, u := range got.Unmatched {
+		rows = append(rows, u.Row)
+	}
+	if !slices.Equal(rows, []int{6, 7, 8, 9}) {
+		t.Fatalf("LoadExport() unmatched rows = %v, want [6 7 8 9]", rows)
+	}
+	if !errors.Is(got.Unmatched[0].Err, bond.ErrNameNotFound) {
+		t.Errorf("LoadExport() unmatched error = %v, want %v", got.Unmatched[0].Err, bond.ErrNameNotFound)
+	}
+}
+
+func TestParseExport_XLSX(t *testing.T) {
+	f := excelize.NewFile()
+	rows := [][]any{
+		{"Emisja", "Data nabycia", "Dostępnych"},
+		{"EDO0834", "2024-08-15", 10},
+		{"ROR1226", "2025-12-01", 20},
+	}
+	for i, row := range rows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := f.SetSheetRow("Sheet1", cell, &row); err != nil {
+			t.Fatalf("SetSheetRow() error = %v", err)
+		}
+	}
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	got, err := portfolio.ParseExport(LoadBondRepository(), &buf)
+	if err != nil {
+		t.Fatalf("ParseExport() error = %v", err)
+	}
+	want := []portfolio.Holding{
+		{Series: "EDO0834", PurchaseDay: 15, Quantity: 10},
+		{Series: "ROR1226", PurchaseDay: 1, Quantity: 20},
+	}
+	if !slices.Equal(got.Portfolio.Holdings, want) || len(got.Unmatched) != 0 {
+		t.Errorf("ParseExport() = %+v, want holdings %+v", got, want)
+	}
+}
+
+func TestParseExport_ZipBomb(t *testing.T) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	sheet, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	// 64 MiB of spaces compress to some 64 KiB
+	if _, err := sheet.Write(bytes.Repeat([]byte(" "), 64<<20)); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	_, err = portfolio.ParseExport(LoadBondRepository(), &buf)
+	if !errors.Is(err, portfolio.ErrInvalidExport) {
+		t.Errorf("ParseExport() error = %v, want %v", err, portfolio.ErrInvalidExport)
+	}
+}
+
+func TestParseExport_Windows1250(t *testing.T) {
+	export, err := charmap.Windows1250.NewEncoder().String("Seria,Data zakupu,Ilość\nEDO0834,15.08.2024,1 000\n")
+	if err != nil {
+		t.Fatalf("String() error = %v", err)
+	}
+
+	got, err := portfolio.ParseExport(LoadBondRepository(), strings.NewReader(export))
+	if err != nil {
+		t.Fatalf("ParseExport() error = %v", err)
+	}
+	want := []portfolio.Holding{{Series: "EDO0834", PurchaseDay: 15, Quantity: 1000}}
+	if !slices.Equal(got.Portfolio.Holdings, want) {
+		t.Errorf("ParseExport() holdings = %+v, want %+v", got.Portfolio.Holdings, want)
+	}
+}
+
+func TestParseExport_Quantity(t *testing.T) {
+	tests := []struct {
+		cell string
+		want int
+	}{
+		{cell: "10", want: 10},
+		{cell: "1 000", want: 1000},
+		{cell: "2\u00a0000", want: 2000},
+		{cell: "10,00", want: 10},
+		{cell: "10 szt.", want: 10},
+		// a thousands separator or a fraction, either way not to be guessed
+		{cell: "1.000"},
+		{cell: "1,000"},
+		{cell: "10,5"},
+		{cell: "0"},
+		{cell: "1000000000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cell, func(t *testing.T) {
+			export := "Emisja;Data zakupu;Liczba obligacji\nEDO0834;15.08.2024;" + tt.cell + "\n"
+			got, err := portfolio.ParseExport(LoadBondRepository(), strings.NewReader(export))
+			if err != nil {
+				t.Fatalf("ParseExport() error = %v", err)
+			}
+			if tt.want == 0 {
+				if len(got.Portfolio.Holdings) != 0 || len(got.Unmatched) != 1 {
+					t.Errorf("ParseExport() = %+v, want the row unmatched", got)
+				}
+				return
+			}
+			if len(got.Portfolio.Holdings) != 1 || got.Portfolio.Holdings[0].Quantity != tt.want {
+				t.Errorf("ParseExport() holdings = %+v, want quantity %d", got.Portfolio.Holdings, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseExport_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "no header", data: "EDO0834;15.08.2024;10\n"},
+		{name: "missing quantity column", data: "Emisja;Data zakupu\nEDO0834;15.08.2024\n"},
+		{name: "legacy XLS", data: "\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1"},
+		{name: "malformed CSV", data: "Emisja;Data zakupu;Liczba\n\"EDO0834;15.08.2024;10\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := portfolio.ParseExport(LoadBondRepository(), strings.NewReader(tt.data))
+			if !errors.Is(err, portfolio.ErrInvalidExport) {
+				t.Errorf("ParseExport() error = %v, want %v", err, portfolio.ErrInvalidExport)
+			}
+		})
+	}
+}
+
+func TestParseExport_TooManyBonds(t *testing.T) {
+	export := "Emisja;Data zakupu;Liczba obligacji\nEDO0834;15.08.2024;600000\nEDO0834;16.08.2024;600000\n"
+	_, err := portfolio.ParseExport(LoadBondRepository(), strings.NewReader(export))
+	if !errors.Is(err, portfolio.ErrInvalidPortfolio) {
+		t.Errorf("ParseExport() error = %v, want %v", err, portfolio.ErrInvalidPortfolio)
+	}
+}
@@ -0,0 +1,155 @@
+package portfolio
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/calculator"
+	"github.com/maciekmm/obligacje/civil"
+)
+
+var (
+	ErrUnknownGranularity = errors.New("unknown granularity")
+)
+
+// Granularity is the spacing of the points of a portfolio history.
+type Granularity int
+
+const (
+	Daily Granularity = iota
+	// Weekly has a point on every Sunday.
+	Weekly
+	// Monthly has a point on the last day of every month.
+	Monthly
+)
+
+// ParseGranularity maps the name of a granularity to the granularity. An empty name stands for Daily.
+func ParseGranularity(name string) (Granularity, error) {
+	switch strings.ToLower(name) {
+	case "", "daily":
+		return Daily, nil
+	case "weekly":
+		return Weekly, nil
+	case "monthly":
+		return Monthly, nil
+	}
+	return Daily, ErrUnknownGranularity
+}
+
+func (g Granularity) String() string {
+	switch g {
+	case Weekly:
+		return "weekly"
+	case Monthly:
+		return "monthly"
+	}
+	return "daily"
+}
+
+// Dates returns the dates of a history from from to to: from, the end of every week or month in between, and to.
+func (g Granularity) Dates(from, to civil.Date) []civil.Date {
+	if to.Before(from) {
+		return nil
+	}
+	dates := []civil.Date{from}
+	for d := g.next(from); d.Before(to); d = g.next(d) {
+		dates = append(dates, d)
+	}
+	if to != from {
+		dates = append(dates, to)
+	}
+	return dates
+}
+
+// next returns the end of the week or month following d, or the day after d for Daily.
+func (g Granularity) next(d civil.Date) civil.Date {
+	switch g {
+	case Weekly:
+		days := int(time.Sunday - d.In(time.UTC).Weekday())
+		if days <= 0 {
+			days += 7
+		}
+		return d.AddDate(0, 0, days)
+	case Monthly:
+		end := civil.New(d.Year(), d.Month()+1, 0)
+		if end == d {
+			end = civil.New(d.Year(), d.Month()+2, 0)
+		}
+		return end
+	}
+	return d.AddDate(0, 0, 1)
+}
+
+// HistoryPoint is the value of a portfolio at a given date. Value, Invested and Interest cover the held bonds only,
+// while coupons are summed up over all bonds bought by the date, including the ones which have already matured.
+type HistoryPoint struct {
+	Date  civil.Date
+	Value bond.Price
+	// Invested is the price paid for the held bonds, see Holding.CostBasis.
+	Invested bond.Price
+	// Interest is the interest accumulated on the held bonds, their value less the invested capital.
+	Interest    bond.Price
+	PaidCoupons bond.Price
+	NetValue    bond.Price
+	// NetPaidCoupons is the sum of coupons paid out after tax.
+	NetPaidCoupons bond.Price
+	// Redeemed is the amount paid out for the bonds which have been redeemed by the date, at maturity or early.
+	Redeemed bond.Price
+	Derived  bool
+}
+
+// History values the portfolio at every date from from to to at the given granularity, see Granularity.Dates.
+// Like Valuate, it returns calculator.ErrValuationDateAfterMaturity together with the history
+// if rates of a held bond are not known yet at some of the dates.
+func History(repo bond.Repository, calc *calculator.Calculator, p Portfolio, from, to civil.Date, granularity Granularity) ([]HistoryPoint, error) {
+	dates := granularity.Dates(from, to)
+	points := make([]HistoryPoint, 0, len(dates))
+	var unknownRates error
+	for _, d := range dates {
+		valuation, err := Valuate(repo, calc, p, d)
+		if errors.Is(err, calculator.ErrValuationDateAfterMaturity) && unknownRates == nil {
+			unknownRates = fmt.Errorf("%w on %s", err, d)
+		} else if err != nil && !errors.Is(err, calculator.ErrValuationDateAfterMaturity) {
+			return nil, err
+		}
+		point, err := historyPoint(valuation)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, point)
+	}
+	return points, unknownRates
+}
+
+func historyPoint(valuation Valuation) (HistoryPoint, error) {
+	point := HistoryPoint{
+		Date:     valuation.ValuatedAt,
+		Value:    valuation.Total.Price,
+		NetValue: valuation.Total.NetPrice,
+		Derived:  valuation.Derived,
+	}
+	var coupons Amounts
+	for _, hv := range valuation.Holdings {
+		switch {
+		case hv.Held:
+			invested, err := hv.Holding.costBasis(hv.Bond).Times(hv.Holding.Quantity)
+			if err != nil {
+				return HistoryPoint{}, err
+			}
+			point.Invested = bond.PriceOf(point.Invested.Decimal() + invested.Decimal())
+		case hv.Redeemed:
+			point.Redeemed = bond.PriceOf(point.Redeemed.Decimal() + hv.Total.Price.Decimal())
+		default:
+			// not bought yet
+			continue
+		}
+		coupons = coupons.add(Amounts{PaidCoupons: hv.Total.PaidCoupons, NetPaidCoupons: hv.Total.NetPaidCoupons})
+	}
+	point.PaidCoupons = coupons.PaidCoupons
+	point.NetPaidCoupons = coupons.NetPaidCoupons
+	point.Interest = bond.PriceOf(point.Value.Decimal() - point.Invested.Decimal())
+	return point, nil
+}
@@ -0,0 +1,137 @@
+package portfolio_test
+
+import (
+	"errors"
+	"math"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/calculator"
+	"github.com/maciekmm/obligacje/civil"
+	"github.com/maciekmm/obligacje/internal/testutil"
+	"github.com/maciekmm/obligacje/portfolio"
+)
+
+func TestGranularity_Dates(t *testing.T) {
+	tests := []struct {
+		granularity portfolio.Granularity
+		from, to    civil.Date
+		want        []civil.Date
+	}{
+		{
+			granularity: portfolio.Daily,
+			from:        civil.New(2025, time.January, 30),
+			to:          civil.New(2025, time.February, 1),
+			want:        []civil.Date{civil.New(2025, time.January, 30), civil.New(2025, time.January, 31), civil.New(2025, time.February, 1)},
+		},
+		{
+			granularity: portfolio.Weekly,
+			from:        civil.New(2025, time.September, 15),
+			to:          civil.New(2025, time.October, 1),
+			want:        []civil.Date{civil.New(2025, time.September, 15), civil.New(2025, time.September, 21), civil.New(2025, time.September, 28), civil.New(2025, time.October, 1)},
+		},
+		{
+			// from on the last day of a month
+			granularity: portfolio.Monthly,
+			from:        civil.New(2025, time.January, 31),
+			to:          civil.New(2025, time.March, 31),
+			want:        []civil.Date{civil.New(2025, time.January, 31), civil.New(2025, time.February, 28), civil.New(2025, time.March, 31)},
+		},
+		{
+			granularity: portfolio.Monthly,
+			from:        civil.New(2025, time.January, 15),
+			to:          civil.New(2025, time.January, 15),
+			want:        []civil.Date{civil.New(2025, time.January, 15)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.granularity.String(), func(t *testing.T) {
+			if got := tt.granularity.Dates(tt.from, tt.to); !slices.Equal(got, tt.want) {
+				t.Errorf("Dates(%v, %v) = %v, want %v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHistory(t *testing.T) {
+	p, err := portfolio.Load(filepath.Join(testutil.TestDataDirectory(), "holdings.json"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	got, err := portfolio.History(LoadBondRepository(), calculator.NewCalculator(), p, civil.New(2025, time.September, 15), civil.New(2025, time.December, 10), portfolio.Monthly)
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	if len(got) != 5 {
+		t.Fatalf("History() returned %d points, want 5", len(got))
+	}
+
+	beforeMaturity, afterMaturity, last := got[2], got[3], got[4]
+	if want := civil.New(2025, time.October, 31); beforeMaturity.Date != want {
+		t.Errorf("History() date = %v, want %v", beforeMaturity.Date, want)
+	}
+
+	prices := []struct {
+		field string
+		got   bond.Price
+		want  bond.Price
+	}{
+		// 10 EDO, 5 COI and 3 TOS
+		{field: "invested before maturity", got: beforeMaturity.Invested, want: 1800},
+		{field: "interest before maturity", got: beforeMaturity.Interest, want: 165.51},
+		// TOS matured on 2025-11-01
+		{field: "invested after maturity", got: afterMaturity.Invested, want: 1500},
+		{field: "redeemed after maturity", got: afterMaturity.Redeemed, want: 365.97},
+		{field: "value after maturity", got: afterMaturity.Value, want: 1607.55},
+		// 20 ROR bought on 2025-12-01
+		{field: "invested", got: last.Invested, want: 3500},
+		{field: "value", got: last.Value, want: 3612.20},
+		{field: "net value", got: last.NetValue, want: 3573.10},
+		{field: "paid coupons", got: last.PaidCoupons, want: 32.75},
+	}
+	for _, p := range prices {
+		if math.Abs(float64(p.got-p.want)) > 1e-9 {
+			t.Errorf("History() %s = %v, want %v", p.field, p.got, p.want)
+		}
+	}
+}
+
+func TestHistory_UnknownRates(t *testing.T) {
+	p := portfolio.Portfolio{Holdings: []portfolio.Holding{{Series: "EDO0834", PurchaseDay: 1, Quantity: 1}}}
+
+	got, err := portfolio.History(LoadBondRepository(), calculator.NewCalculator(), p, civil.New(2029, time.January, 1), civil.New(2031, time.January, 1), portfolio.Monthly)
+	if !errors.Is(err, calculator.ErrValuationDateAfterMaturity) {
+		t.Errorf("History() error = %v, want %v", err, calculator.ErrValuationDateAfterMaturity)
+	}
+	if len(got) != 26 {
+		t.Errorf("History() returned %d points, want 26", len(got))
+	}
+}
+
+func TestParseGranularity(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    portfolio.Granularity
+		wantErr error
+	}{
+		{name: "", want: portfolio.Daily},
+		{name: "weekly", want: portfolio.Weekly},
+		{name: "MONTHLY", want: portfolio.Monthly},
+		{name: "yearly", wantErr: portfolio.ErrUnknownGranularity},
+	}
+
+	for _, tt := range tests {
+		got, err := portfolio.ParseGranularity(tt.name)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("ParseGranularity(%q) error = %v, want %v", tt.name, err, tt.wantErr)
+		}
+		if err == nil && got != tt.want {
+			t.Errorf("ParseGranularity(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
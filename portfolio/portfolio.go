@@ -0,0 +1,278 @@
+// Package portfolio values holdings of many lots of bonds at once.
+package portfolio
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/calculator"
+	"github.com/maciekmm/obligacje/civil"
+)
+
+var (
+	ErrInvalidPortfolio = errors.New("invalid portfolio")
+)
+
+// Holding is a lot of bonds of a single issue bought on the same day.
+type Holding struct {
+	// Series is the name of the issue, e.g. EDO0834.
+	Series      string `json:"series"`
+	PurchaseDay int    `json:"purchase_day"`
+	Quantity    int    `json:"quantity"`
+	// Account optionally tags the account the bonds are held in, e.g. a brokerage account number.
+	Account string `json:"account,omitempty"`
+	// Wrapper is ike or ikze for bonds held in a tax-exempt account and empty for a regular, taxable account.
+	Wrapper string `json:"wrapper,omitempty"`
+	// RedeemedAt is the date all bonds of the holding were redeemed before maturity, zero if they weren't.
+	RedeemedAt civil.Date `json:"redeemed_at,omitzero"`
+	// ExchangedInto is the issue the bonds were rolled over into at maturity (zamiana), e.g. TOS1128.
+	ExchangedInto string `json:"exchanged_into,omitempty"`
+	// CostBasis is the price paid per bond, e.g. the exchange price of bonds acquired by rolling over
+	// an earlier issue. It is the face value of the bond if zero.
+	CostBasis bond.Price `json:"cost_basis,omitzero"`
+}
+
+// Name returns the name of the bond including the purchase day, e.g. EDO083415.
+func (h Holding) Name() string {
+	return fmt.Sprintf("%s%02d", h.Series, h.PurchaseDay)
+}
+
+// costBasis returns the price paid per bond of bnd, which the tax is calculated against.
+func (h Holding) costBasis(bnd bond.Bond) bond.Price {
+	if h.CostBasis == 0 {
+		return bnd.FaceValue
+	}
+	return h.CostBasis
+}
+
+// Taxation returns the taxation of the account the holding is held in.
+func (h Holding) Taxation() (calculator.Taxation, error) {
+	return calculator.ParseTaxation(h.Wrapper)
+}
+
+type Portfolio struct {
+	Holdings []Holding `json:"holdings"`
+}
+
+// Load reads a portfolio from a JSON file, see Parse.
+func Load(path string) (Portfolio, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Portfolio{}, err
+	}
+	defer f.Close()
+
+	return Parse(f)
+}
+
+// Parse reads a portfolio from JSON of the form:
+//
+//	{"holdings": [{"series": "EDO0834", "purchase_day": 15, "quantity": 10, "account": "main", "wrapper": "ike"}]}
+//
+// Holdings must have a series, a purchase day between 1 and 31 and a positive quantity,
+// with at most calculator.MaxQuantity bonds in the whole portfolio.
+// Bonds acquired by rolling over an earlier issue have the exchange price they were bought at in cost_basis.
+// Bonds of a holding redeemed early (redeemed_at) can't be exchanged at maturity (exchanged_into);
+// holdings redeemed in part have to be split into the redeemed and the remaining lot.
+func Parse(r io.Reader) (Portfolio, error) {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+
+	var p Portfolio
+	if err := decoder.Decode(&p); err != nil {
+		return Portfolio{}, fmt.Errorf("%w: %w", ErrInvalidPortfolio, err)
+	}
+	quantity := 0
+	for i, h := range p.Holdings {
+		switch {
+		case h.Series == "":
+			return Portfolio{}, fmt.Errorf("%w: missing series of holding %d", ErrInvalidPortfolio, i+1)
+		case h.PurchaseDay < 1 || h.PurchaseDay > 31:
+			return Portfolio{}, fmt.Errorf("%w: invalid purchase day %d of holding %d", ErrInvalidPortfolio, h.PurchaseDay, i+1)
+		case h.Quantity < 1 || h.Quantity > calculator.MaxQuantity:
+			return Portfolio{}, fmt.Errorf("%w: invalid quantity %d of holding %d", ErrInvalidPortfolio, h.Quantity, i+1)
+		case h.CostBasis < 0:
+			return Portfolio{}, fmt.Errorf("%w: invalid cost basis %v of holding %d", ErrInvalidPortfolio, h.CostBasis, i+1)
+		}
+		if quantity += h.Quantity; quantity > calculator.MaxQuantity {
+			return Portfolio{}, fmt.Errorf("%w: more than %d bonds", ErrInvalidPortfolio, calculator.MaxQuantity)
+		}
+		if _, err := h.Taxation(); err != nil {
+			return Portfolio{}, fmt.Errorf("%w: invalid wrapper %q of holding %d", ErrInvalidPortfolio, h.Wrapper, i+1)
+		}
+		if !h.RedeemedAt.IsZero() && h.ExchangedInto != "" {
+			return Portfolio{}, fmt.Errorf("%w: holding %d both redeemed early and exchanged", ErrInvalidPortfolio, i+1)
+		}
+	}
+	return p, nil
+}
+
+// Amounts are the values of one or more bonds.
+type Amounts struct {
+	Price       bond.Price
+	PaidCoupons bond.Price
+	// NetPrice is what the holder would receive if the bonds were redeemed, see calculator.Calculator.NetValue.
+	NetPrice bond.Price
+	// NetPaidCoupons is the sum of coupons paid out after tax.
+	NetPaidCoupons bond.Price
+}
+
+func (a Amounts) add(b Amounts) Amounts {
+	return Amounts{
+		Price:          bond.PriceOf(a.Price.Decimal() + b.Price.Decimal()),
+		PaidCoupons:    bond.PriceOf(a.PaidCoupons.Decimal() + b.PaidCoupons.Decimal()),
+		NetPrice:       bond.PriceOf(a.NetPrice.Decimal() + b.NetPrice.Decimal()),
+		NetPaidCoupons: bond.PriceOf(a.NetPaidCoupons.Decimal() + b.NetPaidCoupons.Decimal()),
+	}
+}
+
+// HoldingValuation is the value of a single holding.
+type HoldingValuation struct {
+	Holding Holding
+	Bond    bond.Bond
+	// Lifecycle is the stage of life of the bonds. It is zero if they aren't bought yet.
+	Lifecycle calculator.Lifecycle
+	// Held reports whether the bonds are bought and haven't been redeemed yet. Only held bonds count towards the total.
+	Held bool
+	// Redeemed reports whether the bonds have been paid out, at maturity or early.
+	Redeemed bool
+	// PerBond are the amounts of a single bond.
+	PerBond Amounts
+	// Total are the amounts of all bonds of the holding, rounded per bond and then multiplied.
+	Total   Amounts
+	Derived bool
+}
+
+// Valuation is the value of a portfolio at a given date.
+type Valuation struct {
+	ValuatedAt civil.Date
+	Holdings   []HoldingValuation
+	// Total is the sum of all held bonds.
+	Total   Amounts
+	Derived bool
+}
+
+// Valuate values every holding of the portfolio at valuatedAt. Bonds bought later are reported as not held,
+// while redeemed bonds are reported with the amounts paid out at maturity, or at the early redemption,
+// but left out of the total.
+// It returns calculator.ErrValuationDateAfterMaturity together with the valuation
+// if rates of a held bond up to valuatedAt are not known yet.
+func Valuate(repo bond.Repository, calc *calculator.Calculator, p Portfolio, valuatedAt civil.Date) (Valuation, error) {
+	valuation := Valuation{
+		ValuatedAt: valuatedAt,
+		Holdings:   make([]HoldingValuation, 0, len(p.Holdings)),
+	}
+	var unknownRates error
+	for _, h := range p.Holdings {
+		hv, err := valuateHolding(repo, calc, h, valuatedAt)
+		if errors.Is(err, calculator.ErrValuationDateAfterMaturity) && unknownRates == nil {
+			unknownRates = fmt.Errorf("%w: %s", err, h.Name())
+		} else if err != nil && !errors.Is(err, calculator.ErrValuationDateAfterMaturity) {
+			return Valuation{}, fmt.Errorf("%s: %w", h.Name(), err)
+		}
+
+		if hv.Held {
+			valuation.Total = valuation.Total.add(hv.Total)
+			valuation.Derived = valuation.Derived || hv.Derived
+		}
+		valuation.Holdings = append(valuation.Holdings, hv)
+	}
+	return valuation, unknownRates
+}
+
+func valuateHolding(repo bond.Repository, calc *calculator.Calculator, h Holding, valuatedAt civil.Date) (HoldingValuation, error) {
+	bnd, err := repo.Lookup(h.Series)
+	if err != nil {
+		return HoldingValuation{}, err
+	}
+	if err := checkHolding(bnd, h); err != nil {
+		return HoldingValuation{}, err
+	}
+	taxation, err := h.Taxation()
+	if err != nil {
+		return HoldingValuation{}, err
+	}
+
+	hv := HoldingValuation{Holding: h, Bond: bnd}
+	hv.Lifecycle, err = calc.Lifecycle(bnd, h.PurchaseDay, valuatedAt)
+	if errors.Is(err, calculator.ErrValuationDateBeforePurchaseDate) {
+		return hv, nil
+	}
+	if err != nil {
+		return HoldingValuation{}, err
+	}
+	redeemedEarly := !h.RedeemedAt.IsZero() && !valuatedAt.Before(h.RedeemedAt)
+	hv.Redeemed = redeemedEarly || hv.Lifecycle.Status == calculator.StatusMatured
+	hv.Held = !hv.Redeemed
+
+	at := valuatedAt
+	switch {
+	case redeemedEarly:
+		at = h.RedeemedAt
+	case hv.Redeemed:
+		// bonds don't earn interest after maturity
+		at = hv.Lifecycle.Maturity
+	}
+	valuation, valuationErr := calc.Valuate(bnd, h.PurchaseDay, at)
+	if valuationErr != nil && !errors.Is(valuationErr, calculator.ErrValuationDateAfterMaturity) {
+		return HoldingValuation{}, valuationErr
+	}
+	net, err := calc.NetValueWithCostBasis(bnd, h.PurchaseDay, at, taxation, h.costBasis(bnd))
+	if err != nil && !errors.Is(err, calculator.ErrValuationDateAfterMaturity) {
+		return HoldingValuation{}, err
+	}
+
+	hv.PerBond = Amounts{
+		Price:          valuation.Price,
+		PaidCoupons:    valuation.PaidCoupons,
+		NetPrice:       net.Net,
+		NetPaidCoupons: taxation.NetCoupons(valuation),
+	}
+	hv.Total = hv.PerBond
+	if err := multiply(h.Quantity, &hv.Total.Price, &hv.Total.PaidCoupons, &hv.Total.NetPrice, &hv.Total.NetPaidCoupons); err != nil {
+		return HoldingValuation{}, err
+	}
+	hv.Derived = valuation.Derived
+	return hv, valuationErr
+}
+
+// multiply multiplies the amounts of a single bond by quantity in place, see bond.Price.Times.
+func multiply(quantity int, amounts ...*bond.Price) error {
+	for _, amount := range amounts {
+		total, err := amount.Times(quantity)
+		if err != nil {
+			return err
+		}
+		*amount = total
+	}
+	return nil
+}
+
+// checkHolding checks that the holding describes bonds of bnd, bought and redeemed while they could be.
+func checkHolding(bnd bond.Bond, h Holding) error {
+	purchaseDate := civil.New(bnd.SaleStart.Year(), bnd.SaleStart.Month(), h.PurchaseDay)
+	if purchaseDate.Month() != bnd.SaleStart.Month() {
+		return fmt.Errorf("%w: invalid purchase day %d", ErrInvalidPortfolio, h.PurchaseDay)
+	}
+	if h.CostBasis > bnd.FaceValue {
+		return fmt.Errorf("%w: cost basis %v above the face value", ErrInvalidPortfolio, h.CostBasis)
+	}
+	if h.RedeemedAt.IsZero() {
+		return nil
+	}
+	_, maturity, err := bnd.Period(bnd.InterestPeriodCount()-1, h.PurchaseDay)
+	if err != nil {
+		return err
+	}
+	if h.RedeemedAt.Before(purchaseDate) || !h.RedeemedAt.Before(maturity) {
+		return fmt.Errorf("%w: redemption date %s not between the purchase and the maturity", ErrInvalidPortfolio, h.RedeemedAt)
+	}
+	if !bnd.EarlyRedeemable {
+		return fmt.Errorf("%w: %w", ErrInvalidPortfolio, calculator.ErrEarlyRedemptionNotAllowed)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package portfolio_test
+
+import (
+	"errors"
+	"log/slog"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/bondxls"
+	"github.com/maciekmm/obligacje/calculator"
+	"github.com/maciekmm/obligacje/civil"
+	"github.com/maciekmm/obligacje/internal/testutil"
+	"github.com/maciekmm/obligacje/portfolio"
+)
+
+func LoadBondRepository() bond.Repository {
+	repo, err := bondxls.LoadFromXLSX(slog.Default(), testutil.BondDataFile())
+	if err != nil {
+		panic(err)
+	}
+	return repo
+}
+
+func TestLoad(t *testing.T) {
+	p, err := portfolio.Load(filepath.Join(testutil.TestDataDirectory(), "holdings.json"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if len(p.Holdings) != 4 {
+		t.Fatalf("Load() returned %d holdings, want 4", len(p.Holdings))
+	}
+	want := portfolio.Holding{Series: "COI0528", PurchaseDay: 1, Quantity: 5, Account: "retirement", Wrapper: "ike"}
+	if p.Holdings[1] != want {
+		t.Errorf("Load() holding = %+v, want %+v", p.Holdings[1], want)
+	}
+	if got := p.Holdings[1].Name(); got != "COI052801" {
+		t.Errorf("Name() = %s, want COI052801", got)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "malformed JSON", data: `{"holdings": [`},
+		{name: "unknown field", data: `{"holdings": [{"series": "EDO0834", "purchase_day": 1, "quantity": 1, "price": 100}]}`},
+		{name: "missing series", data: `{"holdings": [{"purchase_day": 1, "quantity": 1}]}`},
+		{name: "invalid purchase day", data: `{"holdings": [{"series": "EDO0834", "purchase_day": 32, "quantity": 1}]}`},
+		{name: "invalid quantity", data: `{"holdings": [{"series": "EDO0834", "purchase_day": 1, "quantity": 0}]}`},
+		{name: "quantity too large", data: `{"holdings": [{"series": "EDO0834", "purchase_day": 1, "quantity": 1000000000}]}`},
+		{name: "too many bonds", data: `{"holdings": [{"series": "EDO0834", "purchase_day": 1, "quantity": 600000}, {"series": "EDO0834", "purchase_day": 2, "quantity": 600000}]}`},
+		{name: "negative cost basis", data: `{"holdings": [{"series": "EDO0834", "purchase_day": 1, "quantity": 1, "cost_basis": -99.9}]}`},
+		{name: "unknown wrapper", data: `{"holdings": [{"series": "EDO0834", "purchase_day": 1, "quantity": 1, "wrapper": "ppk"}]}`},
+		{name: "invalid redemption date", data: `{"holdings": [{"series": "EDO0834", "purchase_day": 1, "quantity": 1, "redeemed_at": "2025-13-01"}]}`},
+		{name: "redeemed and exchanged", data: `{"holdings": [{"series": "TOS1125", "purchase_day": 1, "quantity": 1, "redeemed_at": "2024-01-01", "exchanged_into": "TOS1128"}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := portfolio.Parse(strings.NewReader(tt.data))
+			if !errors.Is(err, portfolio.ErrInvalidPortfolio) {
+				t.Errorf("Parse() error = %v, want %v", err, portfolio.ErrInvalidPortfolio)
+			}
+		})
+	}
+}
+
+func TestValuate(t *testing.T) {
+	p, err := portfolio.Load(filepath.Join(testutil.TestDataDirectory(), "holdings.json"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	got, err := portfolio.Valuate(LoadBondRepository(), calculator.NewCalculator(), p, civil.New(2025, time.November, 1))
+	if err != nil {
+		t.Fatalf("Valuate() error = %v", err)
+	}
+	if len(got.Holdings) != len(p.Holdings) {
+		t.Fatalf("Valuate() returned %d holdings, want %d", len(got.Holdings), len(p.Holdings))
+	}
+
+	edo, coi, tos, ror := got.Holdings[0], got.Holdings[1], got.Holdings[2], got.Holdings[3]
+	if !edo.Held || !coi.Held {
+		t.Errorf("Valuate() EDO held = %v, COI held = %v, want both held", edo.Held, coi.Held)
+	}
+	if tos.Held || tos.Lifecycle.Status != calculator.StatusMatured {
+		t.Errorf("Valuate() TOS held = %v with status %s, want matured", tos.Held, tos.Lifecycle.Status)
+	}
+	if ror.Held || ror.Total != (portfolio.Amounts{}) {
+		t.Errorf("Valuate() ROR held = %v worth %+v, want not bought yet", ror.Held, ror.Total)
+	}
+
+	prices := []struct {
+		field string
+		got   bond.Price
+		want  bond.Price
+	}{
+		{field: "EDO total price", got: edo.Total.Price, want: 1084.40},
+		// held in IKE, so only the early redemption fee is charged
+		{field: "COI net price per bond", got: coi.PerBond.NetPrice, want: 102.40},
+		{field: "COI total paid coupons", got: coi.Total.PaidCoupons, want: 32.75},
+		// matured bonds are valued at maturity
+		{field: "TOS total price", got: tos.Total.Price, want: 365.97},
+		{field: "total price", got: got.Total.Price, want: 1599.90},
+		{field: "total net price", got: got.Total.NetPrice, want: 1564.20},
+		{field: "total net paid coupons", got: got.Total.NetPaidCoupons, want: 32.75},
+	}
+	for _, p := range prices {
+		if math.Abs(float64(p.got-p.want)) > 1e-9 {
+			t.Errorf("Valuate() %s = %v, want %v", p.field, p.got, p.want)
+		}
+	}
+}
+
+func TestValuate_RedeemedEarly(t *testing.T) {
+	p, err := portfolio.Parse(strings.NewReader(`{"holdings": [{"series": "EDO0834", "purchase_day": 1, "quantity": 10, "redeemed_at": "2025-09-15"}]}`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	got, err := portfolio.Valuate(LoadBondRepository(), calculator.NewCalculator(), p, civil.New(2025, time.November, 1))
+	if err != nil {
+		t.Fatalf("Valuate() error = %v", err)
+	}
+	edo := got.Holdings[0]
+	if edo.Held || !edo.Redeemed || got.Total != (portfolio.Amounts{}) {
+		t.Errorf("Valuate() held = %v, redeemed = %v, total = %+v, want redeemed and left out of the total", edo.Held, edo.Redeemed, got.Total)
+	}
+	// valued at the redemption, less the 2.00 fee and the tax
+	if math.Abs(float64(edo.PerBond.NetPrice)-104.54) > 1e-9 {
+		t.Errorf("Valuate() net price = %v, want 104.54", edo.PerBond.NetPrice)
+	}
+}
+
+func TestValuate_Errors(t *testing.T) {
+	repo := LoadBondRepository()
+	c := calculator.NewCalculator()
+
+	unknown := portfolio.Portfolio{Holdings: []portfolio.Holding{{Series: "XYZ9999", PurchaseDay: 1, Quantity: 1}}}
+	if _, err := portfolio.Valuate(repo, c, unknown, civil.New(2025, time.November, 1)); !errors.Is(err, bond.ErrNameNotFound) {
+		t.Errorf("Valuate() error = %v, want %v", err, bond.ErrNameNotFound)
+	}
+
+	lastDay := portfolio.Portfolio{Holdings: []portfolio.Holding{{Series: "TOS1125", PurchaseDay: 31, Quantity: 1}}}
+	if _, err := portfolio.Valuate(repo, c, lastDay, civil.New(2025, time.November, 1)); !errors.Is(err, portfolio.ErrInvalidPortfolio) {
+		t.Errorf("Valuate() error = %v, want %v", err, portfolio.ErrInvalidPortfolio)
+	}
+
+	future := portfolio.Portfolio{Holdings: []portfolio.Holding{{Series: "EDO0834", PurchaseDay: 1, Quantity: 1}}}
+	got, err := portfolio.Valuate(repo, c, future, civil.New(2030, time.November, 1))
+	if !errors.Is(err, calculator.ErrValuationDateAfterMaturity) {
+		t.Errorf("Valuate() error = %v, want %v", err, calculator.ErrValuationDateAfterMaturity)
+	}
+	if len(got.Holdings) != 1 || got.Total.Price == 0 {
+		t.Errorf("Valuate() = %+v, want the valuation based on known rates", got)
+	}
+}
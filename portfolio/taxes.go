@@ -0,0 +1,302 @@
+package portfolio
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/calculator"
+	"github.com/maciekmm/obligacje/civil"
+	"github.com/xuri/excelize/v2"
+)
+
+// TaxEventKind is the kind of a payout of interest.
+type TaxEventKind string
+
+const (
+	TaxEventCoupon TaxEventKind = "coupon"
+	// TaxEventMaturity is the redemption of bonds at maturity, including the last coupon of bonds paying coupons.
+	TaxEventMaturity TaxEventKind = "maturity"
+	// TaxEventEarlyRedemption is the redemption of bonds before maturity, charged the early redemption fee.
+	TaxEventEarlyRedemption TaxEventKind = "early_redemption"
+	// TaxEventExchange is the redemption of bonds at maturity rolled over into a new issue, see Holding.ExchangedInto.
+	TaxEventExchange TaxEventKind = "exchange"
+)
+
+// TaxEvent is a payout of interest of all bonds of a holding. Amounts are calculated per bond and then multiplied.
+type TaxEvent struct {
+	Date    civil.Date
+	Kind    TaxEventKind
+	Holding Holding
+	Bond    bond.Bond
+	// Interest is the gross interest paid out. For redemptions it is the gross value less the cost basis,
+	// so it includes the discount of bonds bought below face value.
+	Interest bond.Price
+	// Fee is the early redemption fee, deducted from the interest before tax.
+	Fee bond.Price
+	// Tax is the capital gains tax withheld, zero for bonds held in IKE or IKZE.
+	Tax bond.Price
+	// Net is the interest left after the fee and the tax.
+	Net bond.Price
+	// Payout is the cash paid out to the holder, including the face value of redeemed bonds
+	// and, for exchanges, without the exchange price paid for the new bonds.
+	Payout  bond.Price
+	Derived bool
+}
+
+// TaxTotals are the sums of the amounts of tax events.
+type TaxTotals struct {
+	Interest bond.Price
+	Fee      bond.Price
+	Tax      bond.Price
+	Net      bond.Price
+	Payout   bond.Price
+}
+
+func (t TaxTotals) add(e TaxEvent) TaxTotals {
+	return TaxTotals{
+		Interest: bond.PriceOf(t.Interest.Decimal() + e.Interest.Decimal()),
+		Fee:      bond.PriceOf(t.Fee.Decimal() + e.Fee.Decimal()),
+		Tax:      bond.PriceOf(t.Tax.Decimal() + e.Tax.Decimal()),
+		Net:      bond.PriceOf(t.Net.Decimal() + e.Net.Decimal()),
+		Payout:   bond.PriceOf(t.Payout.Decimal() + e.Payout.Decimal()),
+	}
+}
+
+// TaxReport lists the payouts of interest of a portfolio in a tax year, e.g. to fill in
+// the tax return (PIT-38) or to check the tax withheld by the issuer.
+type TaxReport struct {
+	Year   int
+	Events []TaxEvent
+	Total  TaxTotals
+}
+
+// ReportTaxes lists every payout of interest of the portfolio in the year ordered by date: coupons,
+// redemptions at maturity, early redemptions and exchanges. The tax on redemptions is calculated against
+// the cost basis of the holding, see Holding.CostBasis.
+// Like Valuate, it returns calculator.ErrValuationDateAfterMaturity together with the report
+// if rates of a period ending in the year are not known yet; such payouts are left out.
+func ReportTaxes(repo bond.Repository, calc *calculator.Calculator, p Portfolio, year int) (TaxReport, error) {
+	report := TaxReport{Year: year, Events: []TaxEvent{}}
+	var unknownRates error
+	for _, h := range p.Holdings {
+		events, err := holdingTaxEvents(repo, calc, h, year)
+		if errors.Is(err, calculator.ErrValuationDateAfterMaturity) && unknownRates == nil {
+			unknownRates = fmt.Errorf("%w: %s", err, h.Name())
+		} else if err != nil && !errors.Is(err, calculator.ErrValuationDateAfterMaturity) {
+			return TaxReport{}, fmt.Errorf("%s: %w", h.Name(), err)
+		}
+		report.Events = append(report.Events, events...)
+	}
+
+	slices.SortStableFunc(report.Events, func(a, b TaxEvent) int {
+		return a.Date.Compare(b.Date)
+	})
+	for _, e := range report.Events {
+		report.Total = report.Total.add(e)
+	}
+	return report, unknownRates
+}
+
+func holdingTaxEvents(repo bond.Repository, calc *calculator.Calculator, h Holding, year int) ([]TaxEvent, error) {
+	bnd, err := repo.Lookup(h.Series)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkHolding(bnd, h); err != nil {
+		return nil, err
+	}
+	taxation, err := h.Taxation()
+	if err != nil {
+		return nil, err
+	}
+	schedule, err := calc.CashFlows(bnd, h.PurchaseDay)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []TaxEvent
+	var unknownRates error
+	for i, flow := range schedule.Periods {
+		final := i == len(schedule.Periods)-1
+		redeemedBefore := !h.RedeemedAt.IsZero() && h.RedeemedAt.Before(flow.End)
+		if flow.End.Year() != year || final || !flow.PaidOut || redeemedBefore {
+			continue
+		}
+		if !flow.RateKnown {
+			unknownRates = calculator.ErrValuationDateAfterMaturity
+			continue
+		}
+		tax := taxation.Tax(flow.Interest)
+		net := bond.PriceOf(flow.Interest.Decimal() - tax.Decimal())
+		event := TaxEvent{
+			Date:     flow.End,
+			Kind:     TaxEventCoupon,
+			Holding:  h,
+			Bond:     bnd,
+			Interest: flow.Interest,
+			Tax:      tax,
+			Net:      net,
+			Payout:   net,
+			Derived:  flow.RateDerived,
+		}
+		if err := multiply(h.Quantity, &event.Interest, &event.Tax, &event.Net, &event.Payout); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	redemptionDate := schedule.MaturityDate
+	if !h.RedeemedAt.IsZero() {
+		redemptionDate = h.RedeemedAt
+	}
+	if redemptionDate.Year() != year {
+		return events, unknownRates
+	}
+	redemption, err := redemptionTaxEvent(repo, calc, bnd, h, schedule, taxation)
+	if errors.Is(err, calculator.ErrValuationDateAfterMaturity) {
+		return events, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	return append(events, redemption), unknownRates
+}
+
+// redemptionTaxEvent returns the redemption of the bonds, early or at maturity.
+func redemptionTaxEvent(repo bond.Repository, calc *calculator.Calculator, bnd bond.Bond, h Holding, schedule calculator.Schedule, taxation calculator.Taxation) (TaxEvent, error) {
+	event := TaxEvent{Date: schedule.MaturityDate, Kind: TaxEventMaturity, Holding: h, Bond: bnd}
+
+	var exchange calculator.Exchange
+	switch {
+	case !h.RedeemedAt.IsZero():
+		event.Date = h.RedeemedAt
+		event.Kind = TaxEventEarlyRedemption
+	case h.ExchangedInto != "":
+		event.Kind = TaxEventExchange
+		target, err := repo.Lookup(h.ExchangedInto)
+		if err != nil {
+			return TaxEvent{}, fmt.Errorf("%s: %w", h.ExchangedInto, err)
+		}
+		exchange, err = calc.Exchange(bnd, h.PurchaseDay, h.Quantity, target, schedule.MaturityDate, taxation)
+		if errors.Is(err, calculator.ErrExchangeNotAvailable) {
+			return TaxEvent{}, fmt.Errorf("%w: %w: %s", ErrInvalidPortfolio, err, h.ExchangedInto)
+		}
+		if err != nil && !errors.Is(err, calculator.ErrValuationDateAfterMaturity) {
+			return TaxEvent{}, err
+		}
+	}
+
+	costBasis := h.costBasis(bnd)
+	redemption, err := calc.RedeemWithCostBasis(bnd, h.PurchaseDay, event.Date, taxation, costBasis)
+	if err != nil {
+		return TaxEvent{}, err
+	}
+
+	interest := bond.PriceOf(redemption.Gross.Decimal() - costBasis.Decimal())
+	event.Interest = interest
+	event.Fee = redemption.Fee
+	event.Tax = redemption.Tax
+	event.Net = bond.PriceOf(interest.Decimal() - redemption.Fee.Decimal() - redemption.Tax.Decimal())
+	event.Payout = redemption.Net
+	if event.Kind == TaxEventExchange {
+		// the face value of the old bonds buys the new ones, paying out only the interest
+		event.Payout = bond.PriceOf(redemption.Net.Decimal() - bnd.FaceValue.Decimal())
+	}
+	if err := multiply(h.Quantity, &event.Interest, &event.Fee, &event.Tax, &event.Net, &event.Payout); err != nil {
+		return TaxEvent{}, err
+	}
+	if event.Kind == TaxEventExchange {
+		// and the difference between the face value and the exchange price
+		event.Payout = bond.PriceOf(event.Payout.Decimal() + exchange.Leftover.Decimal())
+	}
+	for _, flow := range schedule.Periods {
+		if flow.Start.Before(event.Date) && flow.RateDerived {
+			event.Derived = true
+		}
+	}
+	return event, nil
+}
+
+var taxReportHeader = []string{"date", "kind", "bond", "isin", "account", "wrapper", "quantity", "interest", "fee", "tax", "net", "payout", "derived_rates"}
+
+// WriteCSV writes the events of the report as CSV with a header row.
+func (r TaxReport) WriteCSV(w io.Writer) error {
+	amount := func(p bond.Price) string {
+		return strconv.FormatFloat(float64(p), 'f', 2, 64)
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Write(taxReportHeader)
+	for _, e := range r.Events {
+		writer.Write([]string{
+			e.Date.String(),
+			string(e.Kind),
+			e.Holding.Name(),
+			e.Bond.ISIN,
+			e.Holding.Account,
+			e.Holding.Wrapper,
+			strconv.Itoa(e.Holding.Quantity),
+			amount(e.Interest),
+			amount(e.Fee),
+			amount(e.Tax),
+			amount(e.Net),
+			amount(e.Payout),
+			strconv.FormatBool(e.Derived),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteXLSX writes the events of the report as a spreadsheet with a header row and a row of totals.
+func (r TaxReport) WriteXLSX(w io.Writer) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	sheet := strconv.Itoa(r.Year)
+	if err := f.SetSheetName("Sheet1", sheet); err != nil {
+		return err
+	}
+
+	rows := make([][]any, 0, len(r.Events)+2)
+	header := make([]any, len(taxReportHeader))
+	for i, name := range taxReportHeader {
+		header[i] = name
+	}
+	rows = append(rows, header)
+	for _, e := range r.Events {
+		rows = append(rows, []any{
+			e.Date.String(), string(e.Kind), e.Holding.Name(), e.Bond.ISIN, e.Holding.Account, e.Holding.Wrapper, e.Holding.Quantity,
+			float64(e.Interest), float64(e.Fee), float64(e.Tax), float64(e.Net), float64(e.Payout), e.Derived,
+		})
+	}
+	rows = append(rows, []any{
+		"total", nil, nil, nil, nil, nil, nil,
+		float64(r.Total.Interest), float64(r.Total.Fee), float64(r.Total.Tax), float64(r.Total.Net), float64(r.Total.Payout),
+	})
+	for i, row := range rows {
+		cell, err := excelize.CoordinatesToCellName(1, i+1)
+		if err != nil {
+			return err
+		}
+		if err := f.SetSheetRow(sheet, cell, &row); err != nil {
+			return err
+		}
+	}
+
+	// amounts are shown with two decimal places
+	style, err := f.NewStyle(&excelize.Style{NumFmt: 2})
+	if err != nil {
+		return err
+	}
+	if err := f.SetCellStyle(sheet, "H2", fmt.Sprintf("L%d", len(rows)), style); err != nil {
+		return err
+	}
+
+	return f.Write(w)
+}
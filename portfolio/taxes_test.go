@@ -0,0 +1,188 @@
+package portfolio_test
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maciekmm/obligacje/bond"
+	"github.com/maciekmm/obligacje/calculator"
+	"github.com/maciekmm/obligacje/civil"
+	"github.com/maciekmm/obligacje/portfolio"
+	"github.com/xuri/excelize/v2"
+)
+
+var taxedPortfolio = portfolio.Portfolio{Holdings: []portfolio.Holding{
+	{Series: "COI0528", PurchaseDay: 1, Quantity: 5},
+	{Series: "TOS1125", PurchaseDay: 1, Quantity: 3, ExchangedInto: "TOS1128"},
+	{Series: "TOS1125", PurchaseDay: 10, Quantity: 2},
+	{Series: "EDO0834", PurchaseDay: 1, Quantity: 10, RedeemedAt: civil.New(2025, time.September, 15)},
+	{Series: "COI0528", PurchaseDay: 2, Quantity: 4, Wrapper: "ike"},
+}}
+
+func TestReportTaxes(t *testing.T) {
+	got, err := portfolio.ReportTaxes(LoadBondRepository(), calculator.NewCalculator(), taxedPortfolio, 2025)
+	if err != nil {
+		t.Fatalf("ReportTaxes() error = %v", err)
+	}
+
+	wantKinds := []portfolio.TaxEventKind{
+		portfolio.TaxEventCoupon,
+		portfolio.TaxEventCoupon,
+		portfolio.TaxEventEarlyRedemption,
+		portfolio.TaxEventExchange,
+		portfolio.TaxEventMaturity,
+	}
+	if len(got.Events) != len(wantKinds) {
+		t.Fatalf("ReportTaxes() returned %d events, want %d", len(got.Events), len(wantKinds))
+	}
+	for i, e := range got.Events {
+		if e.Kind != wantKinds[i] {
+			t.Errorf("ReportTaxes() event %d = %s of %s, want %s", i, e.Kind, e.Holding.Name(), wantKinds[i])
+		}
+	}
+
+	ike, early, exchange := got.Events[1], got.Events[2], got.Events[3]
+	prices := []struct {
+		field string
+		got   bond.Price
+		want  bond.Price
+	}{
+		{field: "IKE coupon tax", got: ike.Tax, want: 0},
+		{field: "IKE coupon net", got: ike.Net, want: 26.20},
+		// 10 * (7.60 - 2.00 fee - 19% of 5.60)
+		{field: "early redemption interest", got: early.Interest, want: 76},
+		{field: "early redemption fee", got: early.Fee, want: 20},
+		{field: "early redemption tax", got: early.Tax, want: 10.60},
+		{field: "early redemption payout", got: early.Payout, want: 1045.40},
+		// the interest and 3 * (100 - 99.90) left over from buying new bonds
+		{field: "exchange net", got: exchange.Net, want: 53.43},
+		{field: "exchange payout", got: exchange.Payout, want: 53.73},
+		{field: "total interest", got: got.Total.Interest, want: 244.90},
+		{field: "total tax", got: got.Total.Tax, want: 37.70},
+		{field: "total net", got: got.Total.Net, want: 187.20},
+	}
+	for _, p := range prices {
+		if math.Abs(float64(p.got-p.want)) > 1e-9 {
+			t.Errorf("ReportTaxes() %s = %v, want %v", p.field, p.got, p.want)
+		}
+	}
+}
+
+func TestReportTaxes_CostBasis(t *testing.T) {
+	repo := LoadBondRepository()
+	c := calculator.NewCalculator()
+	old, err := repo.Lookup("TOS1125")
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	target, err := repo.Lookup("TOS1128")
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	exchange, err := c.Exchange(old, 1, 1, target, civil.New(2028, time.November, 1), calculator.Taxable)
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+
+	// the bond acquired by the exchange, redeemed at maturity
+	p := portfolio.Portfolio{Holdings: []portfolio.Holding{{Series: "TOS1128", PurchaseDay: 1, Quantity: 1, CostBasis: exchange.CostBasis}}}
+	got, err := portfolio.ReportTaxes(repo, c, p, 2028)
+	if err != nil {
+		t.Fatalf("ReportTaxes() error = %v", err)
+	}
+	if len(got.Events) != 1 {
+		t.Fatalf("ReportTaxes() returned %d events, want 1", len(got.Events))
+	}
+	maturity := got.Events[0]
+	if maturity.Tax != exchange.NetValue.Tax || maturity.Payout != exchange.NetValue.Net {
+		t.Errorf("ReportTaxes() tax = %v, payout = %v, want %v and %v as valued by Exchange()", maturity.Tax, maturity.Payout, exchange.NetValue.Tax, exchange.NetValue.Net)
+	}
+	// the discount of the exchange price is taxed as well
+	wantInterest := exchange.Valuation.Price - exchange.CostBasis
+	if math.Abs(float64(maturity.Interest-wantInterest)) > 1e-9 {
+		t.Errorf("ReportTaxes() interest = %v, want %v", maturity.Interest, wantInterest)
+	}
+}
+
+func TestReportTaxes_Errors(t *testing.T) {
+	repo := LoadBondRepository()
+	c := calculator.NewCalculator()
+
+	tests := []struct {
+		name    string
+		holding portfolio.Holding
+		wantErr error
+	}{
+		{name: "unknown target", holding: portfolio.Holding{Series: "TOS1125", PurchaseDay: 1, Quantity: 1, ExchangedInto: "XYZ9999"}, wantErr: bond.ErrNameNotFound},
+		{name: "target not on sale", holding: portfolio.Holding{Series: "TOS1125", PurchaseDay: 1, Quantity: 1, ExchangedInto: "TOS1127"}, wantErr: portfolio.ErrInvalidPortfolio},
+		{name: "redeemed after maturity", holding: portfolio.Holding{Series: "TOS1125", PurchaseDay: 1, Quantity: 1, RedeemedAt: civil.New(2025, time.December, 1)}, wantErr: portfolio.ErrInvalidPortfolio},
+		{name: "cost basis above face value", holding: portfolio.Holding{Series: "TOS1125", PurchaseDay: 1, Quantity: 1, CostBasis: 100.10}, wantErr: portfolio.ErrInvalidPortfolio},
+		{name: "rates unknown", holding: portfolio.Holding{Series: "EDO0834", PurchaseDay: 1, Quantity: 1, RedeemedAt: civil.New(2030, time.January, 15)}, wantErr: calculator.ErrValuationDateAfterMaturity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := portfolio.Portfolio{Holdings: []portfolio.Holding{tt.holding}}
+			year := tt.holding.RedeemedAt.Year()
+			if tt.holding.RedeemedAt.IsZero() {
+				year = 2025
+			}
+			if _, err := portfolio.ReportTaxes(repo, c, p, year); !errors.Is(err, tt.wantErr) {
+				t.Errorf("ReportTaxes() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTaxReport_WriteCSV(t *testing.T) {
+	report, err := portfolio.ReportTaxes(LoadBondRepository(), calculator.NewCalculator(), taxedPortfolio, 2025)
+	if err != nil {
+		t.Fatalf("ReportTaxes() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("WriteCSV() wrote %d lines, want a header and 5 events", len(lines))
+	}
+	if want := "2025-09-15,early_redemption,EDO083401,PL0000117164,,,10,76.00,20.00,10.60,45.40,1045.40,false"; lines[3] != want {
+		t.Errorf("WriteCSV() line = %s, want %s", lines[3], want)
+	}
+}
+
+func TestTaxReport_WriteXLSX(t *testing.T) {
+	report, err := portfolio.ReportTaxes(LoadBondRepository(), calculator.NewCalculator(), taxedPortfolio, 2025)
+	if err != nil {
+		t.Fatalf("ReportTaxes() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteXLSX(&buf); err != nil {
+		t.Fatalf("WriteXLSX() error = %v", err)
+	}
+
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatalf("OpenReader() error = %v", err)
+	}
+	defer f.Close()
+
+	rows, err := f.GetRows("2025")
+	if err != nil {
+		t.Fatalf("GetRows() error = %v", err)
+	}
+	if len(rows) != 7 {
+		t.Fatalf("WriteXLSX() wrote %d rows, want a header, 5 events and totals", len(rows))
+	}
+	if total := rows[6]; total[0] != "total" || total[9] != "37.70" {
+		t.Errorf("WriteXLSX() totals = %v, want total tax 37.70", total)
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/maciekmm/obligacje/bond"
 	"github.com/maciekmm/obligacje/bondxls"
+	"github.com/maciekmm/obligacje/calculator"
 	"github.com/maciekmm/obligacje/internal/downloader"
 	"github.com/maciekmm/obligacje/internal/periodical"
 )
@@ -21,6 +22,17 @@ func validateBondFile(ctx context.Context, file string) error {
 	return nil
 }
 
+// verifyBonds logs differences between the calculated interest and the amounts published in the bond data.
+func verifyBonds(logger *slog.Logger, repo bond.Repository) {
+	discrepancies := calculator.NewCalculator().Verify(repo.List())
+	for _, d := range discrepancies {
+		logger.Debug("calculated interest differs from published", "discrepancy", d.String())
+	}
+	if len(discrepancies) > 0 {
+		logger.Warn("calculated interest differs from published, run cmd/verify for details", "discrepancies", len(discrepancies))
+	}
+}
+
 type BondSource struct {
 	bondsLoader *periodical.Loader[bond.Repository]
 }
@@ -43,6 +55,7 @@ func NewBondSource(logger *slog.Logger, dir string) (*BondSource, error) {
 				if err != nil {
 					lastErr = err
 				} else {
+					verifyBonds(logger, repo)
 					return repo, nil
 				}
 			}
@@ -77,3 +90,11 @@ func (s *BondSource) Lookup(name string) (bond.Bond, error) {
 	}
 	return cur.Lookup(name)
 }
+
+func (s *BondSource) List() []bond.Bond {
+	cur, err := s.bondsLoader.Current()
+	if err != nil {
+		return nil
+	}
+	return cur.List()
+}
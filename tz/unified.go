@@ -3,9 +3,9 @@ package tz
 import "time"
 
 var (
-	// We need to be careful here.
-	// Warsaw timezone has DST which can throw off calculations.
-	// TODO: figure out if it's better to use Europe/Warsaw or UTC
+	// UnifiedTimezone is the time zone of Poland, where bonds are sold.
+	// It's only used to tell the current date, calculations are carried out on civil dates,
+	// so DST changes don't affect day counting.
 	UnifiedTimezone *time.Location
 )
 